@@ -103,6 +103,14 @@ func BenchmarkMatMulUnite(b *testing.B) {
 // BenchmarkMatMul-12           115           9.977.032 ns/op        12308991 B/op        557 allocs/op
 // BenchmarkMatMul-12           112           9.891.651 ns/op        12315537 B/op        557 allocs/op
 //
+// cpu: Intel(R) Xeon(R) Processor, 1 core
+// dot product per output element (AVX512)
+// BenchmarkMatMul                9         112.275.735 ns/op        10684480 B/op         25 allocs/op
+// BenchmarkMatMul                7         147.433.202 ns/op        11447888 B/op         27 allocs/op
+// packed GEMM, 6x32 AVX512 micro kernel
+// BenchmarkMatMul               30          41.694.866 ns/op         5046888 B/op         16 allocs/op
+// BenchmarkMatMul               22          46.001.635 ns/op         5294181 B/op         16 allocs/op
+//
 // numpy matmul ref                          10.645.914 ns/op
 // 10000x10000
 // mine 54.563.731.700
@@ -144,3 +152,83 @@ func BenchmarkDot(b *testing.B) {
 		a1.Dot(b1)
 	}
 }
+
+// typical dense layer: batch 256, 784 inputs, 128 units
+// cpu: Intel(R) Xeon(R) Processor, 1 core
+// dot product per output element (AVX512)
+// BenchmarkMatMulLayer         397           3.159.242 ns/op          539352 B/op         21 allocs/op
+// BenchmarkMatMulLayer         483           2.514.372 ns/op          538265 B/op         21 allocs/op
+// packed GEMM, 6x32 AVX512 micro kernel
+// BenchmarkMatMulLayer        1236           1.047.272 ns/op          493975 B/op         15 allocs/op
+// BenchmarkMatMulLayer        1158           1.285.569 ns/op          494107 B/op         15 allocs/op
+//
+// go test -benchmem -run=^$ -bench ^BenchmarkMatMulLayer$ gograd/benchmarks -v -count=5
+func BenchmarkMatMulLayer(b *testing.B) {
+	rng := tensor.NewRNG(-1)
+	x := rng.RandomFloat32(256, 784)
+	w := rng.RandomFloat32(784, 128)
+	for i := 0; i < b.N; i++ {
+		x.MatMul(w)
+	}
+}
+
+// (32,128,128) @ (128,128)
+// dot product per output element (AVX512)
+// BenchmarkMatMulBatched        96          13.969.189 ns/op         2209193 B/op         24 allocs/op
+// BenchmarkMatMulBatched        90          11.913.129 ns/op         2212204 B/op         24 allocs/op
+// packed GEMM, 6x32 AVX512 micro kernel
+// BenchmarkMatMulBatched       396           2.929.362 ns/op         2469599 B/op         18 allocs/op
+// BenchmarkMatMulBatched       404           3.028.036 ns/op         2469382 B/op         18 allocs/op
+func BenchmarkMatMulBatched(b *testing.B) {
+	rng := tensor.NewRNG(-1)
+	x := rng.RandomFloat32(32, 128, 128)
+	w := rng.RandomFloat32(128, 128)
+	for i := 0; i < b.N; i++ {
+		x.MatMul(w)
+	}
+}
+
+// float64 has no SIMD kernels and runs the pure go implementation
+// dot product per output element
+// BenchmarkMatMulFloat64        80          17.896.174 ns/op         1075722 B/op         22 allocs/op
+// BenchmarkMatMulFloat64        62          18.579.452 ns/op         1083373 B/op         22 allocs/op
+// packed GEMM, 4x4 go micro kernel
+// BenchmarkMatMulFloat64        94          14.819.830 ns/op         1268125 B/op         16 allocs/op
+// BenchmarkMatMulFloat64        60          18.991.699 ns/op         1280835 B/op         16 allocs/op
+func BenchmarkMatMulFloat64(b *testing.B) {
+	rng := tensor.NewRNG(-1)
+	x := rng.RandomFloat64(256, 256)
+	w := rng.RandomFloat64(256, 256)
+	for i := 0; i < b.N; i++ {
+		x.MatMul(w)
+	}
+}
+
+// (2048,2048) @ (2048,2048), AVX512 GEMM below the cutoff
+// cutoff 128
+// BenchmarkMatMulStrassen         3         946.644.050 ns/op      2125400285 B/op      79624 allocs/op
+// cutoff 256
+// BenchmarkMatMulStrassen         3         637.073.654 ns/op       797005170 B/op      11367 allocs/op
+// cutoff 512
+// BenchmarkMatMulStrassen         3         498.750.153 ns/op       356755805 B/op       1616 allocs/op
+// cutoff 1024
+// BenchmarkMatMulStrassen         3         537.246.642 ns/op       150734266 B/op        225 allocs/op
+func BenchmarkMatMulStrassen(b *testing.B) {
+	rng := tensor.NewRNG(-1)
+	x := rng.RandomFloat32(2048, 2048)
+	w := rng.RandomFloat32(2048, 2048)
+	for i := 0; i < b.N; i++ {
+		x.MatMulStrassen(w)
+	}
+}
+
+// the same matrices with MatMul
+// BenchmarkMatMulStrassenBaseline         3         706.097.705 ns/op        39511528 B/op         22 allocs/op
+func BenchmarkMatMulStrassenBaseline(b *testing.B) {
+	rng := tensor.NewRNG(-1)
+	x := rng.RandomFloat32(2048, 2048)
+	w := rng.RandomFloat32(2048, 2048)
+	for i := 0; i < b.N; i++ {
+		x.MatMul(w)
+	}
+}
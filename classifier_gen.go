@@ -67,8 +67,8 @@ func classifier_gen() {
 		pred := model(x, W1, B1, W2, B2).Value.Softmax(nil).MustAssert()
 
 		fmt.Println("pred", pred.ToString(), "true", y.Value.Item())
-		argmax, _ := pred.Find(pred.Max(false).Item())
-		if argmax[1] == int(y.Value.Item()) {
+		argmax := pred.ArgMax(-1, false).Item()
+		if argmax == int(y.Value.Item()) {
 			correct += 1
 		}
 	}
@@ -81,12 +81,9 @@ func classifier_iris() {
 		y := grad.Constant(Ytest.Index(i).Reshape(1, 1))
 		pred := model(x, W1, B1, W2, B2).Value.Softmax(nil).MustAssert()
 
-		argmax, _ := pred.Find(pred.Max(false).Item())
-		if argmax[1] == int(y.Value.Item()) {
-			fmt.Println(i, argmax[1], y.Value.Item())
+		argmax := pred.ArgMax(-1, false).Item()
+		if argmax == int(y.Value.Item()) {
 			correct += 1
-		} else {
-			fmt.Println(i, argmax[1], y.Value.Item(), "mismatch")
 		}
 	}
 	fmt.Println("inference: corrects", correct, "of", test_size)
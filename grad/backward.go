@@ -58,18 +58,29 @@ func (this *Var[T]) Backward(gradient *tensor.Tensor[T]) {
 		this.Grad = gradient
 	}
 
+	// each output pushes its gradient to the parents.
+	// Vars used by several ops (fan-out) accumulate all contributions
+	// before their own backward_fn is invoked thanks to the topological order
 	for _, v := range topo_sorted {
 		if v.backward_fn != nil {
-			new_grad := v.backward_fn()
-			if !new_grad.Shape().AreBroadcastable(v.Grad.Shape()) {
-				panic(fmt.Sprintf("Error at '%v' backprop. Old grad Shape is %v but new grad Shape is %v",
-					v.Alias, v.Grad.Shape(), new_grad.Shape()))
-			}
-			v.Grad.Add(new_grad, v.Grad).MustAssert()
+			v.backward_fn()
 		}
 	}
 }
 
+// adds a gradient contribution to the Var's gradient
+func (v *Var[T]) accumulate(grad *tensor.Tensor[T]) {
+	if !v.Requires_grad {
+		return
+	}
+	grad = unbroadcast(grad, v.Value.Shape())
+	if !grad.Shape().AreBroadcastable(v.Grad.Shape()) {
+		panic(fmt.Sprintf("Error at '%v' backprop. Old grad Shape is %v but new grad Shape is %v",
+			v.Alias, v.Grad.Shape(), grad.Shape()))
+	}
+	v.Grad.Add(grad, v.Grad).MustAssert()
+}
+
 const EPSILON = 0.00000000001
 
 // numerical derivative calc can be used for verifying auto-diff expressions
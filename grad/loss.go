@@ -9,16 +9,14 @@ import (
 //
 // 'y_true' is a cosnt by definition
 func (y_pred *Var[T]) MSE(y_true *Var[T]) *Var[T] {
-	squared := tensor.Scalar[T](2)
+	squared := tensor.ScalarOf[T](2)
 	mean := y_true.Value.Sub(y_pred.Value).Pow(squared).Mean(false)
 	out := Variable(mean, y_pred)
 	out.Alias = "MSE"
-	if y_pred.Requires_grad {
-		y_pred.backward_fn = func() *tensor.Tensor[T] {
-			n := tensor.Scalar[T](T(len(y_true.Value.Data())))
-			_const := tensor.Scalar[T](2).Div(n).Neg()
-			return out.Grad.Mul(_const.Mul(y_true.Value.Sub(y_pred.Value)))
-		}
+	out.backward_fn = func() {
+		n := tensor.ScalarOf[T](float64(len(y_true.Value.Data())))
+		_const := tensor.ScalarOf[T](2).Div(n).Neg()
+		y_pred.accumulate(out.Grad.Mul(_const.Mul(y_true.Value.Sub(y_pred.Value))))
 	}
 	return out
 }
@@ -32,7 +30,6 @@ func (logits *Var[T]) SoftmaxCrossEntropy(y_true *Var[T]) *Var[T] {
 	y_pred := logits.Value.Softmax(nil)
 
 	var epsilon float64 = 1e-15
-	// cross_entropy := y_pred.IndexMask(y_true.Value, true).Clip(epsilon, 1-epsilon).LnNeg()
 
 	clipped_lnn := func(v T) T {
 		// clips value and applies -log()
@@ -45,7 +42,12 @@ func (logits *Var[T]) SoftmaxCrossEntropy(y_true *Var[T]) *Var[T] {
 		}
 		return T(-math.Log(fv))
 	}
-	cross_entropy := y_pred.IndexMask(y_true.Value, true)
+	// picks the predicted probability of the true class for each row
+	labels := tensor.AsType[T, int](y_true.Value)
+	if len(labels.Shape()) == 1 {
+		labels = labels.Reshape(labels.Shape()[0], 1)
+	}
+	cross_entropy := y_pred.TakeAlongAxis(labels, 1)
 	cross_entropy.ApplyFunc(clipped_lnn, cross_entropy)
 
 	out := Variable(cross_entropy, logits).SetAlias("SoftmaxCrossEntropy")
@@ -54,8 +56,8 @@ func (logits *Var[T]) SoftmaxCrossEntropy(y_true *Var[T]) *Var[T] {
 		n_classes := uint(logits.Value.Shape()[1])
 		y_onehot := ToOneHot(y_true.Value, n_classes)
 		// y_onehot := tensor.AsType[int, T](ToOneHot(y_true.Value, n_classes))
-		logits.backward_fn = func() *tensor.Tensor[T] {
-			return out.Grad.Mul(y_pred.Sub(y_onehot))
+		out.backward_fn = func() {
+			logits.accumulate(out.Grad.Mul(y_pred.Sub(y_onehot)))
 		}
 	}
 	return out
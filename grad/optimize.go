@@ -12,7 +12,7 @@ type Optimizer[T types.TensorType] struct {
 
 func SGD[T types.TensorType](lr float64) *Optimizer[T] {
 	return &Optimizer[T]{
-		lr: tensor.Scalar[T](T(lr)),
+		lr: tensor.ScalarOf[T](lr),
 	}
 }
 
@@ -14,13 +14,14 @@ func ToOneHot[T types.TensorType](y *tensor.Tensor[T], classes uint) *tensor.Ten
 	}
 	size := y.Shape()[0]
 	oneHotData := make([]T, int(size)*int(classes))
+	y_data := y.Data()
 
 	var wg sync.WaitGroup
 	for i := 0; i < int(size); i++ {
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			classidx := int(y.Data()[i])
+			classidx := int(y_data[i])
 			if classidx >= int(classes) {
 				panic(fmt.Sprintf(
 					"too few classes. tensor has element '%v' which must be less than 'classes' %v", classidx, classes,
@@ -38,3 +39,36 @@ func ToOneHotAuto[T types.TensorType](y *tensor.Tensor[T]) *tensor.Tensor[T] {
 	_max := uint(y.Max(false).Item() + 1)
 	return ToOneHot(y, _max)
 }
+
+// resolves negative axis
+func normalize_axis(axis, ndims int) int {
+	if axis < 0 {
+		return axis + ndims
+	}
+	return axis
+}
+
+// copy of the shape with the dim of the axis replaced
+func with_dim(shape types.Shape, axis, dim int) types.Shape {
+	out := append(types.Shape(nil), shape...)
+	out[axis] = types.Dim(dim)
+	return out
+}
+
+// copy of the shape without the dim of the axis
+func without_dim(shape types.Shape, axis int) types.Shape {
+	axis = normalize_axis(axis, len(shape))
+	out := append(types.Shape(nil), shape[:axis]...)
+	return append(out, shape[axis+1:]...)
+}
+
+// transposed view with the last two dims swapped
+func swap_last_dims[T types.TensorType](t *tensor.Tensor[T]) *tensor.Tensor[T] {
+	n := len(t.Shape())
+	axes := make([]uint, n)
+	for i := range axes {
+		axes[i] = uint(i)
+	}
+	axes[n-2], axes[n-1] = axes[n-1], axes[n-2]
+	return t.View().T(axes...)
+}
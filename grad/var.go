@@ -23,7 +23,7 @@ var intKinds map[reflect.Kind]bool = map[reflect.Kind]bool{
 type Var[T types.TensorType] struct {
 	Value         *tensor.Tensor[T]
 	Grad          *tensor.Tensor[T]
-	backward_fn   func() *tensor.Tensor[T]
+	backward_fn   func() // owned by the op output. Pushes out.Grad to all Children
 	Alias         string
 	Children      []*Var[T]
 	Requires_grad bool
@@ -56,7 +56,7 @@ func Constant[T types.TensorType](
 ) *Var[T] {
 	return &Var[T]{
 		Value:         tensor_val.MustAssert(),
-		Grad:          tensor.Scalar[T](0),
+		Grad:          tensor.ScalarOf[T](0),
 		Children:      nil,
 		Requires_grad: false,
 	}
@@ -102,39 +102,48 @@ func unbroadcast[T types.TensorType](
 	grad *tensor.Tensor[T],
 	to_shape types.Shape,
 ) *tensor.Tensor[T] {
-	if !grad.Shape().Equals(to_shape) {
-		return grad.SumAlongAxis(0, true).Reshape(to_shape...)
+	shape := grad.Shape()
+	if shape.Equals(to_shape) || len(shape) < len(to_shape) {
+		// smaller gradients are broadcasted during accumulation
+		return grad
+	}
+	// sum over leading dims and over dims that were broadcasted from 1
+	offset := len(shape) - len(to_shape)
+	for i := 0; i < len(shape); i++ {
+		if grad.Shape()[i] == 1 {
+			continue
+		}
+		if i < offset || to_shape[i-offset] == 1 {
+			grad = grad.SumAlongAxis(uint(i), true)
+		}
+	}
+	var to_size types.Dim = 1
+	for _, dim := range to_shape {
+		to_size *= dim
+	}
+	if types.Dim(grad.Size()) == to_size {
+		return tensor.CreateTensor(grad.Data(), to_shape)
 	}
 	return grad
 }
 
 func (this *Var[T]) Add(other *Var[T]) *Var[T] {
 	out := Variable(this.Value.Add(other.Value), this, other).SetAlias("Add")
-	if this.Requires_grad {
-		this.backward_fn = func() *tensor.Tensor[T] {
-			return unbroadcast(out.Grad, this.Value.Shape())
-		}
-	}
-	if other.Requires_grad {
-		other.backward_fn = func() *tensor.Tensor[T] {
-			return unbroadcast(out.Grad, other.Value.Shape())
-		}
+	out.backward_fn = func() {
+		this.accumulate(out.Grad)
+		other.accumulate(out.Grad)
 	}
 	return out
 }
 
 func (this *Var[T]) Sub(other *Var[T]) *Var[T] {
 	out := Variable(this.Value.Sub(other.Value), this, other).SetAlias("Sub")
-	if this.Requires_grad {
-		this.backward_fn = func() *tensor.Tensor[T] {
-			// out.g
-			return unbroadcast(out.Grad, this.Value.Shape())
-		}
-	}
-	if other.Requires_grad {
-		other.backward_fn = func() *tensor.Tensor[T] {
+	out.backward_fn = func() {
+		// out.g
+		this.accumulate(out.Grad)
+		if other.Requires_grad {
 			// -out.g
-			return unbroadcast(out.Grad.Neg(), other.Value.Shape())
+			other.accumulate(out.Grad.Neg())
 		}
 	}
 	return out
@@ -142,16 +151,12 @@ func (this *Var[T]) Sub(other *Var[T]) *Var[T] {
 
 func (this *Var[T]) Mul(other *Var[T]) *Var[T] {
 	out := Variable(this.Value.Mul(other.Value), this, other).SetAlias("Mul")
-	if this.Requires_grad {
-		this.backward_fn = func() *tensor.Tensor[T] {
-			grad := other.Value.Mul(out.Grad) // other * out.g
-			return unbroadcast(grad, this.Value.Shape())
+	out.backward_fn = func() {
+		if this.Requires_grad {
+			this.accumulate(other.Value.Mul(out.Grad)) // other * out.g
 		}
-	}
-	if other.Requires_grad {
-		other.backward_fn = func() *tensor.Tensor[T] {
-			grad := this.Value.Mul(out.Grad) // this * out.g
-			return unbroadcast(grad, other.Value.Shape())
+		if other.Requires_grad {
+			other.accumulate(this.Value.Mul(out.Grad)) // this * out.g
 		}
 	}
 	return out
@@ -159,19 +164,15 @@ func (this *Var[T]) Mul(other *Var[T]) *Var[T] {
 
 func (this *Var[T]) Pow(other *Var[T]) *Var[T] {
 	out := Variable(this.Value.Pow(other.Value), this, other).SetAlias("Pow")
-	if this.Requires_grad {
-		this.backward_fn = func() *tensor.Tensor[T] {
+	out.backward_fn = func() {
+		if this.Requires_grad {
 			// out.g * other * this**(other-1)
 			// or out.g * other * out / this ),
-			grad := out.Grad.Mul(other.Value.Mul(out.Value.Div(this.Value)))
-			return unbroadcast(grad, this.Value.Shape())
+			this.accumulate(out.Grad.Mul(other.Value.Mul(out.Value.Div(this.Value))))
 		}
-	}
-	if other.Requires_grad {
-		other.backward_fn = func() *tensor.Tensor[T] {
+		if other.Requires_grad {
 			// out.g * out * this.ln()
-			grad := out.Grad.Mul(out.Value.Mul(this.Value.Ln()))
-			return unbroadcast(grad, other.Value.Shape())
+			other.accumulate(out.Grad.Mul(out.Value.Mul(this.Value.Ln())))
 		}
 	}
 	return out
@@ -182,35 +183,61 @@ func (this *Var[T]) Pow(other *Var[T]) *Var[T] {
 // => d(other): (-this) / (other**2)
 func (this *Var[T]) Div(other *Var[T]) *Var[T] {
 	out := Variable(this.Value.Div(other.Value), this, other).SetAlias("Div")
-	if this.Requires_grad {
-		// one := tensor.Scalar[T](1)
-		this.backward_fn = func() *tensor.Tensor[T] {
-			grad := out.Grad.Div(other.Value) // this.g += out.g / other.val
-			return unbroadcast(grad, this.Value.Shape())
-
+	out.backward_fn = func() {
+		if this.Requires_grad {
+			this.accumulate(out.Grad.Div(other.Value)) // this.g += out.g / other.val
 		}
-	}
-	if other.Requires_grad {
-		other.backward_fn = func() *tensor.Tensor[T] {
-			grad := this.Value.Neg().Div(other.Value.Mul(other.Value)) // other.g += -this.val * other.
-			return unbroadcast(grad, other.Value.Shape())
+		if other.Requires_grad {
+			// other.g += out.g * -this.val / other.val**2
+			grad := this.Value.Neg().Div(other.Value.Mul(other.Value))
+			other.accumulate(out.Grad.Mul(grad))
 		}
 	}
 	return out
 }
 
+// Batched matrix product, see tensor.MatMul.
+// => d(this): out.g @ other^T
+// => d(other): this^T @ out.g
+// where ^T swaps the last two dims. Gradients of the broadcasted batches are summed up.
 func (this *Var[T]) MatMul(other *Var[T]) *Var[T] {
 	out := Variable(this.Value.MatMul(other.Value), this, other).SetAlias("MatMul")
-	if this.Requires_grad {
-		this.backward_fn = func() *tensor.Tensor[T] {
-			// out.g @ other.T
-			return out.Grad.MatMul(other.Value.TrC())
+	out.backward_fn = func() {
+		// 1D operands are handled as (1,K) and (K,1) matrices
+		a, b := this.Value, other.Value
+		a_1d, b_1d := len(a.Shape()) == 1, len(b.Shape()) == 1
+		if a_1d {
+			a = a.View().Unsqueeze(0)
 		}
-	}
-	if other.Requires_grad {
-		other.backward_fn = func() *tensor.Tensor[T] {
-			// this.T @ out.g
-			return this.Value.TrC().MatMul(out.Grad)
+		if b_1d {
+			b = b.View().Unsqueeze(1)
+		}
+		// restore the dims removed from the output
+		out_shape := out.Value.Shape()
+		n_batch := len(out_shape)
+		if !a_1d {
+			n_batch--
+		}
+		if !b_1d {
+			n_batch--
+		}
+		full_shape := append(types.Shape{}, out_shape[:n_batch]...)
+		full_shape = append(full_shape, a.Shape()[len(a.Shape())-2], b.Shape()[len(b.Shape())-1])
+		out_grad := out.Grad.View().Reshape(full_shape...)
+
+		if this.Requires_grad {
+			grad := out_grad.MatMul(swap_last_dims(b))
+			if a_1d {
+				grad = grad.Reshape(without_dim(grad.Shape(), -2)...)
+			}
+			this.accumulate(grad)
+		}
+		if other.Requires_grad {
+			grad := swap_last_dims(a).MatMul(out_grad)
+			if b_1d {
+				grad = grad.Reshape(without_dim(grad.Shape(), -1)...)
+			}
+			other.accumulate(grad)
 		}
 	}
 	return out
@@ -219,73 +246,445 @@ func (this *Var[T]) MatMul(other *Var[T]) *Var[T] {
 // activations
 func (this *Var[T]) Sigmoid() *Var[T] {
 	out := Variable(this.Value.Sigmoid(), this).SetAlias("Sigmoid")
-	if this.Requires_grad {
-		this.backward_fn = func() *tensor.Tensor[T] {
-			one := tensor.Ones[T](out.Value.Shape()...)
-			return unbroadcast(out.Value.Mul(one.Sub(out.Value)), this.Value.Shape())
-		}
+	out.backward_fn = func() {
+		// out.g * out * (1 - out)
+		one := tensor.Ones[T](out.Value.Shape()...)
+		this.accumulate(out.Grad.Mul(out.Value.Mul(one.Sub(out.Value))))
 	}
 	return out
 }
 
 func (this *Var[T]) Relu() *Var[T] {
 	out := Variable(this.Value.Relu(), this).SetAlias("Relu")
-	if this.Requires_grad {
-		this.backward_fn = func() *tensor.Tensor[T] {
-			expr := func(a T) T {
-				if a > 0 {
-					return 1
-				}
-				return 0
+	out.backward_fn = func() {
+		expr := func(a T) T {
+			if a > 0 {
+				return 1
 			}
-			return out.Grad.Mul(out.Value.ApplyFunc(expr))
+			return 0
 		}
+		this.accumulate(out.Grad.Mul(out.Value.ApplyFunc(expr)))
 	}
 	return out
 }
 
-// FIXME derivative of the softmax is Jacobian of shape (class, class).
-//
-// The batched softmax deriv should be (batch, class).
-//
-// TODO treat each i-th row in batch independently and combine each i-th Jacobian using reduce (sum)
+// Softmax along the last axis. The Jacobian of every row is diag(s) - s*s^T,
+// so the gradient is s * (g - sum(g * s)) with the sum along the last axis.
 func (this *Var[T]) Softmax() *Var[T] {
-	panic("Var.Softmax is not implemented")
-	nclasses := types.Dim(this.Value.Shape()[1])
-	e := this.Value.Exp()
-	_softmax := Variable(e.Div(e.Sum(false)), this)
-
-	fmt.Println("this", this.Value.Shape(), "_softmax", _softmax.Value.Shape())
-	_softmax.Alias = "Softmax"
-	if this.Requires_grad {
-		this.backward_fn = func() *tensor.Tensor[T] {
-			_dot_self := _softmax.Value.MatMul(_softmax.Value.TrC())
-			fmt.Println(_softmax.ToString())
-			diag := tensor.Eye[T](nclasses, nclasses) //_softmax.Value.DiagFlat()
-			fmt.Println(diag.ToString())
-			fmt.Println("diag", diag.Shape(), "_dot_self", _dot_self.Shape())
-			J := diag.Sub(_dot_self).MustAssert()
-			// x := tensor.Eye[T](n, n).Sub(_softmax.Value.Unsqueeze(-1)).MustAssert()
-			// fmt.Println("x", x.Shape(), "sf", _softmax.Value.Shape())
-			// ds := _softmax.Value.Mul(x).MustAssert()
-			// fmt.Println("ds", ds.Shape())
-			// ds = _softmax.Grad.Unsqueeze(-1).Mul(ds).MustAssert()
-			// fmt.Println("ds", ds.Shape())
-			return J
-		}
-	}
-	return _softmax
+	out := Variable(this.Value.Softmax(nil), this).SetAlias("Softmax")
+	out.backward_fn = func() {
+		last := uint(len(out.Value.Shape()) - 1)
+		dot := out.Grad.Mul(out.Value).SumAlongAxis(last, true)
+		this.accumulate(out.Value.Mul(out.Grad.Sub(dot)))
+	}
+	return out
 }
 
 // reduce
 func (this *Var[T]) Mean() *Var[T] {
 	out := Variable(this.Value.Mean(false), this)
 	out.Alias = "Mean"
-	if this.Requires_grad {
-		this.backward_fn = func() *tensor.Tensor[T] {
-			filler := tensor.Scalar(T(1. / float32(this.Value.Size())))
-			return out.Grad.Mul(filler)
+	out.backward_fn = func() {
+		filler := tensor.ScalarOf[T](1. / float64(this.Value.Size()))
+		this.accumulate(out.Grad.Mul(filler))
+	}
+	return out
+}
+
+// cumulative
+
+// cumulative sum along the axis. See tensor.CumSum
+// => d(this): reversed cumulative sum of out.g
+func (this *Var[T]) CumSum(axis int) *Var[T] {
+	out := Variable(this.Value.CumSum(axis), this).SetAlias("CumSum")
+	out.backward_fn = func() {
+		grad, err := tensor.CumSumGrad(out.Grad, axis)
+		if err != nil {
+			panic(err)
 		}
+		this.accumulate(grad)
 	}
 	return out
 }
+
+// cumulative product along the axis. See tensor.CumProd
+func (this *Var[T]) CumProd(axis int) *Var[T] {
+	out := Variable(this.Value.CumProd(axis), this).SetAlias("CumProd")
+	out.backward_fn = func() {
+		grad, err := tensor.CumProdGrad(this.Value, out.Grad, axis)
+		if err != nil {
+			panic(err)
+		}
+		this.accumulate(grad)
+	}
+	return out
+}
+
+// running max along the axis. See tensor.CumMax
+// => d(this): out.g goes to the positions of the running max
+func (this *Var[T]) CumMax(axis int) *Var[T] {
+	out := Variable(this.Value.CumMax(axis), this).SetAlias("CumMax")
+	out.backward_fn = func() {
+		index := this.Value.CumArgMax(axis)
+		this.accumulate(tensor.Zeros[T](this.Value.Shape()...).ScatterAdd(axis, index, out.Grad).MustAssert())
+	}
+	return out
+}
+
+// running min along the axis. See tensor.CumMin
+func (this *Var[T]) CumMin(axis int) *Var[T] {
+	out := Variable(this.Value.CumMin(axis), this).SetAlias("CumMin")
+	out.backward_fn = func() {
+		index := this.Value.CumArgMin(axis)
+		this.accumulate(tensor.Zeros[T](this.Value.Shape()...).ScatterAdd(axis, index, out.Grad).MustAssert())
+	}
+	return out
+}
+
+// log of the cumulative sum of exponents along the axis. See tensor.LogCumSumExp
+func (this *Var[T]) LogCumSumExp(axis int) *Var[T] {
+	out := Variable(this.Value.LogCumSumExp(axis), this).SetAlias("LogCumSumExp")
+	out.backward_fn = func() {
+		grad, err := tensor.LogCumSumExpGrad(this.Value, out.Value, out.Grad, axis)
+		if err != nil {
+			panic(err)
+		}
+		this.accumulate(grad)
+	}
+	return out
+}
+
+// n-th discrete difference along the axis. See tensor.Diff
+func (this *Var[T]) Diff(n, axis int) *Var[T] {
+	out := Variable(this.Value.Diff(n, axis), this).SetAlias("Diff")
+	out.backward_fn = func() {
+		grad, err := tensor.DiffGrad(out.Grad, n, axis)
+		if err != nil {
+			panic(err)
+		}
+		this.accumulate(grad)
+	}
+	return out
+}
+
+// shaping
+
+// joins Vars along an existing axis. See tensor.Concat
+func Concat[T types.TensorType](axis int, vars ...*Var[T]) *Var[T] {
+	values := make([]*tensor.Tensor[T], len(vars))
+	for i, v := range vars {
+		values[i] = v.Value
+	}
+	united, err := tensor.Concat(axis, values...)
+	if err != nil {
+		panic(err)
+	}
+	axis = normalize_axis(axis, len(united.Shape()))
+	sizes := make([]int, len(vars))
+	for i, v := range vars {
+		sizes[i] = int(v.Value.Shape()[axis])
+	}
+	out := Variable(united, vars...).SetAlias("Concat")
+	out.backward_fn = func() {
+		// each Var gets its own part of out.g
+		parts, err := out.Grad.Split(sizes, axis)
+		if err != nil {
+			panic(err)
+		}
+		for i, v := range vars {
+			v.accumulate(parts[i])
+		}
+	}
+	return out
+}
+
+// joins Vars along a new axis. See tensor.Stack
+func Stack[T types.TensorType](axis int, vars ...*Var[T]) *Var[T] {
+	values := make([]*tensor.Tensor[T], len(vars))
+	for i, v := range vars {
+		values[i] = v.Value
+	}
+	stacked, err := tensor.Stack(axis, values...)
+	if err != nil {
+		panic(err)
+	}
+	out := Variable(stacked, vars...).SetAlias("Stack")
+	out.backward_fn = func() {
+		parts, err := out.Grad.Chunk(len(vars), axis)
+		if err != nil {
+			panic(err)
+		}
+		for i, v := range vars {
+			v.accumulate(parts[i].Reshape(v.Value.Shape()...))
+		}
+	}
+	return out
+}
+
+// Einstein summation of Vars, e.g. Einsum("bij,bjk->bik", a, b). See tensor.Einsum
+func Einsum[T types.TensorType](subscripts string, vars ...*Var[T]) *Var[T] {
+	values := make([]*tensor.Tensor[T], len(vars))
+	for i, v := range vars {
+		values[i] = v.Value
+	}
+	result, err := tensor.Einsum(subscripts, values...)
+	if err != nil {
+		panic(err)
+	}
+	out := Variable(result, vars...).SetAlias("Einsum")
+	out.backward_fn = func() {
+		for i, v := range vars {
+			if !v.Requires_grad {
+				continue
+			}
+			grad, err := tensor.EinsumGrad(subscripts, out.Grad, values, i)
+			if err != nil {
+				panic(err)
+			}
+			v.accumulate(grad)
+		}
+	}
+	return out
+}
+
+// splits the Var along the axis into parts with given sizes. See tensor.Split
+func (this *Var[T]) Split(sizes []int, axis int) []*Var[T] {
+	parts, err := this.Value.Split(sizes, axis)
+	if err != nil {
+		panic(err)
+	}
+	axis = normalize_axis(axis, len(this.Value.Shape()))
+	outs := make([]*Var[T], len(parts))
+	start := 0
+	for i, part := range parts {
+		out := Variable(part.Clone(), this).SetAlias("Split")
+		before, after := start, int(this.Value.Shape()[axis])-start-sizes[i]
+		out.backward_fn = func() {
+			// out.g padded with zeros to the shape of this
+			grads := make([]*tensor.Tensor[T], 0, 3)
+			if before > 0 {
+				grads = append(grads, tensor.Zeros[T](with_dim(this.Value.Shape(), axis, before)...))
+			}
+			grads = append(grads, out.Grad)
+			if after > 0 {
+				grads = append(grads, tensor.Zeros[T](with_dim(this.Value.Shape(), axis, after)...))
+			}
+			grad, err := tensor.Concat(axis, grads...)
+			if err != nil {
+				panic(err)
+			}
+			this.accumulate(grad)
+		}
+		outs[i] = out
+		start += sizes[i]
+	}
+	return outs
+}
+
+// splits the Var along the axis into n parts. See tensor.Chunk
+func (this *Var[T]) Chunk(n int, axis int) []*Var[T] {
+	parts, err := this.Value.Chunk(n, axis)
+	if err != nil {
+		panic(err)
+	}
+	axis = normalize_axis(axis, len(this.Value.Shape()))
+	sizes := make([]int, len(parts))
+	for i, part := range parts {
+		sizes[i] = int(part.Shape()[axis])
+	}
+	return this.Split(sizes, axis)
+}
+
+// reverses the order of elements along the axes. See tensor.Flip
+func (this *Var[T]) Flip(axes ...int) *Var[T] {
+	out := Variable(this.Value.Flip(axes...), this).SetAlias("Flip")
+	out.backward_fn = func() {
+		this.accumulate(out.Grad.Flip(axes...))
+	}
+	return out
+}
+
+// interchanges two axes. See tensor.SwapAxes
+func (this *Var[T]) SwapAxes(axis1, axis2 int) *Var[T] {
+	out := Variable(this.Value.SwapAxes(axis1, axis2), this).SetAlias("SwapAxes")
+	out.backward_fn = func() {
+		this.accumulate(out.Grad.SwapAxes(axis1, axis2))
+	}
+	return out
+}
+
+// moves the axis to the new position. See tensor.MoveAxis
+func (this *Var[T]) MoveAxis(source, destination int) *Var[T] {
+	out := Variable(this.Value.MoveAxis(source, destination), this).SetAlias("MoveAxis")
+	out.backward_fn = func() {
+		this.accumulate(out.Grad.MoveAxis(destination, source))
+	}
+	return out
+}
+
+// rotates the Var by 90 degrees k times. See tensor.Rot90
+// => d(this): out.g rotated backwards
+func (this *Var[T]) Rot90(k int, axes ...int) *Var[T] {
+	out := Variable(this.Value.Rot90(k, axes...), this).SetAlias("Rot90")
+	out.backward_fn = func() {
+		this.accumulate(out.Grad.Rot90(-k, axes...))
+	}
+	return out
+}
+
+// shifts elements along the axis. See tensor.Roll
+func (this *Var[T]) Roll(shift, axis int) *Var[T] {
+	out := Variable(this.Value.Roll(shift, axis), this).SetAlias("Roll")
+	out.backward_fn = func() {
+		this.accumulate(out.Grad.Roll(-shift, axis))
+	}
+	return out
+}
+
+// repeats the Var along the axes. See tensor.Tile
+// => d(this): sum of out.g of all repetitions
+func (this *Var[T]) Tile(reps ...int) *Var[T] {
+	out := Variable(this.Value.Tile(reps...), this).SetAlias("Tile")
+	out.backward_fn = func() {
+		grad, err := tensor.TileGrad(out.Grad, this.Value.Shape(), reps...)
+		if err != nil {
+			panic(err)
+		}
+		this.accumulate(grad)
+	}
+	return out
+}
+
+// repeats every element along the axis. See tensor.RepeatInterleave
+func (this *Var[T]) RepeatInterleave(repeats []int, axis int) *Var[T] {
+	out := Variable(this.Value.RepeatInterleave(repeats, axis), this).SetAlias("RepeatInterleave")
+	out.backward_fn = func() {
+		dim := int(this.Value.Shape()[normalize_axis(axis, len(this.Value.Shape()))])
+		grad, err := tensor.RepeatInterleaveGrad(out.Grad, repeats, axis, dim)
+		if err != nil {
+			panic(err)
+		}
+		this.accumulate(grad)
+	}
+	return out
+}
+
+// lower triangle of the matrices. See tensor.Tril
+func (this *Var[T]) Tril(k int) *Var[T] {
+	out := Variable(this.Value.Tril(k), this).SetAlias("Tril")
+	out.backward_fn = func() {
+		this.accumulate(out.Grad.Tril(k))
+	}
+	return out
+}
+
+// upper triangle of the matrices. See tensor.Triu
+func (this *Var[T]) Triu(k int) *Var[T] {
+	out := Variable(this.Value.Triu(k), this).SetAlias("Triu")
+	out.backward_fn = func() {
+		this.accumulate(out.Grad.Triu(k))
+	}
+	return out
+}
+
+// pads the Var along every axis. See tensor.Pad
+// => d(this): out.g of the source elements, padded constants get no gradient
+func (this *Var[T]) Pad(widths [][2]int, mode tensor.PadMode, value ...T) *Var[T] {
+	out := Variable(this.Value.Pad(widths, mode, value...), this).SetAlias("Pad")
+	out.backward_fn = func() {
+		grad, err := tensor.PadGrad(out.Grad, widths, mode)
+		if err != nil {
+			panic(err)
+		}
+		this.accumulate(grad)
+	}
+	return out
+}
+
+// indexing
+
+// gathers values along the axis. See tensor.Gather
+func (this *Var[T]) Gather(axis int, index *tensor.Tensor[int]) *Var[T] {
+	out := Variable(this.Value.Gather(axis, index), this).SetAlias("Gather")
+	out.backward_fn = func() {
+		// out.g is added to the gathered positions
+		grad := tensor.Zeros[T](this.Value.Shape()...).ScatterAdd(axis, index, out.Grad)
+		this.accumulate(grad.MustAssert())
+	}
+	return out
+}
+
+// takes values along the axis with broadcasting. See tensor.TakeAlongAxis
+func (this *Var[T]) TakeAlongAxis(index *tensor.Tensor[int], axis int) *Var[T] {
+	out := Variable(this.Value.TakeAlongAxis(index, axis), this).SetAlias("TakeAlongAxis")
+	out.backward_fn = func() {
+		// gradient of the broadcasted Var is reduced by accumulate
+		out_shape := out.Value.Shape()
+		axis := normalize_axis(axis, len(out_shape))
+		grad_shape := with_dim(out_shape, axis, int(this.Value.Shape()[axis]))
+		grad := tensor.Zeros[T](grad_shape...).ScatterAdd(axis, index.Broadcast(out_shape...), out.Grad)
+		this.accumulate(grad.MustAssert())
+	}
+	return out
+}
+
+// selects slices along the axis. See tensor.IndexSelect
+func (this *Var[T]) IndexSelect(axis int, index *tensor.Tensor[int]) *Var[T] {
+	out := Variable(this.Value.IndexSelect(axis, index), this).SetAlias("IndexSelect")
+	out.backward_fn = func() {
+		grad := tensor.Zeros[T](this.Value.Shape()...).IndexAdd(axis, index, out.Grad)
+		this.accumulate(grad.MustAssert())
+	}
+	return out
+}
+
+// writes src values to the positions of the index along the axis. See tensor.Scatter
+// => d(this): out.g with zeros at the overwritten positions
+// => d(src): out.g gathered from the written positions
+func (this *Var[T]) Scatter(axis int, index *tensor.Tensor[int], src *Var[T]) *Var[T] {
+	out := Variable(this.Value.Scatter(axis, index, src.Value), this, src).SetAlias("Scatter")
+	out.backward_fn = func() {
+		if this.Requires_grad {
+			this.accumulate(out.Grad.Scatter(axis, index, tensor.ScalarOf[T](0)).MustAssert())
+		}
+		if src.Requires_grad {
+			src.accumulate(out.Grad.Gather(axis, index).MustAssert())
+		}
+	}
+	return out
+}
+
+// adds src values to the positions of the index along the axis. See tensor.ScatterAdd
+func (this *Var[T]) ScatterAdd(axis int, index *tensor.Tensor[int], src *Var[T]) *Var[T] {
+	out := Variable(this.Value.ScatterAdd(axis, index, src.Value), this, src).SetAlias("ScatterAdd")
+	out.backward_fn = func() {
+		this.accumulate(out.Grad)
+		if src.Requires_grad {
+			src.accumulate(out.Grad.Gather(axis, index).MustAssert())
+		}
+	}
+	return out
+}
+
+// sorting
+
+// sorts the Var along the axis. See tensor.Sort
+// => d(this): out.g scattered back to the original positions
+func (this *Var[T]) Sort(axis int, descending bool) *Var[T] {
+	index := this.Value.ArgSort(axis, descending)
+	out := Variable(this.Value.Gather(axis, index), this).SetAlias("Sort")
+	out.backward_fn = func() {
+		this.accumulate(tensor.Zeros[T](this.Value.Shape()...).ScatterAdd(axis, index, out.Grad).MustAssert())
+	}
+	return out
+}
+
+// k largest values along the axis and their positions. See tensor.TopK
+func (this *Var[T]) TopK(k, axis int) (*Var[T], *tensor.Tensor[int]) {
+	values, index := this.Value.TopK(k, axis)
+	out := Variable(values, this).SetAlias("TopK")
+	out.backward_fn = func() {
+		this.accumulate(tensor.Zeros[T](this.Value.Shape()...).ScatterAdd(axis, index, out.Grad).MustAssert())
+	}
+	return out, index
+}
@@ -0,0 +1,360 @@
+package tensor
+
+import (
+	"fmt"
+	types "gograd/tensor/types"
+)
+
+// methods shared by all *Tensor[T]
+type any_value interface {
+	Shape() types.Shape
+	Size() uint32
+	ToString() string
+}
+
+// AnyTensor holds a tensor of any supported dtype.
+// Binary ops between AnyTensors convert both operands to the dtype given by PromoteTypes
+// and then apply the typed op, so int labels can be compared with float predictions directly.
+//
+// Example:
+// labels := Wrap(CreateTensor([]int32{0, 1, 1}, types.Shape{3}))
+// preds := Wrap(CreateTensor([]float32{0.1, 0.9, 0.4}, types.Shape{3}))
+// diff := labels.Sub(preds) // float32 tensor [-0.1, 0.1, 0.6]
+// out := Cast[float32](diff)
+type AnyTensor struct {
+	Err   error
+	value any_value
+	dtype DType
+}
+
+// Wraps the typed tensor. The tensor is not copied.
+func Wrap[T types.TensorType](tensor *Tensor[T]) *AnyTensor {
+	dtype := DTypeOf[T]()
+	if dtype == InvalidDType {
+		return &AnyTensor{Err: fmt.Errorf("unsupported dtype %v", tensor.DType())}
+	}
+	return &AnyTensor{Err: tensor.Err, value: tensor, dtype: dtype}
+}
+
+func (tensor *AnyTensor) DType() DType {
+	return tensor.dtype
+}
+
+func (tensor *AnyTensor) Shape() types.Shape {
+	if tensor.value == nil {
+		return nil
+	}
+	return tensor.value.Shape()
+}
+
+func (tensor *AnyTensor) Size() uint32 {
+	if tensor.value == nil {
+		return 0
+	}
+	return tensor.value.Size()
+}
+
+func (tensor *AnyTensor) ToString() string {
+	if tensor.value == nil {
+		return fmt.Sprintf("AnyTensor(err=%v)", tensor.Err)
+	}
+	return tensor.value.ToString()
+}
+
+// Converts the tensor to T whatever precision is lost (float to int truncates, wide ints wrap).
+// Returns the wrapped tensor itself if it already has type T.
+func Cast[T types.TensorType](tensor *AnyTensor) *Tensor[T] {
+	if tensor.Err != nil {
+		ret := Scalar[T](0)
+		ret.Err = tensor.Err
+		return ret
+	}
+	switch t := tensor.value.(type) {
+	case *Tensor[T]:
+		return t
+	case *Tensor[uint8]:
+		return AsType[uint8, T](t)
+	case *Tensor[uint16]:
+		return AsType[uint16, T](t)
+	case *Tensor[uint32]:
+		return AsType[uint32, T](t)
+	case *Tensor[uint64]:
+		return AsType[uint64, T](t)
+	case *Tensor[uint]:
+		return AsType[uint, T](t)
+	case *Tensor[int8]:
+		return AsType[int8, T](t)
+	case *Tensor[int16]:
+		return AsType[int16, T](t)
+	case *Tensor[int32]:
+		return AsType[int32, T](t)
+	case *Tensor[int64]:
+		return AsType[int64, T](t)
+	case *Tensor[int]:
+		return AsType[int, T](t)
+	case *Tensor[types.Float16]:
+		return AsType[types.Float16, T](t)
+	case *Tensor[types.BFloat16]:
+		return AsType[types.BFloat16, T](t)
+	case *Tensor[float32]:
+		return AsType[float32, T](t)
+	case *Tensor[float64]:
+		return AsType[float64, T](t)
+	}
+	ret := Scalar[T](0)
+	ret.Err = fmt.Errorf("unsupported dtype %v", tensor.dtype)
+	return ret
+}
+
+// Returns the tensor as T if the conversion keeps every value (see CanCast).
+// Lossy conversions must be requested explicitly with Cast.
+func Unwrap[T types.TensorType](tensor *AnyTensor) (*Tensor[T], error) {
+	if tensor.Err != nil {
+		return nil, tensor.Err
+	}
+	if to := DTypeOf[T](); !CanCast(tensor.dtype, to) {
+		return nil, fmt.Errorf(
+			"converting %v to %v may lose precision, use Cast to convert explicitly", tensor.dtype, to)
+	}
+	return Cast[T](tensor), nil
+}
+
+// Explicit conversion to the dtype, see Cast
+func (tensor *AnyTensor) AsDType(dtype DType) *AnyTensor {
+	if tensor.Err != nil {
+		return tensor
+	}
+	switch dtype {
+	case Uint8:
+		return Wrap(Cast[uint8](tensor))
+	case Uint16:
+		return Wrap(Cast[uint16](tensor))
+	case Uint32:
+		return Wrap(Cast[uint32](tensor))
+	case Uint64:
+		return Wrap(Cast[uint64](tensor))
+	case Uint:
+		return Wrap(Cast[uint](tensor))
+	case Int8:
+		return Wrap(Cast[int8](tensor))
+	case Int16:
+		return Wrap(Cast[int16](tensor))
+	case Int32:
+		return Wrap(Cast[int32](tensor))
+	case Int64:
+		return Wrap(Cast[int64](tensor))
+	case Int:
+		return Wrap(Cast[int](tensor))
+	case Float16:
+		return Wrap(Cast[types.Float16](tensor))
+	case BFloat16:
+		return Wrap(Cast[types.BFloat16](tensor))
+	case Float32:
+		return Wrap(Cast[float32](tensor))
+	case Float64:
+		return Wrap(Cast[float64](tensor))
+	}
+	tensor.Err = fmt.Errorf("unsupported dtype %v", dtype)
+	return tensor
+}
+
+type binary_op uint8
+
+const (
+	op_add binary_op = iota
+	op_sub
+	op_mul
+	op_div
+	op_pow
+	op_mod
+	op_floor_div
+	op_maximum
+	op_minimum
+	op_eq
+	op_ne
+	op_lt
+	op_le
+	op_gt
+	op_ge
+	op_matmul
+)
+
+// arithmetic ops give *AnyTensor, comparisons give *BoolTensor
+func apply_binary[T types.TensorType](a, b *AnyTensor, op binary_op) any {
+	x, y := Cast[T](a), Cast[T](b)
+	var out *Tensor[T]
+	switch op {
+	case op_add:
+		out = x.Add(y)
+	case op_sub:
+		out = x.Sub(y)
+	case op_mul:
+		out = x.Mul(y)
+	case op_div:
+		out = x.Div(y)
+	case op_pow:
+		out = x.Pow(y)
+	case op_mod:
+		out = x.Mod(y)
+	case op_floor_div:
+		out = x.FloorDiv(y)
+	case op_maximum:
+		out = x.Maximum(y)
+	case op_minimum:
+		out = x.Minimum(y)
+	case op_eq:
+		return x.Eq(y)
+	case op_ne:
+		return x.Ne(y)
+	case op_lt:
+		return x.Lt(y)
+	case op_le:
+		return x.Le(y)
+	case op_gt:
+		return x.Gt(y)
+	case op_ge:
+		return x.Ge(y)
+	case op_matmul:
+		out = x.MatMul(y)
+	}
+	return Wrap(out)
+}
+
+// applies the op in the dtype both operands are promoted to
+func promote_binary(a, b *AnyTensor, op binary_op) (any, error) {
+	dtype, err := PromoteTypes(a.dtype, b.dtype)
+	if err != nil {
+		return nil, err
+	}
+	switch dtype {
+	case Uint8:
+		return apply_binary[uint8](a, b, op), nil
+	case Uint16:
+		return apply_binary[uint16](a, b, op), nil
+	case Uint32:
+		return apply_binary[uint32](a, b, op), nil
+	case Uint64:
+		return apply_binary[uint64](a, b, op), nil
+	case Uint:
+		return apply_binary[uint](a, b, op), nil
+	case Int8:
+		return apply_binary[int8](a, b, op), nil
+	case Int16:
+		return apply_binary[int16](a, b, op), nil
+	case Int32:
+		return apply_binary[int32](a, b, op), nil
+	case Int64:
+		return apply_binary[int64](a, b, op), nil
+	case Int:
+		return apply_binary[int](a, b, op), nil
+	case Float16:
+		return apply_binary[types.Float16](a, b, op), nil
+	case BFloat16:
+		return apply_binary[types.BFloat16](a, b, op), nil
+	case Float32:
+		return apply_binary[float32](a, b, op), nil
+	case Float64:
+		return apply_binary[float64](a, b, op), nil
+	}
+	return nil, fmt.Errorf("unsupported dtype %v", dtype)
+}
+
+func (tensor *AnyTensor) binary(other *AnyTensor, op binary_op) *AnyTensor {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if other.Err != nil {
+		tensor.Err = other.Err
+		return tensor
+	}
+	out, err := promote_binary(tensor, other, op)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	return out.(*AnyTensor)
+}
+
+func (tensor *AnyTensor) compare(other *AnyTensor, op binary_op) *BoolTensor {
+	if tensor.Err != nil {
+		return errBool(tensor.Err)
+	}
+	if other.Err != nil {
+		return errBool(other.Err)
+	}
+	out, err := promote_binary(tensor, other, op)
+	if err != nil {
+		return errBool(err)
+	}
+	return out.(*BoolTensor)
+}
+
+// Elementwise ops with dtype promotion. Operands are broadcasted like in the typed ops.
+
+func (tensor *AnyTensor) Add(other *AnyTensor) *AnyTensor {
+	return tensor.binary(other, op_add)
+}
+
+func (tensor *AnyTensor) Sub(other *AnyTensor) *AnyTensor {
+	return tensor.binary(other, op_sub)
+}
+
+func (tensor *AnyTensor) Mul(other *AnyTensor) *AnyTensor {
+	return tensor.binary(other, op_mul)
+}
+
+// Division in the promoted dtype, so integers are divided as integers.
+// Cast one operand to a float first for the true division.
+func (tensor *AnyTensor) Div(other *AnyTensor) *AnyTensor {
+	return tensor.binary(other, op_div)
+}
+
+func (tensor *AnyTensor) Pow(other *AnyTensor) *AnyTensor {
+	return tensor.binary(other, op_pow)
+}
+
+func (tensor *AnyTensor) Mod(other *AnyTensor) *AnyTensor {
+	return tensor.binary(other, op_mod)
+}
+
+func (tensor *AnyTensor) FloorDiv(other *AnyTensor) *AnyTensor {
+	return tensor.binary(other, op_floor_div)
+}
+
+func (tensor *AnyTensor) Maximum(other *AnyTensor) *AnyTensor {
+	return tensor.binary(other, op_maximum)
+}
+
+func (tensor *AnyTensor) Minimum(other *AnyTensor) *AnyTensor {
+	return tensor.binary(other, op_minimum)
+}
+
+// Comparisons are done in the promoted dtype
+
+func (tensor *AnyTensor) Eq(other *AnyTensor) *BoolTensor {
+	return tensor.compare(other, op_eq)
+}
+
+func (tensor *AnyTensor) Ne(other *AnyTensor) *BoolTensor {
+	return tensor.compare(other, op_ne)
+}
+
+func (tensor *AnyTensor) Lt(other *AnyTensor) *BoolTensor {
+	return tensor.compare(other, op_lt)
+}
+
+func (tensor *AnyTensor) Le(other *AnyTensor) *BoolTensor {
+	return tensor.compare(other, op_le)
+}
+
+func (tensor *AnyTensor) Gt(other *AnyTensor) *BoolTensor {
+	return tensor.compare(other, op_gt)
+}
+
+func (tensor *AnyTensor) Ge(other *AnyTensor) *BoolTensor {
+	return tensor.compare(other, op_ge)
+}
+
+func (tensor *AnyTensor) MatMul(other *AnyTensor) *AnyTensor {
+	return tensor.binary(other, op_matmul)
+}
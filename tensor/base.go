@@ -7,11 +7,16 @@ import (
 
 // fieldalignment -fix gograd/tensor
 type Tensor[T types.TensorType] struct {
-	Err       error
+	Err error
+	// data_buff can be shared between tensor and its views.
+	// For views it starts at the lowest reachable element
 	data_buff []T
 	shape     types.Shape
 	strides   []int
 	dim_order []uint16
+	// position of the first element (with index [0,0,...]) in data_buff.
+	// Non zero only for views with negative strides
+	offset int
 }
 
 func (tensor *Tensor[T]) Shape() types.Shape {
@@ -34,8 +39,10 @@ func (tensor *Tensor[T]) Order() []uint16 {
 	return tensor.dim_order
 }
 
+// Returns elements of the tensor in row-major order.
+// For contiguous tensors the memory is shared, non-contiguous tensors (views, transposed) are materialized.
 func (tensor *Tensor[T]) Data() []T {
-	return tensor.data()
+	return tensor.AsContiguous().data()
 }
 
 func (tensor *Tensor[T]) data() []T {
@@ -43,11 +50,10 @@ func (tensor *Tensor[T]) data() []T {
 }
 
 func (tensor *Tensor[T]) Item() T {
-	data := tensor.data()
-	if len(data) > 1 {
+	if tensor.Size() > 1 {
 		panic("cannot use Item() on non-scalar tensors")
 	}
-	return data[0]
+	return tensor.data()[tensor.offset]
 }
 
 func (tensor *Tensor[T]) DType() reflect.Type {
@@ -0,0 +1,100 @@
+package tensor
+
+import (
+	"fmt"
+	"gograd/tensor/internal"
+	"gograd/tensor/internal/device"
+)
+
+// Bitwise ops are defined for integer tensors only, float tensors get an error.
+// Operands are broadcasted like in other binary ops.
+
+func (tensor *Tensor[T]) check_integer(op string) error {
+	if internal.IsFloat[T]() || is_half[T]() {
+		return fmt.Errorf("%v is not supported for float type %T, only integer types are allowed", op, *new(T))
+	}
+	return nil
+}
+
+func (tensor *Tensor[T]) bitwise(
+	op string,
+	other *Tensor[T],
+	scalar_impl func(T, T) T,
+	vector_impl func(device.Implementation, []T, []T, []T),
+	out *Tensor[T],
+) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if err := tensor.check_integer(op); err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	return baseBinElementwiseOp(tensor, other, scalar_impl, vector_impl, out)
+}
+
+func (tensor *Tensor[T]) bitwise_unary(
+	op string,
+	scalar_impl func(T) T,
+	vector_impl func(device.Implementation, []T, []T),
+	out *Tensor[T],
+) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if err := tensor.check_integer(op); err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	return unaryElementwiseRoutine(tensor, scalar_impl, vector_impl, out)
+}
+
+// Elementwise a & b
+//
+// Example:
+// a = [12,10]
+// a.BitAnd(Scalar(6)) => [4,2]
+func (tensor *Tensor[T]) BitAnd(other *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	return tensor.bitwise("BitAnd", other, internal.BitAndAtomic[T], device.BitAnd[T], get_param(out...))
+}
+
+// Elementwise a | b
+func (tensor *Tensor[T]) BitOr(other *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	return tensor.bitwise("BitOr", other, internal.BitOrAtomic[T], device.BitOr[T], get_param(out...))
+}
+
+// Elementwise a ^ b
+func (tensor *Tensor[T]) BitXor(other *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	return tensor.bitwise("BitXor", other, internal.BitXorAtomic[T], device.BitXor[T], get_param(out...))
+}
+
+// Elementwise ^a, for signed types it equals -a-1
+func (tensor *Tensor[T]) BitNot(out ...*Tensor[T]) *Tensor[T] {
+	return tensor.bitwise_unary("BitNot", internal.BitNotAtomic[T], device.BitNot[T], get_param(out...))
+}
+
+// Elementwise a << b. Bits shifted out of the type's width are dropped.
+// Negative shift counts give 0.
+//
+// Example:
+// a = [1,3]
+// a.Shl(Scalar(4)) => [16,48]
+func (tensor *Tensor[T]) Shl(other *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	return tensor.bitwise("Shl", other, internal.ShlAtomic[T], device.Shl[T], get_param(out...))
+}
+
+// Elementwise a >> b. The shift is arithmetic for signed types and logical for unsigned.
+// Negative shift counts give 0 or -1 depending on the sign of a.
+//
+// Example:
+// a = [-16,16]
+// a.Shr(Scalar(2)) => [-4,4]
+func (tensor *Tensor[T]) Shr(other *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	return tensor.bitwise("Shr", other, internal.ShrAtomic[T], device.Shr[T], get_param(out...))
+}
+
+// Number of set bits of every element. Negative values are counted in the type's width,
+// so PopCount of int8 -1 is 8
+func (tensor *Tensor[T]) PopCount(out ...*Tensor[T]) *Tensor[T] {
+	return tensor.bitwise_unary("PopCount", internal.PopCountAtomic[T], device.PopCount[T], get_param(out...))
+}
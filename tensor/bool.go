@@ -0,0 +1,300 @@
+package tensor
+
+import (
+	"fmt"
+	types "gograd/tensor/types"
+	"slices"
+	"strings"
+)
+
+// BoolTensor holds a boolean mask with one byte per element,
+// so it takes 4-8x less memory than a mask stored as a float or int tensor.
+// Comparisons like Eq produce it, Where, MaskedFill, MaskedSelect and IndexMask consume it.
+// The data is always contiguous in row-major order.
+//
+// Example:
+// a = [[1,2],[3,4]]
+// mask := a.Gt(Scalar(2)) => [[false, false], [true, true]]
+// mask.Any(false, 1) => [false, true]
+type BoolTensor struct {
+	Err   error
+	data  []bool
+	shape types.Shape
+}
+
+func errBool(err error) *BoolTensor {
+	return &BoolTensor{Err: err}
+}
+
+// creates a bool tensor, the data is copied
+func CreateBoolTensor(data []bool, shape types.Shape) *BoolTensor {
+	return CreateBoolTensorNoCopy(slices.Clone(data), shape)
+}
+
+// creates a bool tensor which shares the data
+func CreateBoolTensorNoCopy(data []bool, shape types.Shape) *BoolTensor {
+	size := 1
+	for _, dim := range shape {
+		if dim < 0 {
+			return errBool(fmt.Errorf("shape %v cannot have negative dims", shape))
+		}
+		size *= int(dim)
+	}
+	if size != len(data) {
+		return errBool(fmt.Errorf("value length %v cannot have shape %v", len(data), shape))
+	}
+	return &BoolTensor{data: data, shape: slices.Clone(shape)}
+}
+
+// Converts the tensor to a bool tensor, any non zero value is true.
+//
+// Example:
+// AsBool([0,2,-1]) => [false, true, true]
+func AsBool[T types.TensorType](tensor *Tensor[T]) *BoolTensor {
+	if tensor.Err != nil {
+		return errBool(tensor.Err)
+	}
+	if is_half[T]() {
+		// -0 has non zero bits
+		return AsBool(half_to_float32(tensor))
+	}
+	values := tensor.AsContiguous().data()
+	data := make([]bool, len(values))
+	for i, v := range values {
+		data[i] = v != 0
+	}
+	return &BoolTensor{data: data, shape: slices.Clone(tensor.shape)}
+}
+
+// Converts the mask to a tensor with 1 for true and 0 for false
+//
+// Example:
+// FromBool[float32]([true, false]) => [1, 0]
+func FromBool[T types.TensorType](mask *BoolTensor) *Tensor[T] {
+	if mask.Err != nil {
+		return &Tensor[T]{Err: mask.Err}
+	}
+	data := make([]T, len(mask.data))
+	value := one[T]()
+	for i, v := range mask.data {
+		if v {
+			data[i] = value
+		}
+	}
+	return CreateTensorNoCopy(data, slices.Clone(mask.shape))
+}
+
+func (mask *BoolTensor) Shape() types.Shape {
+	return mask.shape
+}
+
+func (mask *BoolTensor) Size() int {
+	return len(mask.data)
+}
+
+// Returns the elements in row-major order, the memory is shared with the tensor
+func (mask *BoolTensor) Data() []bool {
+	return mask.data
+}
+
+func (mask *BoolTensor) Item() bool {
+	if len(mask.data) != 1 {
+		panic("cannot use Item() on non-scalar tensors")
+	}
+	return mask.data[0]
+}
+
+func (mask *BoolTensor) Clone() *BoolTensor {
+	if mask.Err != nil {
+		return mask
+	}
+	return &BoolTensor{data: slices.Clone(mask.data), shape: slices.Clone(mask.shape)}
+}
+
+// Returns a tensor with the new shape sharing the data. One dim can be -1 to infer it.
+func (mask *BoolTensor) Reshape(shape ...types.Dim) *BoolTensor {
+	if mask.Err != nil {
+		return mask
+	}
+	new_shape, err := infer_shape(len(mask.data), shape)
+	if err != nil {
+		mask.Err = err
+		return mask
+	}
+	return &BoolTensor{data: mask.data, shape: new_shape}
+}
+
+// writes the values as nested lists
+func bool_repr(sb *strings.Builder, data []bool, shape types.Shape) {
+	if len(shape) == 0 {
+		fmt.Fprint(sb, data[0])
+		return
+	}
+	sb.WriteRune('[')
+	step := len(data) / max(int(shape[0]), 1)
+	for i := 0; i < int(shape[0]); i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		bool_repr(sb, data[i*step:(i+1)*step], shape[1:])
+	}
+	sb.WriteRune(']')
+}
+
+func (mask *BoolTensor) ToString() string {
+	mask.MustAssert()
+	var sb strings.Builder
+	bool_repr(&sb, mask.data, mask.shape)
+	return fmt.Sprintf("BoolTensor(%v, shape=%v, dtype=bool)", sb.String(), mask.shape)
+}
+
+func (mask *BoolTensor) MustAssert() *BoolTensor {
+	if mask.Err != nil {
+		panic(mask.Err)
+	}
+	return mask
+}
+
+//
+// logical ops
+//
+
+func (mask *BoolTensor) binary(other *BoolTensor, fn func(a, b bool) bool) *BoolTensor {
+	if mask.Err != nil {
+		return mask
+	}
+	if other.Err != nil {
+		return other
+	}
+	data, shape, err := broadcast_apply(mask.data, mask.shape, other.data, other.shape, fn)
+	if err != nil {
+		mask.Err = err
+		return mask
+	}
+	return &BoolTensor{data: data, shape: shape}
+}
+
+// Elementwise AND, operands are broadcasted
+func (mask *BoolTensor) And(other *BoolTensor) *BoolTensor {
+	return mask.binary(other, func(a, b bool) bool { return a && b })
+}
+
+// Elementwise OR, operands are broadcasted
+func (mask *BoolTensor) Or(other *BoolTensor) *BoolTensor {
+	return mask.binary(other, func(a, b bool) bool { return a || b })
+}
+
+// Elementwise XOR, operands are broadcasted
+func (mask *BoolTensor) Xor(other *BoolTensor) *BoolTensor {
+	return mask.binary(other, func(a, b bool) bool { return a != b })
+}
+
+func (mask *BoolTensor) Not() *BoolTensor {
+	if mask.Err != nil {
+		return mask
+	}
+	data := make([]bool, len(mask.data))
+	for i, v := range mask.data {
+		data[i] = !v
+	}
+	return &BoolTensor{data: data, shape: slices.Clone(mask.shape)}
+}
+
+//
+// reductions
+//
+
+// folds the values along the axes, all axes by default
+func reduce_mask[O any](mask *BoolTensor, keep_dims bool, axes []int, init O, fn func(O, bool) O) ([]O, types.Shape, error) {
+	if len(axes) == 0 {
+		axes = all_axes(mask.shape)
+	}
+	axes, err := normalize_axes(mask.shape, axes)
+	if err != nil {
+		return nil, nil, err
+	}
+	out_shape := slices.Clone(mask.shape)
+	for _, axis := range axes {
+		out_shape[axis] = 1
+	}
+	out := make([]O, shape_size(out_shape))
+	for i := range out {
+		out[i] = init
+	}
+	// reduced axes get 0 stride, so the values along them are folded into the same output
+	out_strides := broadcast_strides(out_shape, mask.shape)
+	idx := make([]int, len(mask.shape))
+	offset := 0
+	for _, v := range mask.data {
+		out[offset] = fn(out[offset], v)
+		for d := len(mask.shape) - 1; d >= 0; d-- {
+			idx[d]++
+			offset += out_strides[d]
+			if idx[d] < int(mask.shape[d]) {
+				break
+			}
+			offset -= out_strides[d] * idx[d]
+			idx[d] = 0
+		}
+	}
+	if !keep_dims {
+		out_shape = drop_axes(mask.shape, axes)
+	}
+	return out, out_shape, nil
+}
+
+func (mask *BoolTensor) reduce(keep_dims bool, axes []int, init bool, fn func(bool, bool) bool) *BoolTensor {
+	if mask.Err != nil {
+		return mask
+	}
+	data, shape, err := reduce_mask(mask, keep_dims, axes, init, fn)
+	if err != nil {
+		mask.Err = err
+		return mask
+	}
+	return &BoolTensor{data: data, shape: shape}
+}
+
+// Reports whether any value is true along the axes, all axes by default.
+// Reduction of an empty axis gives false.
+//
+// Example:
+// mask = [[true, false], [false, false]]
+// mask.Any(false, 1) => [true, false]
+func (mask *BoolTensor) Any(keep_dims bool, axes ...int) *BoolTensor {
+	return mask.reduce(keep_dims, axes, false, func(acc, v bool) bool { return acc || v })
+}
+
+// Reports whether all values are true along the axes, all axes by default.
+// Reduction of an empty axis gives true.
+func (mask *BoolTensor) All(keep_dims bool, axes ...int) *BoolTensor {
+	return mask.reduce(keep_dims, axes, true, func(acc, v bool) bool { return acc && v })
+}
+
+// Number of true values along the axes, all axes by default
+func (mask *BoolTensor) Count(keep_dims bool, axes ...int) *Tensor[int] {
+	if mask.Err != nil {
+		return &Tensor[int]{Err: mask.Err}
+	}
+	data, shape, err := reduce_mask(mask, keep_dims, axes, 0, func(acc int, v bool) int {
+		if v {
+			return acc + 1
+		}
+		return acc
+	})
+	if err != nil {
+		return &Tensor[int]{Err: err}
+	}
+	return CreateTensorNoCopy(data, shape)
+}
+
+// repeats the mask to the shape, the shape must be broadcastable from the shape of the mask
+func (mask *BoolTensor) broadcast_data(shape types.Shape) []bool {
+	if mask.shape.Equals(shape) {
+		return mask.data
+	}
+	// broadcasting against placeholders of the shape repeats the values
+	data, _, _ := broadcast_apply(mask.data, mask.shape, make([]struct{}, shape_size(shape)), shape,
+		func(v bool, _ struct{}) bool { return v })
+	return data
+}
@@ -1,7 +1,9 @@
 package tensor
 
 import (
+	"fmt"
 	types "gograd/tensor/types"
+	"slices"
 )
 
 // tries to broadcast the shape and replicate the data accordingly
@@ -12,84 +14,81 @@ func (tensor *Tensor[T]) Broadcast(shape ...types.Dim) *Tensor[T] {
 	if tensor.shape.Equals(shape) {
 		return tensor
 	}
-	// TODO test with transpose
-
 	broadcastedShape := tensor.shape.BroadcastShapes(shape)
 
-	outTensor := CreateEmptyTensor[T](broadcastedShape...)
+	// broadcasted dims get zero strides, so the view repeats the data along them
+	return tensor.broadcastView(broadcastedShape).AsContiguous()
+}
 
-	// shape diff
-	// compares shapes and sets 1 if the dim is changed
-	tensor_dims := len(tensor.shape)
-	shape_diff := make([]int, len(broadcastedShape))
-	for i := 0; i < len(broadcastedShape); i++ {
-		j := len(broadcastedShape) - i - 1
-		if tensor_dims-i > 0 {
-			if broadcastedShape[j] != tensor.shape[tensor_dims-i-1] {
-				shape_diff[j] = 1
-			}
-		} else {
-			shape_diff[j] = 1
+// view of the tensor broadcasted to the shape, the data is not copied.
+// Broadcasted and missing leading dims get zero strides. Shape must be broadcastable
+func (tensor *Tensor[T]) broadcastView(shape types.Shape) *Tensor[T] {
+	strides := make([]int, len(shape))
+	lead := len(shape) - len(tensor.shape)
+	for i, dim := range tensor.shape {
+		if dim == shape[lead+i] {
+			strides[lead+i] = tensor.strides[i]
 		}
 	}
+	return tensor.makeView(tensor.offset, shape, strides)
+}
 
-	// repeat data to fill broadcasted dims
-	sub_index := make([]int, tensor_dims)
-	repeat, last_repeat := 1, 1
-	is_innermost_broadcast := true
-	for i := len(shape_diff) - 1; i >= 0; i-- {
-		if shape_diff[i] != 1 {
-			// if shape_diff[i] == 0 that means that broadsacting
-			// will be applied to sub tensors
-			is_innermost_broadcast = false
-			continue
-		}
-		repeat *= int(broadcastedShape[i])
-		if i > 0 && shape_diff[i-1] == 1 {
-			// if shape_diff[i-1] == 1 previous (inner) dim was changed
-			continue
-		}
+func shape_size(shape types.Shape) int {
+	size := 1
+	for _, dim := range shape {
+		size *= int(dim)
+	}
+	return size
+}
 
-		// TODO test for other shapes
-		if is_innermost_broadcast {
-			is_innermost_broadcast = false
-			for i, val := range tensor.data() {
-				for j := 0; j < repeat; j++ {
-					outTensor.data()[i*repeat+j] = val
-				}
-			}
-			last_repeat = repeat * len(tensor.data())
-			repeat = 1
-			continue
+// strides of the contiguous shape aligned to the broadcasted shape, broadcasted dims get 0 stride
+func broadcast_strides(shape, out_shape types.Shape) []int {
+	strides := make([]int, len(out_shape))
+	stride := 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		if shape[i] != 1 {
+			strides[i+len(out_shape)-len(shape)] = stride
 		}
+		stride *= int(shape[i])
+	}
+	return strides
+}
 
-		if last_repeat == 1 {
-			// if last_repeat == 1 this is the first time we need to broadcast,
-			// so we take a sub tensor using Index() from original tensor and repeat it.
-			// sub: [1,2,3], repeat 2 => [1,2,3,1,2,3]
-			var sub *Tensor[T] = tensor
-
-			if i > 0 {
-				sub = tensor.Index(sub_index[:i]...)
-			}
-			for j := 0; j < repeat; j++ { // repeat
-				start := j * len(sub.data())
-				end := len(sub.data()) * (j + 1)
-				copy(outTensor.data()[start:end], sub.data())
-			}
-		} else {
-			// if broadcasting already happened in inner dimensions,
-			// that means outTensor contains repeated data, we just repeat is again with the current factor
-			sub_data := outTensor.data()[:last_repeat]
-			for j := 1; j < repeat; j++ { // repeat
-				start := j * len(sub_data)
-				end := len(sub_data) * (j + 1)
-				copy(outTensor.data()[start:end], sub_data)
+// applies fn to the contiguous data of a and b broadcasted together.
+// Used by the tensor types which don't support views, e.g. ComplexTensor and BoolTensor
+func broadcast_apply[A, B, O any](
+	a []A, a_shape types.Shape, b []B, b_shape types.Shape, fn func(A, B) O,
+) ([]O, types.Shape, error) {
+	if a_shape.Equals(b_shape) {
+		out := make([]O, len(a))
+		for i := range out {
+			out[i] = fn(a[i], b[i])
+		}
+		return out, slices.Clone(a_shape), nil
+	}
+	if !a_shape.AreBroadcastable(b_shape) {
+		return nil, nil, fmt.Errorf("shapes: %v, %v are not broadcastable", a_shape, b_shape)
+	}
+	shape := a_shape.BroadcastShapes(b_shape)
+	a_strides := broadcast_strides(a_shape, shape)
+	b_strides := broadcast_strides(b_shape, shape)
+	out := make([]O, shape_size(shape))
+	idx := make([]int, len(shape))
+	i_a, i_b := 0, 0
+	for i := range out {
+		out[i] = fn(a[i_a], b[i_b])
+		// next multi-index, the offsets are updated incrementally
+		for d := len(shape) - 1; d >= 0; d-- {
+			idx[d]++
+			i_a += a_strides[d]
+			i_b += b_strides[d]
+			if idx[d] < int(shape[d]) {
+				break
 			}
+			i_a -= a_strides[d] * idx[d]
+			i_b -= b_strides[d] * idx[d]
+			idx[d] = 0
 		}
-
-		last_repeat = repeat
-		repeat = 1
 	}
-	return outTensor
+	return out, slices.Clone(shape), nil
 }
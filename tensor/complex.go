@@ -0,0 +1,253 @@
+package tensor
+
+import (
+	"fmt"
+	types "gograd/tensor/types"
+	"math/cmplx"
+	"slices"
+)
+
+// ComplexTensor holds complex64 or complex128 values.
+// Complex numbers can't be compared, so they don't fit the ops of Tensor[T].
+// The data is always contiguous in row-major order.
+type ComplexTensor[C types.Complex] struct {
+	Err   error
+	data  []C
+	shape types.Shape
+}
+
+func errComplex[C types.Complex](err error) *ComplexTensor[C] {
+	return &ComplexTensor[C]{Err: err}
+}
+
+// creates a complex tensor, the data is copied
+func CreateComplexTensor[C types.Complex](data []C, shape types.Shape) *ComplexTensor[C] {
+	return CreateComplexTensorNoCopy(slices.Clone(data), shape)
+}
+
+// creates a complex tensor which shares the data
+func CreateComplexTensorNoCopy[C types.Complex](data []C, shape types.Shape) *ComplexTensor[C] {
+	size := 1
+	for _, dim := range shape {
+		if dim < 0 {
+			return errComplex[C](fmt.Errorf("shape %v cannot have negative dims", shape))
+		}
+		size *= int(dim)
+	}
+	if size != len(data) {
+		return errComplex[C](fmt.Errorf("value length %v cannot have shape %v", len(data), shape))
+	}
+	return &ComplexTensor[C]{data: data, shape: slices.Clone(shape)}
+}
+
+// complex tensor with the given real and imaginary parts. The parts are broadcasted.
+//
+// Example:
+// Complex[complex64](Range[float32](2), Scalar[float32](1)) => [0+1i, 1+1i]
+func Complex[C types.Complex, F types.Float](re, im *Tensor[F]) *ComplexTensor[C] {
+	if re.Err != nil {
+		return errComplex[C](re.Err)
+	}
+	if im.Err != nil {
+		return errComplex[C](im.Err)
+	}
+	if !re.shape.AreBroadcastable(im.shape) {
+		return errComplex[C](fmt.Errorf("shapes: %v, %v are not broadcastable", re.shape, im.shape))
+	}
+	shape := re.shape.BroadcastShapes(im.shape)
+	re_data := re.Broadcast(shape...).Data()
+	im_data := im.Broadcast(shape...).Data()
+	data := make([]C, len(re_data))
+	for i := range data {
+		data[i] = C(complex(float64(re_data[i]), float64(im_data[i])))
+	}
+	return &ComplexTensor[C]{data: data, shape: slices.Clone(shape)}
+}
+
+// converts a real tensor to complex values with zero imaginary parts
+func AsComplex[C types.Complex, T types.TensorType](tensor *Tensor[T]) *ComplexTensor[C] {
+	if tensor.Err != nil {
+		return errComplex[C](tensor.Err)
+	}
+	values := AsType[T, float64](tensor).Data()
+	data := make([]C, len(values))
+	for i, v := range values {
+		data[i] = C(complex(v, 0))
+	}
+	return &ComplexTensor[C]{data: data, shape: slices.Clone(tensor.shape)}
+}
+
+func (tensor *ComplexTensor[C]) Shape() types.Shape {
+	return tensor.shape
+}
+
+func (tensor *ComplexTensor[C]) Size() int {
+	return len(tensor.data)
+}
+
+// Returns the elements in row-major order, the memory is shared with the tensor
+func (tensor *ComplexTensor[C]) Data() []C {
+	return tensor.data
+}
+
+func (tensor *ComplexTensor[C]) Item() C {
+	if len(tensor.data) != 1 {
+		panic("cannot use Item() on non-scalar tensors")
+	}
+	return tensor.data[0]
+}
+
+func (tensor *ComplexTensor[C]) Clone() *ComplexTensor[C] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	return &ComplexTensor[C]{data: slices.Clone(tensor.data), shape: slices.Clone(tensor.shape)}
+}
+
+// Returns a tensor with the new shape sharing the data. One dim can be -1 to infer it.
+func (tensor *ComplexTensor[C]) Reshape(shape ...types.Dim) *ComplexTensor[C] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	new_shape, err := infer_shape(len(tensor.data), shape)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	return &ComplexTensor[C]{data: tensor.data, shape: new_shape}
+}
+
+func (tensor *ComplexTensor[C]) ToString() string {
+	tensor.MustAssert()
+	return fmt.Sprintf("ComplexTensor(%v, shape=%v, dtype=%T)", tensor.data, tensor.shape, *new(C))
+}
+
+func (tensor *ComplexTensor[C]) MustAssert() *ComplexTensor[C] {
+	if tensor.Err != nil {
+		panic(tensor.Err)
+	}
+	return tensor
+}
+
+// maps complex values to a real tensor of type F
+func complex_to_real[F types.Float, C types.Complex](tensor *ComplexTensor[C], fn func(complex128) float64) *Tensor[F] {
+	if tensor.Err != nil {
+		return &Tensor[F]{Err: tensor.Err}
+	}
+	data := make([]F, len(tensor.data))
+	for i, v := range tensor.data {
+		data[i] = F(fn(complex128(v)))
+	}
+	return CreateTensorNoCopy(data, slices.Clone(tensor.shape))
+}
+
+// Real part of the values
+//
+// Example:
+// Real[float32](z) for complex64 z
+func Real[F types.Float, C types.Complex](tensor *ComplexTensor[C]) *Tensor[F] {
+	return complex_to_real[F](tensor, func(v complex128) float64 { return real(v) })
+}
+
+// Imaginary part of the values
+func Imag[F types.Float, C types.Complex](tensor *ComplexTensor[C]) *Tensor[F] {
+	return complex_to_real[F](tensor, func(v complex128) float64 { return imag(v) })
+}
+
+// Magnitude of the values
+func Abs[F types.Float, C types.Complex](tensor *ComplexTensor[C]) *Tensor[F] {
+	return complex_to_real[F](tensor, cmplx.Abs)
+}
+
+// Phase of the values in radians, in range [-Pi, Pi]
+func Angle[F types.Float, C types.Complex](tensor *ComplexTensor[C]) *Tensor[F] {
+	return complex_to_real[F](tensor, cmplx.Phase)
+}
+
+func (tensor *ComplexTensor[C]) apply(fn func(complex128) complex128) *ComplexTensor[C] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	data := make([]C, len(tensor.data))
+	for i, v := range tensor.data {
+		data[i] = C(fn(complex128(v)))
+	}
+	return &ComplexTensor[C]{data: data, shape: slices.Clone(tensor.shape)}
+}
+
+// Complex conjugate
+func (tensor *ComplexTensor[C]) Conj() *ComplexTensor[C] {
+	return tensor.apply(cmplx.Conj)
+}
+
+func (tensor *ComplexTensor[C]) Exp() *ComplexTensor[C] {
+	return tensor.apply(cmplx.Exp)
+}
+
+// general binary op with broadcasting
+func (tensor *ComplexTensor[C]) binary(other *ComplexTensor[C], fn func(C, C) C) *ComplexTensor[C] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if other.Err != nil {
+		return other
+	}
+	data, shape, err := broadcast_apply(tensor.data, tensor.shape, other.data, other.shape, fn)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	return &ComplexTensor[C]{data: data, shape: shape}
+}
+
+// Elementwise ops, operands are broadcasted
+
+func (tensor *ComplexTensor[C]) Add(other *ComplexTensor[C]) *ComplexTensor[C] {
+	return tensor.binary(other, func(a, b C) C { return a + b })
+}
+
+func (tensor *ComplexTensor[C]) Sub(other *ComplexTensor[C]) *ComplexTensor[C] {
+	return tensor.binary(other, func(a, b C) C { return a - b })
+}
+
+func (tensor *ComplexTensor[C]) Mul(other *ComplexTensor[C]) *ComplexTensor[C] {
+	return tensor.binary(other, func(a, b C) C { return a * b })
+}
+
+func (tensor *ComplexTensor[C]) Div(other *ComplexTensor[C]) *ComplexTensor[C] {
+	return tensor.binary(other, func(a, b C) C { return a / b })
+}
+
+// Sum of all elements or sum along given axes
+func (tensor *ComplexTensor[C]) Sum(keep_dims bool, axes ...int) *ComplexTensor[C] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if len(axes) == 0 {
+		axes = all_axes(tensor.shape)
+	}
+	axes, err := normalize_axes(tensor.shape, axes)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	// reduces the axes one by one starting from the last one, so the indices of others stay the same
+	data, shape := tensor.data, slices.Clone(tensor.shape)
+	for i := len(axes) - 1; i >= 0; i-- {
+		outer, dim, inner := split_shape(shape, axes[i], axes[i]+1)
+		sum := make([]C, outer*inner)
+		for o := 0; o < outer; o++ {
+			for d := 0; d < dim; d++ {
+				for j := 0; j < inner; j++ {
+					sum[o*inner+j] += data[(o*dim+d)*inner+j]
+				}
+			}
+		}
+		data = sum
+		shape[axes[i]] = 1
+	}
+	if !keep_dims {
+		shape = drop_axes(tensor.shape, axes)
+	}
+	return &ComplexTensor[C]{data: data, shape: shape}
+}
@@ -0,0 +1,326 @@
+package tensor
+
+import (
+	"fmt"
+	"gograd/tensor/internal"
+	"gograd/tensor/internal/device"
+	types "gograd/tensor/types"
+	"math"
+	"sync"
+)
+
+// applies the scan kernel along the axis. Result has the shape of the tensor
+func (tensor *Tensor[T]) scanAxis(
+	axis int,
+	kernel func(device.Implementation, []T, []T, int, int, int),
+	out *Tensor[T],
+) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	axes, err := normalize_axes(tensor.shape, []int{axis})
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	if out != nil && !out.IsContiguous() {
+		// output is a view. Compute the result and write it back using view's strides
+		res := tensor.scanAxis(axis, kernel, nil)
+		if res.Err != nil {
+			return res
+		}
+		if _, err := PrepareOutTensor(out, res.shape); err != nil {
+			tensor.Err = err
+			return tensor
+		}
+		out.copyFromContiguous(res)
+		return out
+	}
+	out, err = PrepareOutTensor(out, tensor.shape)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	tensor = tensor.AsContiguous()
+	outer, dim, inner := split_shape(tensor.shape, axes[0], axes[0]+1)
+	kernel(AUTO_IMPL, tensor.data(), out.data(), outer, dim, inner)
+	return out
+}
+
+// Cumulative sum along the axis. Negative axis is counted from the end.
+//
+// Example:
+// a = [
+// [1,2,3],
+// [4,5,6]]
+// a.CumSum(1) => [[1,3,6],[4,9,15]]
+// a.CumSum(0) => [[1,2,3],[5,7,9]]
+func (tensor *Tensor[T]) CumSum(axis int, out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, func(t *Tensor[float32], out ...*Tensor[float32]) *Tensor[float32] {
+			return t.CumSum(axis, out...)
+		}, get_param(out...))
+	}
+	return tensor.scanAxis(axis, device.CumSumAxis[T], get_param(out...))
+}
+
+// Cumulative product along the axis
+//
+// Example:
+// a = [1,2,3,4]
+// a.CumProd(0) => [1,2,6,24]
+func (tensor *Tensor[T]) CumProd(axis int, out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, func(t *Tensor[float32], out ...*Tensor[float32]) *Tensor[float32] {
+			return t.CumProd(axis, out...)
+		}, get_param(out...))
+	}
+	return tensor.scanAxis(axis, device.CumProdAxis[T], get_param(out...))
+}
+
+// Running max along the axis
+//
+// Example:
+// a = [1,3,2,5]
+// a.CumMax(0) => [1,3,3,5]
+func (tensor *Tensor[T]) CumMax(axis int, out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, func(t *Tensor[float32], out ...*Tensor[float32]) *Tensor[float32] {
+			return t.CumMax(axis, out...)
+		}, get_param(out...))
+	}
+	return tensor.scanAxis(axis, device.CumMaxAxis[T], get_param(out...))
+}
+
+// Running min along the axis
+func (tensor *Tensor[T]) CumMin(axis int, out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, func(t *Tensor[float32], out ...*Tensor[float32]) *Tensor[float32] {
+			return t.CumMin(axis, out...)
+		}, get_param(out...))
+	}
+	return tensor.scanAxis(axis, device.CumMinAxis[T], get_param(out...))
+}
+
+// log(CumSum(exp(x))) computed without overflow
+//
+// Example:
+// a = [0,0,0]
+// a.LogCumSumExp(0) => [0, ln2, ln3]
+func (tensor *Tensor[T]) LogCumSumExp(axis int, out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, func(t *Tensor[float32], out ...*Tensor[float32]) *Tensor[float32] {
+			return t.LogCumSumExp(axis, out...)
+		}, get_param(out...))
+	}
+	return tensor.scanAxis(axis, device.LogCumSumExpAxis[T], get_param(out...))
+}
+
+func (tensor *Tensor[T]) argScan(
+	axis int,
+	kernel func(device.Implementation, []T, []int, int, int, int),
+) *Tensor[int] {
+	if tensor.Err != nil {
+		return &Tensor[int]{Err: tensor.Err}
+	}
+	axes, err := normalize_axes(tensor.shape, []int{axis})
+	if err != nil {
+		return &Tensor[int]{Err: err}
+	}
+	tensor = tensor.AsContiguous()
+	outer, dim, inner := split_shape(tensor.shape, axes[0], axes[0]+1)
+	out := CreateEmptyTensor[int](tensor.shape...)
+	kernel(AUTO_IMPL, tensor.data(), out.data(), outer, dim, inner)
+	return out
+}
+
+// Positions of the running max along the axis, the values are CumMax.
+// The last position is taken on ties.
+//
+// Example:
+// a = [1,3,2,3]
+// a.CumArgMax(0) => [0,1,1,3]
+func (tensor *Tensor[T]) CumArgMax(axis int) *Tensor[int] {
+	if is_half[T]() && tensor.Err == nil {
+		return half_to_float32(tensor).CumArgMax(axis)
+	}
+	return tensor.argScan(axis, device.CumArgMaxAxis[T])
+}
+
+// Positions of the running min along the axis. The last position is taken on ties.
+func (tensor *Tensor[T]) CumArgMin(axis int) *Tensor[int] {
+	if is_half[T]() && tensor.Err == nil {
+		return half_to_float32(tensor).CumArgMin(axis)
+	}
+	return tensor.argScan(axis, device.CumArgMinAxis[T])
+}
+
+// n-th discrete difference along the axis: out[i] = a[i+1] - a[i], applied n times.
+// The axis dim shrinks by n, so n must be less than the dim.
+//
+// Example:
+// a = [1,2,4,7]
+// a.Diff(1, 0) => [1,2,3]
+// a.Diff(2, 0) => [1,1]
+func (tensor *Tensor[T]) Diff(n, axis int) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	axes, err := normalize_axes(tensor.shape, []int{axis})
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	axis = axes[0]
+	dim := int(tensor.shape[axis])
+	if n < 0 || n >= dim {
+		tensor.Err = fmt.Errorf("diff order must be in range [0, %v) for the axis %v with dim %v, got %v", dim, axis, dim, n)
+		return tensor
+	}
+	res := tensor.Clone()
+	for i := 0; i < n; i++ {
+		d := int(res.shape[axis])
+		res = res.sliceAxis(axis, 1, d).Sub(res.sliceAxis(axis, 0, d-1))
+	}
+	return res
+}
+
+//
+// gradients
+//
+
+// calls fn for every lane along the axis. Lane elements are at base + k*stride, k < dim
+func for_each_lane(shape types.Shape, axis int, fn func(base, dim, stride int)) {
+	outer, dim, inner := split_shape(shape, axis, axis+1)
+	internal.Parallel(outer*inner,
+		func(start, end int, _, _, _ []int, mu *sync.Mutex) {
+			for j := start; j < end; j++ {
+				o, i := j/inner, j%inner
+				fn(o*dim*inner+i, dim, inner)
+			}
+		}, nil, nil, nil)
+}
+
+// checks the axis and that all tensors have the same shape
+func check_scan_grad[T types.TensorType](axis int, tensors ...*Tensor[T]) (int, error) {
+	for _, t := range tensors {
+		if t.Err != nil {
+			return 0, t.Err
+		}
+		if !t.shape.Equals(tensors[0].shape) {
+			return 0, fmt.Errorf("shapes %v and %v must be equal", tensors[0].shape, t.shape)
+		}
+	}
+	axes, err := normalize_axes(tensors[0].shape, []int{axis})
+	if err != nil {
+		return 0, err
+	}
+	return axes[0], nil
+}
+
+// Gradient of CumSum along the axis, it is the reversed cumulative sum of grad
+func CumSumGrad[T types.TensorType](grad *Tensor[T], axis int) (*Tensor[T], error) {
+	if is_half[T]() {
+		return half_call([]*Tensor[T]{grad}, func(ts []*Tensor[float32]) (*Tensor[float32], error) {
+			return CumSumGrad(ts[0], axis)
+		})
+	}
+	axis, err := check_scan_grad(axis, grad)
+	if err != nil {
+		return nil, err
+	}
+	g := grad.AsContiguous().data()
+	out := CreateEmptyTensor[T](grad.shape...)
+	data := out.data()
+	for_each_lane(grad.shape, axis, func(base, dim, stride int) {
+		var acc T
+		for k := dim - 1; k >= 0; k-- {
+			acc += g[base+k*stride]
+			data[base+k*stride] = acc
+		}
+	})
+	return out, nil
+}
+
+// Gradient of CumProd of x along the axis. Zeros in x are handled without division:
+// d(x_k) = prod(x_0..x_k-1) * r_k, where r_k = g_k + x_k+1 * r_k+1
+func CumProdGrad[T types.TensorType](x, grad *Tensor[T], axis int) (*Tensor[T], error) {
+	if is_half[T]() {
+		return half_call([]*Tensor[T]{x, grad}, func(ts []*Tensor[float32]) (*Tensor[float32], error) {
+			return CumProdGrad(ts[0], ts[1], axis)
+		})
+	}
+	axis, err := check_scan_grad(axis, x, grad)
+	if err != nil {
+		return nil, err
+	}
+	xs, g := x.AsContiguous().data(), grad.AsContiguous().data()
+	out := CreateEmptyTensor[T](x.shape...)
+	data := out.data()
+	for_each_lane(x.shape, axis, func(base, dim, stride int) {
+		var r T
+		for k := dim - 1; k >= 0; k-- {
+			if k < dim-1 {
+				r *= xs[base+(k+1)*stride]
+			}
+			r += g[base+k*stride]
+			data[base+k*stride] = r
+		}
+		var prefix T = 1
+		for k := 0; k < dim; k++ {
+			data[base+k*stride] *= prefix
+			prefix *= xs[base+k*stride]
+		}
+	})
+	return out, nil
+}
+
+// Gradient of y = LogCumSumExp(x) along the axis:
+// d(x_k) = exp(x_k - y_k) * r_k, where r_k = g_k + exp(y_k - y_k+1) * r_k+1.
+// All exponents are not positive, because y is not decreasing
+func LogCumSumExpGrad[T types.TensorType](x, y, grad *Tensor[T], axis int) (*Tensor[T], error) {
+	if is_half[T]() {
+		return half_call([]*Tensor[T]{x, y, grad}, func(ts []*Tensor[float32]) (*Tensor[float32], error) {
+			return LogCumSumExpGrad(ts[0], ts[1], ts[2], axis)
+		})
+	}
+	axis, err := check_scan_grad(axis, x, y, grad)
+	if err != nil {
+		return nil, err
+	}
+	xs, ys, g := x.AsContiguous().data(), y.AsContiguous().data(), grad.AsContiguous().data()
+	out := CreateEmptyTensor[T](x.shape...)
+	data := out.data()
+	for_each_lane(x.shape, axis, func(base, dim, stride int) {
+		var r float64
+		for k := dim - 1; k >= 0; k-- {
+			pos := base + k*stride
+			if k < dim-1 {
+				r *= math.Exp(float64(ys[pos]) - float64(ys[pos+stride]))
+			}
+			r += float64(g[pos])
+			data[pos] = T(math.Exp(float64(xs[pos])-float64(ys[pos])) * r)
+		}
+	})
+	return out, nil
+}
+
+// Gradient of Diff(n, axis), grad has the shape of the Diff result.
+// Every step of the difference gives -g_k to the element k and g_k to the element k+1
+func DiffGrad[T types.TensorType](grad *Tensor[T], n, axis int) (*Tensor[T], error) {
+	axis, err := check_scan_grad(axis, grad)
+	if err != nil {
+		return nil, err
+	}
+	res := grad.Clone()
+	for i := 0; i < n; i++ {
+		shape := append(types.Shape(nil), res.shape...)
+		shape[axis]++
+		out := Zeros[T](shape...)
+		d := int(shape[axis])
+		out.sliceAxis(axis, 1, d).Add(res, out.sliceAxis(axis, 1, d))
+		out.sliceAxis(axis, 0, d-1).Sub(res, out.sliceAxis(axis, 0, d-1))
+		res = out
+	}
+	return res, nil
+}
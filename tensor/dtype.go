@@ -0,0 +1,228 @@
+package tensor
+
+import (
+	"fmt"
+	types "gograd/tensor/types"
+	"strconv"
+)
+
+// DType identifies the element type of a tensor at runtime.
+// It is used by AnyTensor to pick the result type of mixed-type ops.
+type DType uint8
+
+const (
+	InvalidDType DType = iota
+	Uint8
+	Uint16
+	Uint32
+	Uint64
+	Uint
+	Int8
+	Int16
+	Int32
+	Int64
+	Int
+	Float16
+	BFloat16
+	Float32
+	Float64
+)
+
+type dtype_kind uint8
+
+const (
+	kind_unsigned dtype_kind = iota
+	kind_signed
+	kind_float
+)
+
+type dtype_info struct {
+	name string
+	kind dtype_kind
+	bits int
+	// number of integers a float can represent exactly, in bits
+	mantissa int
+	// the largest finite float is below 2^(max_exp+1)
+	max_exp int
+}
+
+var dtype_infos = [...]dtype_info{
+	InvalidDType: {name: "invalid"},
+	Uint8:        {"uint8", kind_unsigned, 8, 0, 0},
+	Uint16:       {"uint16", kind_unsigned, 16, 0, 0},
+	Uint32:       {"uint32", kind_unsigned, 32, 0, 0},
+	Uint64:       {"uint64", kind_unsigned, 64, 0, 0},
+	Uint:         {"uint", kind_unsigned, strconv.IntSize, 0, 0},
+	Int8:         {"int8", kind_signed, 8, 0, 0},
+	Int16:        {"int16", kind_signed, 16, 0, 0},
+	Int32:        {"int32", kind_signed, 32, 0, 0},
+	Int64:        {"int64", kind_signed, 64, 0, 0},
+	Int:          {"int", kind_signed, strconv.IntSize, 0, 0},
+	Float16:      {"float16", kind_float, 16, 11, 15},
+	BFloat16:     {"bfloat16", kind_float, 16, 8, 127},
+	Float32:      {"float32", kind_float, 32, 24, 127},
+	Float64:      {"float64", kind_float, 64, 53, 1023},
+}
+
+func (dtype DType) info() dtype_info {
+	if int(dtype) >= len(dtype_infos) {
+		return dtype_infos[InvalidDType]
+	}
+	return dtype_infos[dtype]
+}
+
+func (dtype DType) String() string {
+	return dtype.info().name
+}
+
+func (dtype DType) IsFloat() bool {
+	return dtype != InvalidDType && dtype.info().kind == kind_float
+}
+
+// Returns the DType of T. Named types (e.g. type MyInt int) are not supported and give InvalidDType.
+func DTypeOf[T types.TensorType]() DType {
+	switch any(*new(T)).(type) {
+	case uint8:
+		return Uint8
+	case uint16:
+		return Uint16
+	case uint32:
+		return Uint32
+	case uint64:
+		return Uint64
+	case uint:
+		return Uint
+	case int8:
+		return Int8
+	case int16:
+		return Int16
+	case int32:
+		return Int32
+	case int64:
+		return Int64
+	case int:
+		return Int
+	case types.Float16:
+		return Float16
+	case types.BFloat16:
+		return BFloat16
+	case float32:
+		return Float32
+	case float64:
+		return Float64
+	}
+	return InvalidDType
+}
+
+// the type with more bits. For types of the same size the platform int/uint is preferred
+func wider(a, b DType) DType {
+	ia, ib := a.info(), b.info()
+	switch {
+	case ia.bits > ib.bits:
+		return a
+	case ib.bits > ia.bits:
+		return b
+	case a == Int || a == Uint:
+		return a
+	}
+	return b
+}
+
+// the float of integer with float ops. 16-bit floats are widened to float32
+// if the integer overflows them, no float holds all 64-bit integers
+func int_with_float(i, f DType) (DType, error) {
+	ii := i.info()
+	if ii.bits == 64 {
+		return InvalidDType, fmt.Errorf(
+			"no float holds every %v, convert one of %v and %v explicitly", i, i, f)
+	}
+	value_bits := ii.bits
+	if ii.kind == kind_signed {
+		value_bits--
+	}
+	if value_bits > f.info().max_exp {
+		return Float32, nil
+	}
+	return f, nil
+}
+
+func signed_of_size(bits int) DType {
+	switch bits {
+	case 8:
+		return Int8
+	case 16:
+		return Int16
+	case 32:
+		return Int32
+	}
+	return Int64
+}
+
+// Returns the dtype both operands of a binary op are converted to.
+//
+// Rules:
+//   - floats: the wider float, float16 with bfloat16 gives float32
+//   - integer with float: the float, e.g. int32 with float32 gives float32.
+//     16-bit floats are widened to float32 if the integer overflows them (int32 with float16).
+//     64-bit integers (int64, uint64, int, uint) with a float are an error, no float holds them.
+//   - integers of the same signedness: the wider one
+//   - signed with unsigned: a signed type that holds both, e.g. uint8 with int8 gives int16.
+//     uint64 (and uint) with any signed type has no such type and is an error.
+func PromoteTypes(a, b DType) (DType, error) {
+	if a == InvalidDType || b == InvalidDType {
+		return InvalidDType, fmt.Errorf("cannot promote %v and %v", a, b)
+	}
+	if a == b {
+		return a, nil
+	}
+	ia, ib := a.info(), b.info()
+	switch {
+	case ia.kind == kind_float && ib.kind == kind_float:
+		if ia.bits == ib.bits {
+			// float16 and bfloat16 don't hold each other
+			return Float32, nil
+		}
+		return wider(a, b), nil
+	case ia.kind == kind_float:
+		return int_with_float(b, a)
+	case ib.kind == kind_float:
+		return int_with_float(a, b)
+	case ia.kind == ib.kind:
+		return wider(a, b), nil
+	}
+	signed, unsigned := a, b
+	if ia.kind == kind_unsigned {
+		signed, unsigned = b, a
+	}
+	if signed.info().bits > unsigned.info().bits {
+		return signed, nil
+	}
+	if unsigned.info().bits < 64 {
+		return signed_of_size(unsigned.info().bits * 2), nil
+	}
+	return InvalidDType, fmt.Errorf(
+		"no dtype holds both %v and %v, convert one of them explicitly", a, b)
+}
+
+// Reports whether every value of from can be converted to to without losing precision.
+func CanCast(from, to DType) bool {
+	if from == InvalidDType || to == InvalidDType {
+		return false
+	}
+	if from == to {
+		return true
+	}
+	f, t := from.info(), to.info()
+	switch {
+	case f.kind == kind_float:
+		return t.kind == kind_float && t.bits > f.bits && t.mantissa >= f.mantissa
+	case t.kind == kind_float:
+		return f.bits <= t.mantissa
+	case f.kind == t.kind:
+		return t.bits >= f.bits
+	case f.kind == kind_unsigned:
+		return t.bits > f.bits
+	}
+	// signed to unsigned
+	return false
+}
@@ -0,0 +1,460 @@
+package tensor
+
+import (
+	"errors"
+	"fmt"
+	types "gograd/tensor/types"
+	"slices"
+	"strings"
+)
+
+// labels of the dims covered by ellipsis. They are out of the letters range, so they never clash with subscripts
+const ellipsis_label rune = 0x10000
+
+// parsed einsum subscripts. Ellipsis is expanded to the ellipsis labels
+type einsumSpec struct {
+	inputs [][]rune
+	output []rune
+}
+
+func is_subscript(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+// parses the term of subscripts like "ij" or "...ij". Returns labels and the number of ellipsis dims
+func parse_einsum_term(term string, ndims int) ([]rune, int, error) {
+	before, after, has_ellipsis := strings.Cut(term, "...")
+	if strings.Contains(after, "...") || strings.Contains(strings.ReplaceAll(term, "...", ""), ".") {
+		return nil, 0, fmt.Errorf("invalid ellipsis in einsum term '%v'", term)
+	}
+	labels := []rune(before + after)
+	for _, r := range labels {
+		if !is_subscript(r) {
+			return nil, 0, fmt.Errorf("invalid subscript '%c' in einsum term '%v'", r, term)
+		}
+	}
+	if ndims < 0 {
+		// output term
+		return labels, 0, nil
+	}
+	n_ellipsis := ndims - len(labels)
+	if n_ellipsis < 0 || (!has_ellipsis && n_ellipsis != 0) {
+		return nil, 0, fmt.Errorf("einsum term '%v' does not match tensor with %v dims", term, ndims)
+	}
+	if !has_ellipsis {
+		return labels, 0, nil
+	}
+	ellipsis := make([]rune, n_ellipsis)
+	out := append(append([]rune(before), ellipsis...), []rune(after)...)
+	return out, n_ellipsis, nil
+}
+
+// parses subscripts like "bij,bjk->bik". Output is inferred if it's not set:
+// it contains ellipsis dims and labels which appear only once, in alphabetical order
+func parse_einsum(subscripts string, ndims []int) (*einsumSpec, error) {
+	subscripts = strings.ReplaceAll(subscripts, " ", "")
+	inputs_str, output_str, explicit := strings.Cut(subscripts, "->")
+	terms := strings.Split(inputs_str, ",")
+	if len(terms) != len(ndims) {
+		return nil, fmt.Errorf("einsum subscripts have %v terms, but %v operands are given", len(terms), len(ndims))
+	}
+	spec := &einsumSpec{inputs: make([][]rune, len(terms))}
+	// ellipsis dims are aligned to the right, like in broadcasting
+	max_ellipsis := 0
+	counts := make([]int, len(terms))
+	for i, term := range terms {
+		labels, n_ellipsis, err := parse_einsum_term(term, ndims[i])
+		if err != nil {
+			return nil, err
+		}
+		spec.inputs[i] = labels
+		counts[i] = n_ellipsis
+		max_ellipsis = max(max_ellipsis, n_ellipsis)
+	}
+	for i, term := range terms {
+		start := strings.Index(term, "...")
+		for j := 0; j < counts[i]; j++ {
+			spec.inputs[i][start+j] = ellipsis_label + rune(max_ellipsis-counts[i]+j)
+		}
+	}
+	ellipsis := make([]rune, max_ellipsis)
+	for j := range ellipsis {
+		ellipsis[j] = ellipsis_label + rune(j)
+	}
+
+	if !explicit {
+		occurrences := make(map[rune]int)
+		for _, labels := range spec.inputs {
+			for _, label := range labels {
+				occurrences[label]++
+			}
+		}
+		spec.output = ellipsis
+		var once []rune
+		for label, count := range occurrences {
+			if count == 1 && label < ellipsis_label {
+				once = append(once, label)
+			}
+		}
+		slices.Sort(once)
+		spec.output = append(spec.output, once...)
+		return spec, nil
+	}
+
+	labels, _, err := parse_einsum_term(output_str, -1)
+	if err != nil {
+		return nil, err
+	}
+	before, after, has_ellipsis := strings.Cut(output_str, "...")
+	if has_ellipsis {
+		labels = append(append([]rune(before), ellipsis...), []rune(after)...)
+	}
+	for i, label := range labels {
+		if slices.Contains(labels[:i], label) {
+			return nil, fmt.Errorf("output subscript '%c' is repeated", label)
+		}
+		found := false
+		for _, input := range spec.inputs {
+			found = found || slices.Contains(input, label)
+		}
+		if !found {
+			return nil, fmt.Errorf("output subscript '%c' does not appear in the inputs", label)
+		}
+	}
+	spec.output = labels
+	return spec, nil
+}
+
+// tensor with labeled dims. Tensor without labels is scalar-like with shape (1)
+type labeledTensor[T types.TensorType] struct {
+	tensor *Tensor[T]
+	labels []rune
+}
+
+// sizes of all labels. Ellipsis dims can be broadcasted, e.g. 1 and N gives N
+func einsum_sizes[T types.TensorType](spec *einsumSpec, operands []*Tensor[T]) (map[rune]types.Dim, error) {
+	sizes := make(map[rune]types.Dim)
+	for i, labels := range spec.inputs {
+		for j, label := range labels {
+			dim := operands[i].shape[j]
+			size, ok := sizes[label]
+			switch {
+			case !ok || size == dim:
+				sizes[label] = dim
+			case label >= ellipsis_label && (size == 1 || dim == 1):
+				sizes[label] = max(size, dim)
+			default:
+				return nil, fmt.Errorf("size of subscript '%c' does not match: %v and %v", label, size, dim)
+			}
+		}
+	}
+	return sizes, nil
+}
+
+// view with unique labels. Repeated labels are replaced by the diagonal: "ii" => "i".
+// Broadcasted ellipsis dims get zero strides
+func (op labeledTensor[T]) diagonal(sizes map[rune]types.Dim) labeledTensor[T] {
+	if len(op.labels) == 0 {
+		return op
+	}
+	t := op.tensor
+	var labels []rune
+	var shape types.Shape
+	var strides []int
+	for i, label := range op.labels {
+		stride := t.strides[i]
+		if t.shape[i] != sizes[label] {
+			// broadcasted one-sized dim
+			stride = 0
+		}
+		if j := slices.Index(labels, label); j >= 0 {
+			strides[j] += stride
+			continue
+		}
+		labels = append(labels, label)
+		shape = append(shape, sizes[label])
+		strides = append(strides, stride)
+	}
+	if len(labels) == len(op.labels) && shape.Equals(t.shape) {
+		return op
+	}
+	return labeledTensor[T]{t.makeView(t.offset, shape, strides), labels}
+}
+
+// sums dims with labels which are not kept
+func (op labeledTensor[T]) sumOut(keep func(rune) bool) labeledTensor[T] {
+	var axes []int
+	var labels []rune
+	for i, label := range op.labels {
+		if keep(label) {
+			labels = append(labels, label)
+		} else {
+			axes = append(axes, i)
+		}
+	}
+	if len(axes) == 0 {
+		return op
+	}
+	return labeledTensor[T]{op.tensor.Sum(false, axes...), labels}
+}
+
+// transposed view with the given order of labels, reshaped to the shape
+func (op labeledTensor[T]) arrange(labels []rune, shape ...types.Dim) *Tensor[T] {
+	view := op.tensor.View()
+	if len(op.labels) > 1 {
+		axes := make([]uint, len(labels))
+		for i, label := range labels {
+			axes[i] = uint(slices.Index(op.labels, label))
+		}
+		view = view.T(axes...)
+	}
+	return view.Reshape(shape...)
+}
+
+// view with the given labels. Missing labels are broadcasted with zero strides
+func (op labeledTensor[T]) expand(labels []rune, sizes map[rune]types.Dim) *Tensor[T] {
+	shape := make(types.Shape, len(labels))
+	strides := make([]int, len(labels))
+	for i, label := range labels {
+		shape[i] = sizes[label]
+		if j := slices.Index(op.labels, label); j >= 0 {
+			strides[i] = op.tensor.strides[j]
+		}
+	}
+	return op.tensor.makeView(op.tensor.offset, shape, strides)
+}
+
+func labels_size(labels []rune, sizes map[rune]types.Dim) types.Dim {
+	var size types.Dim = 1
+	for _, label := range labels {
+		size *= sizes[label]
+	}
+	return size
+}
+
+func labels_shape(labels []rune, sizes map[rune]types.Dim) types.Shape {
+	shape := make(types.Shape, len(labels))
+	for i, label := range labels {
+		shape[i] = sizes[label]
+	}
+	return shape
+}
+
+// contracts two tensors over the shared labels which are not needed later.
+// Tensors are arranged as (batch, a_only, contracted) @ (batch, contracted, b_only), so it is a batched MatMul
+func contract_pair[T types.TensorType](
+	a, b labeledTensor[T],
+	needed func(rune) bool,
+	sizes map[rune]types.Dim,
+) labeledTensor[T] {
+	var batch, a_only, b_only, contracted []rune
+	for _, label := range a.labels {
+		switch {
+		case !slices.Contains(b.labels, label):
+			a_only = append(a_only, label)
+		case needed(label):
+			batch = append(batch, label)
+		default:
+			contracted = append(contracted, label)
+		}
+	}
+	for _, label := range b.labels {
+		if !slices.Contains(a.labels, label) {
+			b_only = append(b_only, label)
+		}
+	}
+	nb, m, k, n := labels_size(batch, sizes), labels_size(a_only, sizes), labels_size(contracted, sizes), labels_size(b_only, sizes)
+	a_mat := a.arrange(slices.Concat(batch, a_only, contracted), nb, m, k)
+	b_mat := b.arrange(slices.Concat(batch, contracted, b_only), nb, k, n)
+	var out *Tensor[T]
+	if len(contracted) == 0 {
+		// outer or elementwise product, (nb,m,1) * (nb,1,n)
+		out = a_mat.Mul(b_mat)
+	} else {
+		out = a_mat.MatMul(b_mat)
+	}
+	labels := slices.Concat(batch, a_only, b_only)
+	return labeledTensor[T]{out.Reshape(labels_shape(labels, sizes)...), labels}
+}
+
+// evaluates einsum for the parsed operands. Result has the output labels
+func einsum_labeled[T types.TensorType](
+	ops []labeledTensor[T],
+	output []rune,
+	sizes map[rune]types.Dim,
+) (*Tensor[T], error) {
+	// label is needed if it is in the output or in the operands which are not processed yet
+	needed_after := func(i int) func(rune) bool {
+		return func(label rune) bool {
+			if slices.Contains(output, label) {
+				return true
+			}
+			for _, op := range ops[i+1:] {
+				if slices.Contains(op.labels, label) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	for i := range ops {
+		ops[i] = ops[i].diagonal(sizes)
+	}
+	// labels used by a single operand only are summed before the contraction
+	used_by_others := func(i int) func(rune) bool {
+		return func(label rune) bool {
+			if slices.Contains(output, label) {
+				return true
+			}
+			for j, op := range ops {
+				if j != i && slices.Contains(op.labels, label) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	for i := range ops {
+		ops[i] = ops[i].sumOut(used_by_others(i))
+	}
+
+	result := ops[0]
+	for i := 1; i < len(ops); i++ {
+		result = contract_pair(result, ops[i], needed_after(i), sizes)
+		if result.tensor.Err != nil {
+			return nil, result.tensor.Err
+		}
+	}
+	// single operand
+	result = result.sumOut(func(label rune) bool { return slices.Contains(output, label) })
+	out := result.arrange(output, labels_shape(output, sizes)...)
+	if out.Err != nil {
+		return nil, out.Err
+	}
+	if sharesBuffer(out.data_buff, ops[0].tensor.data_buff) {
+		// transpose or diagonal of a single operand is a view
+		out = out.Clone()
+	}
+	return out, nil
+}
+
+func prepare_einsum[T types.TensorType](subscripts string, operands []*Tensor[T]) (*einsumSpec, map[rune]types.Dim, error) {
+	if len(operands) == 0 {
+		return nil, nil, errors.New("at least 1 operand is required")
+	}
+	ndims := make([]int, len(operands))
+	for i, op := range operands {
+		if op.Err != nil {
+			return nil, nil, op.Err
+		}
+		ndims[i] = len(op.shape)
+	}
+	spec, err := parse_einsum(subscripts, ndims)
+	if err != nil {
+		return nil, nil, err
+	}
+	sizes, err := einsum_sizes(spec, operands)
+	if err != nil {
+		return nil, nil, err
+	}
+	return spec, sizes, nil
+}
+
+// Evaluates the Einstein summation convention on the operands.
+// Subscripts are letters naming the dims of every operand, separated by commas, and optional output after "->".
+// Repeated subscripts in one operand take the diagonal, subscripts which are not in the output are summed.
+// Ellipsis "..." stands for the broadcasted batch dims.
+// If the output is not set, it contains ellipsis dims and subscripts which appear once, in alphabetical order.
+// Contractions are computed with the matmul kernels.
+//
+// Example:
+// Einsum("ij,jk->ik", a, b)      // matmul
+// Einsum("bij,bjk->bik", a, b)   // batched matmul
+// Einsum("...ij,...jk", a, b)    // matmul with broadcasted batch dims
+// Einsum("ij->ji", a)            // transpose
+// Einsum("ii", a)                // trace
+// Einsum("i,j->ij", a, b)        // outer product
+func Einsum[T types.TensorType](subscripts string, operands ...*Tensor[T]) (*Tensor[T], error) {
+	if is_half[T]() {
+		return half_call(operands, func(ops []*Tensor[float32]) (*Tensor[float32], error) {
+			return Einsum(subscripts, ops...)
+		})
+	}
+	spec, sizes, err := prepare_einsum(subscripts, operands)
+	if err != nil {
+		return nil, err
+	}
+	ops := make([]labeledTensor[T], len(operands))
+	for i, op := range operands {
+		ops[i] = labeledTensor[T]{op, spec.inputs[i]}
+	}
+	return einsum_labeled(ops, spec.output, sizes)
+}
+
+// Gradient of the Einsum result w.r.t. the operand with the index. grad has the shape of the Einsum result.
+// It is the Einsum of grad and the other operands, broadcasted to the dims which are summed in the forward pass.
+// Repeated subscripts get the gradient on the diagonal only.
+func EinsumGrad[T types.TensorType](subscripts string, grad *Tensor[T], operands []*Tensor[T], index int) (*Tensor[T], error) {
+	if is_half[T]() {
+		return half_call(append([]*Tensor[T]{grad}, operands...), func(ops []*Tensor[float32]) (*Tensor[float32], error) {
+			return EinsumGrad(subscripts, ops[0], ops[1:], index)
+		})
+	}
+	spec, sizes, err := prepare_einsum(subscripts, operands)
+	if err != nil {
+		return nil, err
+	}
+	if grad.Err != nil {
+		return nil, grad.Err
+	}
+	target := operands[index]
+	target_labels := spec.inputs[index]
+	ops := []labeledTensor[T]{{grad, spec.output}}
+	for i, op := range operands {
+		if i != index {
+			ops = append(ops, labeledTensor[T]{op, spec.inputs[i]})
+		}
+	}
+	// unique labels of the operand and the ones which can be computed from the grad and the other operands
+	var unique, available []rune
+	for _, label := range target_labels {
+		if slices.Contains(unique, label) {
+			continue
+		}
+		unique = append(unique, label)
+		for _, op := range ops {
+			if slices.Contains(op.labels, label) {
+				available = append(available, label)
+				break
+			}
+		}
+	}
+	g, err := einsum_labeled(ops, available, sizes)
+	if err != nil {
+		return nil, err
+	}
+	expanded := labeledTensor[T]{g, available}.expand(unique, sizes)
+
+	// sum the ellipsis dims which are broadcasted from one-sized dims of the operand
+	own_sizes := make(map[rune]types.Dim)
+	var axes []int
+	for i, label := range target_labels {
+		own_sizes[label] = target.shape[i]
+	}
+	for i, label := range unique {
+		if own_sizes[label] != sizes[label] {
+			axes = append(axes, i)
+		}
+	}
+	if len(axes) > 0 {
+		expanded = expanded.Sum(true, axes...)
+	}
+	if len(unique) == len(target_labels) {
+		return expanded.Reshape(target.shape...), nil
+	}
+	// put the gradient on the diagonal of zeros
+	out := Zeros[T](target.shape...)
+	diag := labeledTensor[T]{out, target_labels}.diagonal(own_sizes)
+	diag.tensor.copyFromContiguous(expanded.AsContiguous())
+	return out, nil
+}
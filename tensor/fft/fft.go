@@ -0,0 +1,381 @@
+// Discrete Fourier transforms of complex and real tensors along chosen axes.
+//
+// The transforms are computed in complex128. Lengths which are powers of two use the radix-2
+// Cooley-Tukey algorithm, other lengths use Bluestein's algorithm, so every size takes O(n log n).
+// Like numpy, forward transforms are not scaled and inverse transforms are scaled by 1/n.
+// Errors are returned through the Err field of the result tensors.
+package fft
+
+import (
+	"fmt"
+	"gograd/tensor"
+	"gograd/tensor/internal"
+	types "gograd/tensor/types"
+	"math"
+	"math/bits"
+	"math/cmplx"
+	"slices"
+	"sync"
+)
+
+// precomputed factors of the transform of length n
+type plan struct {
+	n int
+	// length of the radix-2 transform, n or the padded length for Bluestein's algorithm
+	m        int
+	twiddles []complex128
+	// Bluestein's chirp exp(-i*pi*k^2/n) and the transform of its padded conjugate
+	chirp     []complex128
+	chirp_fft []complex128
+}
+
+func is_pow2(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+func new_plan(n int) *plan {
+	p := &plan{n: n, m: n}
+	if !is_pow2(n) {
+		p.m = 1 << bits.Len(uint(2*n-2))
+	}
+	p.twiddles = make([]complex128, p.m/2)
+	for k := range p.twiddles {
+		p.twiddles[k] = cmplx.Rect(1, -2*math.Pi*float64(k)/float64(p.m))
+	}
+	if p.m == n {
+		return p
+	}
+	p.chirp = make([]complex128, n)
+	for k := range p.chirp {
+		// k^2 mod 2n keeps the angle small for large k
+		p.chirp[k] = cmplx.Rect(1, -math.Pi*float64(k*k%(2*n))/float64(n))
+	}
+	p.chirp_fft = make([]complex128, p.m)
+	p.chirp_fft[0] = cmplx.Conj(p.chirp[0])
+	for k := 1; k < n; k++ {
+		p.chirp_fft[k] = cmplx.Conj(p.chirp[k])
+		p.chirp_fft[p.m-k] = cmplx.Conj(p.chirp[k])
+	}
+	p.radix2(p.chirp_fft)
+	return p
+}
+
+// in-place forward transform of the length m
+func (p *plan) radix2(x []complex128) {
+	m := len(x)
+	if m < 2 {
+		return
+	}
+	shift := bits.UintSize - bits.Len(uint(m-1))
+	for i := range x {
+		j := int(bits.Reverse(uint(i)) >> shift)
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= m; size <<= 1 {
+		half, step := size/2, m/size
+		for start := 0; start < m; start += size {
+			for k := 0; k < half; k++ {
+				u, v := x[start+k], x[start+k+half]*p.twiddles[k*step]
+				x[start+k], x[start+k+half] = u+v, u-v
+			}
+		}
+	}
+}
+
+// in-place transform of the lane with length n. scratch must have length m
+func (p *plan) transform(x []complex128, inverse bool, scratch []complex128) {
+	if inverse {
+		// ifft(x) = conj(fft(conj(x))) / n
+		for i, v := range x {
+			x[i] = cmplx.Conj(v)
+		}
+	}
+	if p.chirp == nil {
+		p.radix2(x)
+	} else {
+		// the transform is the convolution of the chirped input with the conjugate chirp
+		clear(scratch)
+		for k, v := range x {
+			scratch[k] = v * p.chirp[k]
+		}
+		p.radix2(scratch)
+		for k, v := range p.chirp_fft {
+			scratch[k] = cmplx.Conj(scratch[k] * v)
+		}
+		p.radix2(scratch)
+		scale := complex(1/float64(p.m), 0)
+		for k := range x {
+			x[k] = cmplx.Conj(scratch[k]) * scale * p.chirp[k]
+		}
+	}
+	if inverse {
+		scale := complex(1/float64(p.n), 0)
+		for i, v := range x {
+			x[i] = cmplx.Conj(v) * scale
+		}
+	}
+}
+
+func normalize_axis(shape types.Shape, axis int) (int, error) {
+	ndim := len(shape)
+	if axis < -ndim || axis >= ndim {
+		return 0, fmt.Errorf("axis %v is out of range for shape %v", axis, shape)
+	}
+	if axis < 0 {
+		axis += ndim
+	}
+	return axis, nil
+}
+
+// transforms every lane along the axis. Lanes are cropped or zero-padded to n points, n < 1 keeps the length
+func transform_axis(
+	data []complex128, shape types.Shape, axis, n int, inverse bool,
+) ([]complex128, types.Shape, error) {
+	axis, err := normalize_axis(shape, axis)
+	if err != nil {
+		return nil, nil, err
+	}
+	dim := int(shape[axis])
+	if n < 1 {
+		n = dim
+	}
+	if n < 1 {
+		return nil, nil, fmt.Errorf("invalid number of points %v along axis %v of shape %v", n, axis, shape)
+	}
+	outer, inner := 1, 1
+	for i, d := range shape {
+		if i < axis {
+			outer *= int(d)
+		} else if i > axis {
+			inner *= int(d)
+		}
+	}
+	out_shape := slices.Clone(shape)
+	out_shape[axis] = types.Dim(n)
+	out := make([]complex128, outer*n*inner)
+	p := new_plan(n)
+	internal.Parallel(outer*inner, func(start, end int, _, _, _ []float64, _ *sync.Mutex) {
+		lane := make([]complex128, n)
+		scratch := make([]complex128, p.m)
+		for j := start; j < end; j++ {
+			o, i := j/inner, j%inner
+			clear(lane)
+			for d := 0; d < min(dim, n); d++ {
+				lane[d] = data[(o*dim+d)*inner+i]
+			}
+			p.transform(lane, inverse, scratch)
+			for d, v := range lane {
+				out[(o*n+d)*inner+i] = v
+			}
+		}
+	}, nil, nil, nil)
+	return out, out_shape, nil
+}
+
+func to_complex128[C types.Complex](data []C) []complex128 {
+	out := make([]complex128, len(data))
+	for i, v := range data {
+		out[i] = complex128(v)
+	}
+	return out
+}
+
+func from_complex128[C types.Complex](data []complex128, shape types.Shape) *tensor.ComplexTensor[C] {
+	out := make([]C, len(data))
+	for i, v := range data {
+		out[i] = C(v)
+	}
+	return tensor.CreateComplexTensorNoCopy(out, shape)
+}
+
+func errComplex[C types.Complex](err error) *tensor.ComplexTensor[C] {
+	return &tensor.ComplexTensor[C]{Err: err}
+}
+
+func complex_transform[C types.Complex](
+	x *tensor.ComplexTensor[C], n int, axes []int, inverse bool,
+) *tensor.ComplexTensor[C] {
+	if x.Err != nil {
+		return x
+	}
+	data, shape := to_complex128(x.Data()), x.Shape()
+	for _, axis := range axes {
+		var err error
+		if data, shape, err = transform_axis(data, shape, axis, n, inverse); err != nil {
+			return errComplex[C](err)
+		}
+	}
+	return from_complex128[C](data, shape)
+}
+
+// checks the axes of 2D transforms, the last two axes are used by default
+func axes_2d(axes []int) ([]int, error) {
+	if len(axes) == 0 {
+		return []int{-2, -1}, nil
+	}
+	if len(axes) != 2 {
+		return nil, fmt.Errorf("2D transforms expect 2 axes, got %v", axes)
+	}
+	return axes, nil
+}
+
+// Transform of the complex tensor along the axis.
+// The input is cropped or zero-padded to n points, n < 1 keeps the length of the axis.
+//
+// Example:
+// x = [1, 0, 0, 0]
+// FFT(x, 0, -1) => [1, 1, 1, 1]
+func FFT[C types.Complex](x *tensor.ComplexTensor[C], n, axis int) *tensor.ComplexTensor[C] {
+	return complex_transform(x, n, []int{axis}, false)
+}
+
+// Inverse of FFT, the result is scaled by 1/n
+func IFFT[C types.Complex](x *tensor.ComplexTensor[C], n, axis int) *tensor.ComplexTensor[C] {
+	return complex_transform(x, n, []int{axis}, true)
+}
+
+// 2D transform along the axes, the last two axes by default
+func FFT2[C types.Complex](x *tensor.ComplexTensor[C], axes ...int) *tensor.ComplexTensor[C] {
+	axes, err := axes_2d(axes)
+	if err != nil {
+		return errComplex[C](err)
+	}
+	return complex_transform(x, 0, axes, false)
+}
+
+// Inverse of FFT2
+func IFFT2[C types.Complex](x *tensor.ComplexTensor[C], axes ...int) *tensor.ComplexTensor[C] {
+	axes, err := axes_2d(axes)
+	if err != nil {
+		return errComplex[C](err)
+	}
+	return complex_transform(x, 0, axes, true)
+}
+
+// Transform of the real tensor along the axis. Only n/2+1 non-negative frequencies are returned,
+// the others are their complex conjugates. n < 1 keeps the length of the axis.
+//
+// Example:
+// RFFT[complex64](x, 0, -1) for float32 x
+func RFFT[C types.Complex, F types.Float](x *tensor.Tensor[F], n, axis int) *tensor.ComplexTensor[C] {
+	if x.Err != nil {
+		return errComplex[C](x.Err)
+	}
+	shape := x.Shape()
+	data := make([]complex128, 0, x.Size())
+	for _, v := range x.Data() {
+		data = append(data, complex(float64(v), 0))
+	}
+	out, out_shape, err := transform_axis(data, shape, axis, n, false)
+	if err != nil {
+		return errComplex[C](err)
+	}
+	axis, _ = normalize_axis(shape, axis)
+	return from_complex128[C](crop_axis(out, out_shape, axis, int(out_shape[axis])/2+1))
+}
+
+// keeps the first k elements along the axis
+func crop_axis(data []complex128, shape types.Shape, axis, k int) ([]complex128, types.Shape) {
+	outer, dim, inner := 1, int(shape[axis]), 1
+	for i, d := range shape {
+		if i < axis {
+			outer *= int(d)
+		} else if i > axis {
+			inner *= int(d)
+		}
+	}
+	out := make([]complex128, 0, outer*k*inner)
+	for o := 0; o < outer; o++ {
+		out = append(out, data[o*dim*inner:(o*dim+k)*inner]...)
+	}
+	out_shape := slices.Clone(shape)
+	out_shape[axis] = types.Dim(k)
+	return out, out_shape
+}
+
+// Inverse of RFFT. n is the length of the real output along the axis, n < 1 gives 2*(m-1)
+// where m is the length of the input axis. The input is cropped or zero-padded to n/2+1 points.
+//
+// Example:
+// IRFFT[float32](RFFT[complex64](x, 0, -1), x.Shape()[x.Dims()-1], -1) => x
+func IRFFT[F types.Float, C types.Complex](x *tensor.ComplexTensor[C], n, axis int) *tensor.Tensor[F] {
+	if x.Err != nil {
+		return &tensor.Tensor[F]{Err: x.Err}
+	}
+	data, shape, err := hermitian_axis(to_complex128(x.Data()), x.Shape(), axis, n)
+	if err != nil {
+		return &tensor.Tensor[F]{Err: err}
+	}
+	out, out_shape, err := transform_axis(data, shape, axis, 0, true)
+	if err != nil {
+		return &tensor.Tensor[F]{Err: err}
+	}
+	return real_part[F](out, out_shape)
+}
+
+// restores the full spectrum of n points from the non-negative frequencies of a real signal
+func hermitian_axis(data []complex128, shape types.Shape, axis, n int) ([]complex128, types.Shape, error) {
+	axis, err := normalize_axis(shape, axis)
+	if err != nil {
+		return nil, nil, err
+	}
+	dim := int(shape[axis])
+	if n < 1 {
+		n = 2 * (dim - 1)
+	}
+	if n < 1 {
+		return nil, nil, fmt.Errorf("invalid number of points %v along axis %v of shape %v", n, axis, shape)
+	}
+	outer, inner := 1, 1
+	for i, d := range shape {
+		if i < axis {
+			outer *= int(d)
+		} else if i > axis {
+			inner *= int(d)
+		}
+	}
+	half := min(dim, n/2+1)
+	out := make([]complex128, outer*n*inner)
+	for o := 0; o < outer; o++ {
+		for i := 0; i < inner; i++ {
+			for k := 0; k < half; k++ {
+				v := data[(o*dim+k)*inner+i]
+				out[(o*n+k)*inner+i] = v
+				if k > 0 && n-k >= half {
+					out[(o*n+n-k)*inner+i] = cmplx.Conj(v)
+				}
+			}
+		}
+	}
+	out_shape := slices.Clone(shape)
+	out_shape[axis] = types.Dim(n)
+	return out, out_shape, nil
+}
+
+func real_part[F types.Float](data []complex128, shape types.Shape) *tensor.Tensor[F] {
+	out := make([]F, len(data))
+	for i, v := range data {
+		out[i] = F(real(v))
+	}
+	return tensor.CreateTensorNoCopy(out, shape)
+}
+
+// 2D transform of the real tensor. The last of the axes keeps only the non-negative frequencies.
+func RFFT2[C types.Complex, F types.Float](x *tensor.Tensor[F], axes ...int) *tensor.ComplexTensor[C] {
+	axes, err := axes_2d(axes)
+	if err != nil {
+		return errComplex[C](err)
+	}
+	return complex_transform(RFFT[C](x, 0, axes[1]), 0, axes[:1], false)
+}
+
+// Inverse of RFFT2. n is the length of the real output along the last of the axes, see IRFFT.
+func IRFFT2[F types.Float, C types.Complex](x *tensor.ComplexTensor[C], n int, axes ...int) *tensor.Tensor[F] {
+	axes, err := axes_2d(axes)
+	if err != nil {
+		return &tensor.Tensor[F]{Err: err}
+	}
+	return IRFFT[F](complex_transform(x, 0, axes[:1], true), n, axes[1])
+}
@@ -0,0 +1,272 @@
+package tensor
+
+import (
+	"fmt"
+	types "gograd/tensor/types"
+)
+
+// resolves the index value along the dim. Negative values are counted from the end
+func resolve_index(value, dim int) (int, error) {
+	if value < -dim || value >= dim {
+		return 0, fmt.Errorf("index %v is out of bounds for dim %v", value, dim)
+	}
+	if value < 0 {
+		value += dim
+	}
+	return value, nil
+}
+
+// positions in the tensor's buffer for every element of the index.
+// The position is taken from the index along the axis and from the element's own coordinates along other dims.
+// Index must have the same number of dims and must not be larger than the tensor along other dims
+func (tensor *Tensor[T]) along_axis(axis int, index *Tensor[int]) (int, []int, error) {
+	if tensor.Err != nil {
+		return 0, nil, tensor.Err
+	}
+	if index.Err != nil {
+		return 0, nil, index.Err
+	}
+	axes, err := normalize_axes(tensor.shape, []int{axis})
+	if err != nil {
+		return 0, nil, err
+	}
+	axis = axes[0]
+	if len(index.shape) != len(tensor.shape) {
+		return 0, nil, fmt.Errorf("index must have %v dims, got shape %v", len(tensor.shape), index.shape)
+	}
+	for d, dim := range index.shape {
+		if d != axis && dim > tensor.shape[d] {
+			return 0, nil, fmt.Errorf("index with shape %v is larger than tensor with shape %v along axis %v",
+				index.shape, tensor.shape, d)
+		}
+	}
+	values := index.AsContiguous().data()
+	positions := make([]int, len(values))
+	dim := int(tensor.shape[axis])
+	it := CreateIterator(len(values), index.shape)
+	for it.Iterate() {
+		i := it.Index()
+		idx := it.Next()
+		value, err := resolve_index(values[i], dim)
+		if err != nil {
+			return 0, nil, err
+		}
+		pos := tensor.offset
+		for d, coord := range idx {
+			if d == axis {
+				coord = value
+			}
+			pos += coord * tensor.strides[d]
+		}
+		positions[i] = pos
+	}
+	return axis, positions, nil
+}
+
+// Gathers values along the axis. Result has the shape of the index.
+// For axis 1: out[i][j][k] = tensor[i][index[i][j][k]][k]
+//
+// Example:
+// a = [
+// [1,2,3],
+// [4,5,6]]
+// a.Gather(1, [[2],[0]]) => [[3],[4]]
+func (tensor *Tensor[T]) Gather(axis int, index *Tensor[int]) *Tensor[T] {
+	_, positions, err := tensor.along_axis(axis, index)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	out := CreateEmptyTensor[T](index.shape...)
+	data, out_data := tensor.data(), out.data()
+	for i, pos := range positions {
+		out_data[i] = data[pos]
+	}
+	return out
+}
+
+// writes src values to the positions given by the index along the axis.
+// Result is a copy of the tensor unless out is set, out can be the tensor itself
+func (tensor *Tensor[T]) scatter(
+	axis int,
+	index *Tensor[int],
+	src *Tensor[T],
+	accumulate bool,
+	out *Tensor[T],
+) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if src.Err != nil {
+		return src
+	}
+	if index.Err != nil {
+		tensor.Err = index.Err
+		return tensor
+	}
+	if !src.shape.AreBroadcastable(index.shape) || !index.shape.BroadcastShapes(src.shape).Equals(index.shape) {
+		tensor.Err = fmt.Errorf("src with shape %v cannot be broadcasted to the index shape %v", src.shape, index.shape)
+		return tensor
+	}
+	if accumulate && is_half[T]() {
+		res := half_to_float32(tensor).scatter(axis, index, half_to_float32(src), true, nil)
+		return half_result(tensor, res, out)
+	}
+	result, err := PrepareOutTensor(out, tensor.shape)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	if !sharesBuffer(result.data_buff, tensor.data_buff) {
+		result.copyFromContiguous(tensor.AsContiguous())
+	}
+	_, positions, err := result.along_axis(axis, index)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	src_data := src.Broadcast(index.shape...).AsContiguous().data()
+	data := result.data()
+	for i, pos := range positions {
+		if accumulate {
+			data[pos] += src_data[i]
+		} else {
+			data[pos] = src_data[i]
+		}
+	}
+	return result
+}
+
+// Writes src values to the positions given by the index along the axis, it is the reverse of Gather.
+// For axis 1: out[i][index[i][j][k]][k] = src[i][j][k]
+// src is broadcasted to the shape of the index, so it can be a scalar.
+// If the index has duplicates, the last value in the row-major order is written.
+// Result is a copy of the tensor. Pass the tensor as out to update it in-place.
+//
+// Example:
+// a = zeros(2,3)
+// a.Scatter(1, [[2],[0]], [[7],[8]]) => [[0,0,7],[8,0,0]]
+func (tensor *Tensor[T]) Scatter(axis int, index *Tensor[int], src *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	return tensor.scatter(axis, index, src, false, get_param(out...))
+}
+
+// Same as Scatter but adds src values. Values with duplicated indices are summed up.
+//
+// Example:
+// a = zeros(3)
+// a.ScatterAdd(0, [0,2,0], [1,2,3]) => [4,0,2]
+func (tensor *Tensor[T]) ScatterAdd(axis int, index *Tensor[int], src *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	return tensor.scatter(axis, index, src, true, get_param(out...))
+}
+
+// Takes values along the axis like numpy take_along_axis.
+// Works like Gather, but the index and the tensor are broadcasted along other dims.
+//
+// Example:
+// a = [
+// [10,30,20],
+// [60,40,50]]
+// a.TakeAlongAxis(a.ArgMax(1, true), 1) => [[30],[60]]
+func (tensor *Tensor[T]) TakeAlongAxis(index *Tensor[int], axis int) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if index.Err != nil {
+		tensor.Err = index.Err
+		return tensor
+	}
+	axes, err := normalize_axes(tensor.shape, []int{axis})
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	axis = axes[0]
+	if len(index.shape) != len(tensor.shape) {
+		tensor.Err = fmt.Errorf("index must have %v dims, got shape %v", len(tensor.shape), index.shape)
+		return tensor
+	}
+	tensor_shape := append(types.Shape(nil), tensor.shape...)
+	index_shape := append(types.Shape(nil), index.shape...)
+	for d := range tensor_shape {
+		a, b := tensor_shape[d], index_shape[d]
+		switch {
+		case d == axis || a == b:
+		case a == 1:
+			tensor_shape[d] = b
+		case b == 1:
+			index_shape[d] = a
+		default:
+			tensor.Err = fmt.Errorf("shapes %v and %v are not broadcastable along axis %v", tensor.shape, index.shape, d)
+			return tensor
+		}
+	}
+	return tensor.broadcastView(tensor_shape).Gather(axis, index.Broadcast(index_shape...))
+}
+
+// 1D index broadcasted to the shape with the dim of the axis replaced by the index size.
+// The index values go along the axis
+func expand_index(index *Tensor[int], shape types.Shape, axis int) (*Tensor[int], error) {
+	if index.Err != nil {
+		return nil, index.Err
+	}
+	if len(index.shape) != 1 {
+		return nil, fmt.Errorf("index must be 1D, got shape %v", index.shape)
+	}
+	shape = append(types.Shape(nil), shape...)
+	shape[axis] = index.shape[0]
+	index_shape := make(types.Shape, len(shape))
+	for d := range index_shape {
+		index_shape[d] = 1
+	}
+	index_shape[axis] = index.shape[0]
+	return index.View().Reshape(index_shape...).Broadcast(shape...), nil
+}
+
+// Selects the slices along the axis with the 1D index. Indices can repeat.
+//
+// Example:
+// a = [
+// [1,2],
+// [3,4],
+// [5,6]]
+// a.IndexSelect(0, [2,0,2]) => [[5,6],[1,2],[5,6]]
+func (tensor *Tensor[T]) IndexSelect(axis int, index *Tensor[int]) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	axes, err := normalize_axes(tensor.shape, []int{axis})
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	expanded, err := expand_index(index, tensor.shape, axes[0])
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	return tensor.Gather(axes[0], expanded)
+}
+
+// Adds src slices to the slices along the axis selected by the 1D index, it is the reverse of IndexSelect.
+// Values with duplicated indices are summed up.
+// Result is a copy of the tensor. Pass the tensor as out to update it in-place.
+//
+// Example:
+// a = zeros(3,2)
+// a.IndexAdd(0, [2,0,2], [[1,1],[2,2],[3,3]]) => [[2,2],[0,0],[4,4]]
+func (tensor *Tensor[T]) IndexAdd(axis int, index *Tensor[int], src *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	axes, err := normalize_axes(tensor.shape, []int{axis})
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	expanded, err := expand_index(index, tensor.shape, axes[0])
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	return tensor.scatter(axes[0], expanded, src, true, get_param(out...))
+}
@@ -0,0 +1,150 @@
+package tensor
+
+import (
+	"fmt"
+	"gograd/tensor/internal/device"
+	types "gograd/tensor/types"
+)
+
+// Float16 and BFloat16 tensors store the raw 16-bit values.
+// Arithmetic converts them to float32, computes and accumulates in float32
+// and rounds the result back to the 16-bit type.
+
+func is_half[T types.TensorType]() bool {
+	switch any(*new(T)).(type) {
+	case types.Float16, types.BFloat16:
+		return true
+	}
+	return false
+}
+
+func half_op_err[T types.TensorType]() error {
+	return fmt.Errorf("the op is not implemented for %T, convert the tensor with AsType first", *new(T))
+}
+
+// 1 of type T, the value of 16-bit floats is converted
+func one[T types.TensorType]() T {
+	switch any(*new(T)).(type) {
+	case types.Float16:
+		return T(types.Float16FromFloat32(1))
+	case types.BFloat16:
+		return T(types.BFloat16FromFloat32(1))
+	}
+	return 1
+}
+
+// float32 copy of a 16-bit float tensor
+func half_to_float32[T types.TensorType](tensor *Tensor[T]) *Tensor[float32] {
+	tensor = tensor.AsContiguous()
+	out := CreateEmptyTensor[float32](tensor.shape...)
+	switch data := any(tensor.data()).(type) {
+	case []types.Float16:
+		device.HalfToFloat32(AUTO_IMPL, data, out.data())
+	case []types.BFloat16:
+		device.HalfToFloat32(AUTO_IMPL, data, out.data())
+	}
+	return out
+}
+
+// rounds float32 values to the 16-bit float type T
+func float32_to_half[T types.TensorType](tensor *Tensor[float32]) *Tensor[T] {
+	tensor = tensor.AsContiguous()
+	out := CreateEmptyTensor[T](tensor.shape...)
+	switch out_data := any(out.data()).(type) {
+	case []types.Float16:
+		device.Float32ToHalf(AUTO_IMPL, tensor.data(), out_data)
+	case []types.BFloat16:
+		device.Float32ToHalf(AUTO_IMPL, tensor.data(), out_data)
+	}
+	return out
+}
+
+// AsType for 16-bit floats, the values go through float32
+func convert_half[OLD_T, NEW_T types.TensorType](tensor *Tensor[OLD_T]) *Tensor[NEW_T] {
+	var f32 *Tensor[float32]
+	if is_half[OLD_T]() {
+		f32 = half_to_float32(tensor)
+	} else {
+		f32 = AsType[OLD_T, float32](tensor)
+	}
+	if is_half[NEW_T]() {
+		return float32_to_half[NEW_T](f32)
+	}
+	if ret, ok := any(f32).(*Tensor[NEW_T]); ok {
+		return ret
+	}
+	return AsType[float32, NEW_T](f32)
+}
+
+// writes the float32 result of an op to a new tensor or to out
+func half_result[T types.TensorType](tensor *Tensor[T], res *Tensor[float32], out *Tensor[T]) *Tensor[T] {
+	if res.Err != nil {
+		tensor.Err = res.Err
+		return tensor
+	}
+	ret := float32_to_half[T](res)
+	if out == nil {
+		return ret
+	}
+	if out.Err != nil {
+		return out
+	}
+	if _, err := PrepareOutTensor(out, ret.shape); err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	out.copyFromContiguous(ret)
+	return out
+}
+
+func half_binary[T types.TensorType](
+	tensor, other *Tensor[T],
+	op func(*Tensor[float32], *Tensor[float32], ...*Tensor[float32]) *Tensor[float32],
+	out *Tensor[T],
+) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if other.Err != nil {
+		return other
+	}
+	return half_result(tensor, op(half_to_float32(tensor), half_to_float32(other)), out)
+}
+
+func half_unary[T types.TensorType](
+	tensor *Tensor[T],
+	op func(*Tensor[float32], ...*Tensor[float32]) *Tensor[float32],
+	out *Tensor[T],
+) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	return half_result(tensor, op(half_to_float32(tensor)), out)
+}
+
+// applies the reduction or any other op of a single tensor in float32
+func half_apply[T types.TensorType](tensor *Tensor[T], op func(*Tensor[float32]) *Tensor[float32]) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	return half_result(tensor, op(half_to_float32(tensor)), nil)
+}
+
+// applies the function of several tensors in float32, for the ops returning an error
+func half_call[T types.TensorType](
+	tensors []*Tensor[T],
+	op func([]*Tensor[float32]) (*Tensor[float32], error),
+) (*Tensor[T], error) {
+	converted := make([]*Tensor[float32], len(tensors))
+	for i, t := range tensors {
+		if t.Err != nil {
+			return nil, t.Err
+		}
+		converted[i] = half_to_float32(t)
+	}
+	res, err := op(converted)
+	if err != nil {
+		return nil, err
+	}
+	return float32_to_half[T](res), nil
+}
@@ -12,7 +12,7 @@ func (tensor *Tensor[T]) Id() (string, error) {
 	if tensor.Err != nil {
 		return "", tensor.Err
 	}
-	data := tensor.data()
+	data := tensor.AsContiguous().data()
 	shape := tensor.Shape()
 	dimord := tensor.dim_order
 
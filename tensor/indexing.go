@@ -4,12 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"gograd/tensor/internal"
+	types "gograd/tensor/types"
+	"slices"
 	"strconv"
 	"strings"
 )
 
+// returns position of the element in tensor's data. View offset is taken into account
 func (tensor *Tensor[T]) getFlatIndex(indices ...int) (int, error) {
-	flatIndex := 0
+	flatIndex := tensor.offset
 	for i, ind := range indices {
 		dim := int(tensor.shape[i])
 		// resolve negative indexes
@@ -46,7 +49,7 @@ func get_flat_idx_fast(strides []int, indices ...int) int {
 
 // faster Get() without bounds checking. Does not support negative indexing
 func (tensor *Tensor[T]) Get_fast(indices ...int) T {
-	return tensor.data()[get_flat_idx_fast(tensor.strides, indices...)]
+	return tensor.data()[tensor.offset+get_flat_idx_fast(tensor.strides, indices...)]
 }
 
 func (tensor *Tensor[T]) Get(indices ...int) (T, error) {
@@ -64,7 +67,9 @@ func (tensor *Tensor[T]) Get(indices ...int) (T, error) {
 	return tensor.data()[flatIndex], nil
 }
 
-// returns sub data for given indices.
+// returns sub tensor for given indices.
+//
+// The sub tensor is a view and shares memory with the original tensor
 func (tensor *Tensor[T]) Index(indices ...int) *Tensor[T] {
 	if tensor.Err != nil {
 		return tensor
@@ -81,50 +86,194 @@ func (tensor *Tensor[T]) Index(indices ...int) *Tensor[T] {
 	}
 
 	// index of the first elem in the sub tensor
-	if n_indices == n_dims {
-		flatIndex, err := tensor.getFlatIndex(indices...)
-		if err != nil {
-			tensor.Err = err
-			return tensor
-		}
-		return Scalar[T](tensor.data()[flatIndex])
-	}
-
-	tensor = tensor.AsContiguous()
-	innerShape := tensor.shape[n_indices:]
 	flatIndex, err := tensor.getFlatIndex(indices...)
 	if err != nil {
 		tensor.Err = err
 		return tensor
 	}
-	// if data layout is contiguous we can just take a slice start:end from data
-	endFlatIndex := flatIndex + tensor.strides[n_indices-1]
-	subData := tensor.data()[flatIndex:endFlatIndex]
-	return CreateTensor(subData, innerShape)
+	if n_indices == n_dims {
+		return scalar(tensor.data()[flatIndex])
+	}
+	return tensor.makeView(flatIndex, tensor.shape[n_indices:], tensor.strides[n_indices:])
 }
 
 // IdxRange is used to create a slice along specific axis.
 // Setting start & end is needed to apply slicing boundaries.
 
+type idxKind uint8
+
+const (
+	idxSingle   idxKind = iota // single index, removes the dim
+	idxSlice                   // start:end:step range, keeps the dim
+	idxEllipsis                // '...', expands to as many full axes as needed
+	idxNewAxis                 // 'None', inserts a new dim of size 1
+)
+
 type idxRange struct {
-	start int
-	end   int
+	start     int
+	end       int
+	step      int
+	has_start bool
+	has_end   bool
+	kind      idxKind
 }
 
 // Idx() is an utility function is used for taking a specific index
 func I(val int) *idxRange {
-	return &idxRange{val, val}
+	return &idxRange{start: val, end: val, step: 1, has_start: true, has_end: true, kind: idxSingle}
 }
 
 // Axis() is used for taking entire axis-wide slice
 func Axis() *idxRange {
-	return &idxRange{0, -1}
+	return &idxRange{step: 1, kind: idxSlice}
+}
+
+// ISlc() is a slice 'start:end'. Negative values are counted from the end of the axis
+func ISlc(start, end int) *idxRange {
+	return &idxRange{start: start, end: end, step: 1, has_start: true, has_end: true, kind: idxSlice}
+}
+
+// IFrom() is a slice 'start:'
+func IFrom(start int) *idxRange {
+	return &idxRange{start: start, step: 1, has_start: true, kind: idxSlice}
+}
+
+// ITo() is a slice ':end'
+func ITo(end int) *idxRange {
+	return &idxRange{end: end, step: 1, has_end: true, kind: idxSlice}
+}
+
+// Step() sets the step of the slice. Axis().Step(-1) is the same as '::-1'
+func (r *idxRange) Step(step int) *idxRange {
+	if r.kind == idxSlice {
+		r.step = step
+	}
+	return r
+}
+
+// Ellipsis() is the same as '...'
+func Ellipsis() *idxRange {
+	return &idxRange{kind: idxEllipsis}
+}
+
+// NewAxis() is the same as 'None'
+func NewAxis() *idxRange {
+	return &idxRange{kind: idxNewAxis}
+}
+
+func (r *idxRange) String() string {
+	switch r.kind {
+	case idxSingle:
+		return strconv.Itoa(r.start)
+	case idxEllipsis:
+		return "..."
+	case idxNewAxis:
+		return "None"
+	}
+	s := ":"
+	if r.has_start {
+		s = strconv.Itoa(r.start) + s
+	}
+	if r.has_end {
+		s += strconv.Itoa(r.end)
+	}
+	if r.step != 1 {
+		s += ":" + strconv.Itoa(r.step)
+	}
+	return s
+}
+
+// resolves the slice for the dim of given size.
+// Returns the first index, the number of taken elements and the step
+func (r *idxRange) resolve(dim int) (int, int, error) {
+	if r.step == 0 {
+		return 0, 0, fmt.Errorf("slice '%v' step cannot be zero", r)
+	}
+	normalize := func(v int) (int, error) {
+		if v < -dim || v > dim {
+			return 0, fmt.Errorf("slice '%v' is out of range for dim %v", r, dim)
+		}
+		if v < 0 {
+			v += dim
+		}
+		return v, nil
+	}
+	var start, end int
+	var err error
+	if r.step > 0 {
+		start, end = 0, dim
+		if r.has_start {
+			if start, err = normalize(r.start); err != nil {
+				return 0, 0, err
+			}
+		}
+		if r.has_end {
+			if end, err = normalize(r.end); err != nil {
+				return 0, 0, err
+			}
+		}
+	} else {
+		// going backwards 'end' is exclusive and -1 means "before the first element"
+		start, end = dim-1, -1
+		if r.has_start {
+			if start, err = normalize(r.start); err != nil {
+				return 0, 0, err
+			}
+			start = min(start, dim-1)
+		}
+		if r.has_end {
+			if end, err = normalize(r.end); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	n := 0
+	if r.step > 0 && end > start {
+		n = (end - start + r.step - 1) / r.step
+	} else if r.step < 0 && start > end {
+		n = (start - end - r.step - 1) / -r.step
+	}
+	if n == 0 {
+		// empty slice gives an empty view
+		return 0, 0, nil
+	}
+	return start, n, nil
 }
 
-// TODO Implement index slices
-// func ISlc(start, end uint) *idxRange {
-// 	return &idxRange{int(start), int(end)}
-// }
+func parse_int(s string) (int, error) {
+	if floatVal, err := strconv.ParseFloat(s, 64); err == nil {
+		return int(floatVal), nil
+	}
+	return 0, fmt.Errorf("found unknown symbol in expression: '%v'", s)
+}
+
+// parses a single slice like 'start:end:step', any of the parts can be omitted
+func parse_slice(el string) (*idxRange, error) {
+	parts := strings.Split(el, ":")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid slice: '%v'. Expected 'start:end:step'", el)
+	}
+	r := Axis()
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		v, err := parse_int(part)
+		if err != nil {
+			return nil, err
+		}
+		switch i {
+		case 0:
+			r.start, r.has_start = v, true
+		case 1:
+			r.end, r.has_end = v, true
+		case 2:
+			r.step = v
+		}
+	}
+	return r, nil
+}
 
 func parse_indexes(expr string) ([]*idxRange, error) {
 	if len(expr) == 0 {
@@ -134,27 +283,64 @@ func parse_indexes(expr string) ([]*idxRange, error) {
 	indices := make([]*idxRange, 0, len(symbols))
 	for _, el := range symbols {
 		el = strings.TrimSpace(el)
-		switch el {
-		case ":":
-			indices = append(indices, Axis())
-		case "":
+		switch {
+		case el == "...":
+			indices = append(indices, Ellipsis())
+		case el == "None":
+			indices = append(indices, NewAxis())
+		case el == "":
 			return nil, fmt.Errorf(
-				"invalid expression: '%v'. Arguments should be numeric or ':' and separated by ','", expr,
+				"invalid expression: '%v'. Arguments should be numeric, slices, '...' or 'None' and separated by ','", expr,
 			)
+		case strings.Contains(el, ":"):
+			r, err := parse_slice(el)
+			if err != nil {
+				return nil, err
+			}
+			indices = append(indices, r)
 		default:
-			if floatVal, err := strconv.ParseFloat(el, 64); err == nil {
-				indices = append(indices, I(int(floatVal)))
-				continue
+			v, err := parse_int(el)
+			if err != nil {
+				return nil, err
 			}
-			return nil, fmt.Errorf(
-				"found unknown symbol in expression: '%v'", el,
-			)
+			indices = append(indices, I(v))
 		}
 	}
 	return indices, nil
 }
 
+// replaces ellipsis with full axes so that every dim of the tensor gets an index
+func expand_ellipsis(indices []*idxRange, ndim int) ([]*idxRange, error) {
+	ellipsis_at := -1
+	n_consumed := 0
+	for i, idx := range indices {
+		switch idx.kind {
+		case idxEllipsis:
+			if ellipsis_at >= 0 {
+				return nil, errors.New("an index can only have a single ellipsis '...'")
+			}
+			ellipsis_at = i
+		case idxSingle, idxSlice:
+			n_consumed++
+		}
+	}
+	if n_consumed > ndim {
+		return nil, errors.New("too many indices")
+	}
+	if ellipsis_at < 0 {
+		return indices, nil
+	}
+	expanded := make([]*idxRange, 0, len(indices)+ndim-n_consumed)
+	expanded = append(expanded, indices[:ellipsis_at]...)
+	for i := 0; i < ndim-n_consumed; i++ {
+		expanded = append(expanded, Axis())
+	}
+	return append(expanded, indices[ellipsis_at+1:]...), nil
+}
+
 // Advanced indexing allows to specify index ranges.
+// Supported indices are: integer 'i', slice 'start:end:step' (any part can be omitted,
+// negative values are counted from the end), ellipsis '...' and new axis 'None'.
 //
 // Example: with given tensor:
 //
@@ -166,6 +352,8 @@ func parse_indexes(expr string) ([]*idxRange, error) {
 // should return
 // tensor.IndexAdv(":,0") ==> [1,4]
 // tensor.IndexAdv(":,1") ==> [2,5]
+// tensor.IndexAdv("..., ::-2") ==> [[3,1],[6,4]]
+// tensor.IndexAdv("-1, None") ==> [[4,5,6]]
 //
 // Getting a sub tensor by axis is similar to:
 // a.TrC(2, 0, 1, 3, 4).Index(n) == a.IndexAdv(":,:,n,:,:")
@@ -182,125 +370,259 @@ func (tensor *Tensor[T]) IndexAdv_(indices ...*idxRange) *Tensor[T] {
 	if tensor.Err != nil {
 		return tensor
 	}
+	first, shape, strides, err := tensor.resolveIndices(indices)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	if len(shape) == 0 {
+		return scalar(tensor.data()[first])
+	}
+	return tensor.makeView(first, shape, strides)
+}
 
+// computes the position of the first element, the shape and the strides of the region selected by indices.
+// Empty shape means that all dims were indexed by a constant index
+func (tensor *Tensor[T]) resolveIndices(indices []*idxRange) (int, types.Shape, []int, error) {
 	if len(indices) == 0 {
-		tensor.Err = errors.New("at least one index is required")
+		return 0, nil, nil, errors.New("at least one index is required")
+	}
+	indices, err := expand_ellipsis(indices, len(tensor.shape))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	// constant index I(i) removes the dim and moves the first element of the view,
+	// slice keeps the dim, moves the first element to 'start' and multiplies the stride by 'step'.
+	// Example: indices => [Axis(),I(i)] for shape (4,3) strides (3,1)
+	// gives view with shape (4,), strides (3,) starting at i
+	first := tensor.offset
+	shape := make(types.Shape, 0, len(tensor.shape)+len(indices))
+	strides := make([]int, 0, len(tensor.shape)+len(indices))
+	dim_i := 0
+	for _, idx := range indices {
+		if idx.kind == idxNewAxis {
+			shape = append(shape, 1)
+			strides = append(strides, 1)
+			continue
+		}
+		dim := int(tensor.shape[dim_i])
+		stride := tensor.strides[dim_i]
+		dim_i++
+		if idx.kind == idxSlice {
+			start, n, err := idx.resolve(dim)
+			if err != nil {
+				return 0, nil, nil, err
+			}
+			first += start * stride
+			shape = append(shape, types.Dim(n))
+			strides = append(strides, stride*idx.step)
+			continue
+		}
+		i := idx.start
+		if i < 0 {
+			i += dim
+		}
+		if i < 0 || i >= dim {
+			return 0, nil, nil, fmt.Errorf("index %v is out of bounds for dim %v", idx.start, dim)
+		}
+		first += i * stride
+	}
+	// remaining dims are taken as is
+	for ; dim_i < len(tensor.shape); dim_i++ {
+		shape = append(shape, tensor.shape[dim_i])
+		strides = append(strides, tensor.strides[dim_i])
+	}
+	return first, shape, strides, nil
+}
+
+// Writes 'src' into the region selected by the expression. Expression syntax is the same as in IndexAdv.
+// 'src' is broadcasted to the shape of the region, so Scalar(v) fills the whole region with v.
+//
+// Example:
+// a := Range[int32](6).Reshape(2, 3)
+// a.SetIndexAdv(":, 1:", Scalar[int32](0)) ==> [[0,0,0],[3,0,0]]
+func (tensor *Tensor[T]) SetIndexAdv(expr string, src *Tensor[T]) *Tensor[T] {
+	indices, err := parse_indexes(expr)
+	if err != nil {
+		tensor.Err = err
 		return tensor
 	}
-	if len(indices) > len(tensor.shape) {
-		tensor.Err = errors.New("too many indices")
+	return tensor.SetIndexAdv_(src, indices...)
+}
+
+func (tensor *Tensor[T]) SetIndexAdv_(src *Tensor[T], indices ...*idxRange) *Tensor[T] {
+	if tensor.Err != nil {
 		return tensor
 	}
-	// remove trailing axis-wide idx
-	// for example: [I(),Axis(),I(),Axis(),Axis()] => [I(),Axis(),I()]
-	j := len(indices) - 1
-	for i := len(indices) - 1; i >= 0; i-- {
-		index_range := indices[i]
-		if index_range.start == 0 && index_range.end == -1 {
-			j -= 1
-		} else {
-			break
-		}
+	if src.Err != nil {
+		tensor.Err = src.Err
+		return tensor
 	}
-	indices = indices[:j+1]
-	if len(indices) == 0 {
-		return tensor.Copy()
-	}
-
-	are_constants_only := true
-	for i := 0; i < len(indices); i++ {
-		index_range := indices[i]
-		if index_range.start == 0 && index_range.end == -1 {
-			// axis wide
-			are_constants_only = false
-		} else if index_range.end != index_range.start {
-			// sub axis
-			are_constants_only = false
-		}
+	first, shape, strides, err := tensor.resolveIndices(indices)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	if len(shape) == 0 {
+		shape, strides = types.Shape{1}, []int{1}
 	}
+	region := tensor.makeView(first, shape, strides)
 
-	if are_constants_only {
-		idxs := make([]int, len(indices))
-		for i, idx_range := range indices {
-			idxs[i] = idx_range.start
-		}
-		return tensor.Index(idxs...)
+	// leading dims of size 1 can be dropped, e.g. (1,1,3) can be written into (2,3)
+	src_shape := src.shape
+	for len(src_shape) > len(shape) && src_shape[0] == 1 {
+		src_shape = src_shape[1:]
+	}
+	if !src_shape.AreBroadcastable(shape) || !src_shape.BroadcastShapes(shape).Equals(shape) {
+		tensor.Err = fmt.Errorf("cannot broadcast src of shape %v to the indexed region of shape %v", src.shape, shape)
+		return tensor
+	}
+	src = src.View().Reshape(src_shape...).Broadcast(shape...).AsContiguous()
+	if src.Err != nil {
+		tensor.Err = src.Err
+		return tensor
+	}
+	// src may be a view of the same data, so it has to be copied to avoid overwriting the elements before reading
+	if sharesBuffer(src.data_buff, tensor.data_buff) {
+		src = src.Clone()
 	}
+	region.copyFromContiguous(src)
+	return tensor
+}
 
-	// prepare axes to T()
+// DATA LAYOUT (move to other file?)
 
-	// Example: indices => [Axis(),I(i),I(j)]
-	// init strides: [4,2,1]
-	// init axes: [0,1,2]
-	// It needs two (2 I()) transpositions with axes:
-	// [1,0,2] then: [1,0]
-	// T(1,0,2).Index(i).T(1,0).Index(j)
-	shift := 0
-	dims := len(tensor.shape)
-	axes := make([]uint, dims)
-	for j := range axes {
-		axes[j] = uint(j)
+// creates a view which shares data_buff with the tensor.
+// 'first' is the position of the view's first element in tensor's data
+func (tensor *Tensor[T]) makeView(first int, shape types.Shape, strides []int) *Tensor[T] {
+	if slices.Contains(shape, 0) {
+		// empty view doesn't reach any element
+		view := &Tensor[T]{
+			data_buff: tensor.data()[:0],
+			shape:     append(types.Shape(nil), shape...),
+			strides:   append([]int(nil), strides...),
+		}
+		view.dim_order = orderFromStrides(view.strides)
+		return view
 	}
-	for i, idx := range indices {
-		if idx.start == idx.end {
-			shifted_axes := make([]uint, dims-shift)
-			copy(shifted_axes, axes[:dims-shift])
-			prev := shifted_axes[0]
-			shifted_axes[0] = shifted_axes[i-shift]
-
-			for j := 1; j < dims-shift; j++ {
-				shifted_axes[j], prev = prev, shifted_axes[j]
-				if i-shift == j {
-					break
-				}
+	// find the range of elements reachable by the view
+	low, high := first, first
+	for i, dim := range shape {
+		span := (int(dim) - 1) * strides[i]
+		if span < 0 {
+			low += span
+		} else {
+			high += span
+		}
+	}
+	view := &Tensor[T]{
+		data_buff: tensor.data()[low : high+1],
+		shape:     append(types.Shape(nil), shape...),
+		strides:   append([]int(nil), strides...),
+		offset:    first - low,
+	}
+	view.dim_order = orderFromStrides(view.strides)
+	return view
+}
+
+// creates a new tensor header which shares the memory with the original tensor.
+// Changes of the view data are visible in the original tensor and vice versa.
+func (tensor *Tensor[T]) View() *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	return &Tensor[T]{
+		data_buff: tensor.data_buff,
+		shape:     append(types.Shape(nil), tensor.shape...),
+		strides:   append([]int(nil), tensor.strides...),
+		dim_order: append([]uint16(nil), tensor.dim_order...),
+		offset:    tensor.offset,
+	}
+}
+
+// dim order derived from strides: the dim with the largest stride goes first
+func orderFromStrides(strides []int) []uint16 {
+	order := make([]uint16, len(strides))
+	for i, stride := range strides {
+		for j, other := range strides {
+			if abs(other) > abs(stride) || (abs(other) == abs(stride) && j < i) {
+				order[i]++
 			}
-			tensor = tensor.TrC(shifted_axes...).Index(idx.start)
-			shift += 1
 		}
 	}
-	return tensor
+	return order
 }
 
-// DATA LAYOUT (move to other file?)
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
 
-// Check if dimensions order is not shuffled and data layout is contiguous
+// Check if data layout is contiguous: elements are stored in row-major order without gaps
 func (tensor *Tensor[T]) IsContiguous() bool {
-	dimOrder := tensor.dim_order
-	switch len(dimOrder) {
-	case 1:
-		return true
-	case 2:
-		return dimOrder[0] == 0
-	default:
-		var min uint16 = 0
-		var dim uint16
-		for _, dim = range dimOrder {
-			if dim > min {
-				return false
-			}
-			min += 1
+	if tensor.offset != 0 {
+		return false
+	}
+	expected := 1
+	for i := len(tensor.shape) - 1; i >= 0; i-- {
+		dim := int(tensor.shape[i])
+		// stride of one-sized dim doesn't affect the layout
+		if dim != 1 && tensor.strides[i] != expected {
+			return false
 		}
-		return true
+		expected *= dim
 	}
+	return len(tensor.data_buff) == expected
 }
 
 // reorders data layout to contiguous format.
 // it is useful for optimizing indexing/iterating for transposed & other non-contiguous tensors
+//
+// Contiguous tensors are returned as is, otherwise a new tensor is created
 func (tensor *Tensor[T]) AsContiguous() *Tensor[T] {
 	if tensor.Err != nil {
 		return tensor
 	}
 	if tensor.IsContiguous() {
+		canonical := tensor.shape.GetStrides()
+		if !EqualSlices(tensor.strides, canonical) {
+			// strides of one-sized dims can differ from canonical ones. Kernels rely on them
+			view := tensor.View()
+			view.strides = canonical
+			return view
+		}
 		return tensor
 	}
 	outTensor := CreateEmptyTensor[T](tensor.shape...)
-	// for 2 dim tensor
-	if len(tensor.shape) == 2 {
+	// for transposed 2 dim tensor
+	if len(tensor.shape) == 2 && tensor.offset == 0 && len(tensor.data()) == len(outTensor.data()) &&
+		tensor.strides[0] == 1 && tensor.strides[1] == int(tensor.shape[0]) {
 		// make matrix contiguous
 		internal.TraverseAsContiguous2D(tensor.data(), outTensor.data(), tensor.shape)
 		return outTensor
 	}
 	// for N Dim tensor
-	internal.TraverseAsContiguousND[T](tensor.data(), outTensor.data(), tensor.strides, tensor.shape)
+	internal.TraverseAsContiguousND[T](tensor.data(), outTensor.data(), tensor.strides, tensor.shape, tensor.offset)
 	return outTensor
 }
+
+// reports whether two slices point to the same underlying array
+func sharesBuffer[T types.TensorType](a, b []T) bool {
+	if cap(a) == 0 || cap(b) == 0 {
+		return false
+	}
+	return &a[:cap(a)][cap(a)-1] == &b[:cap(b)][cap(b)-1]
+}
+
+// writes contiguous 'src' data to the tensor using tensor's strides.
+// Shapes must be equal
+func (tensor *Tensor[T]) copyFromContiguous(src *Tensor[T]) {
+	if tensor.IsContiguous() {
+		copy(tensor.data(), src.data())
+		return
+	}
+	internal.TraverseFromContiguousND(src.data(), tensor.data(), tensor.strides, tensor.shape, tensor.offset)
+}
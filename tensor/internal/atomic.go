@@ -3,6 +3,8 @@ package internal
 import (
 	"gograd/tensor/types"
 	"math"
+	"math/bits"
+	"unsafe"
 )
 
 // this is set of scalar operations used in generic tensor/ops.go
@@ -22,6 +24,28 @@ func DivAtomic[T types.TensorType](a, b T) T {
 	return a / b
 }
 
+func MaxAtomic[T types.TensorType](a, b T) T {
+	if b > a {
+		return b
+	}
+	return a
+}
+
+func MinAtomic[T types.TensorType](a, b T) T {
+	if b < a {
+		return b
+	}
+	return a
+}
+
+func GreaterAtomic[T types.TensorType](a, b T) bool {
+	return a > b
+}
+
+func LessAtomic[T types.TensorType](a, b T) bool {
+	return a < b
+}
+
 func PowAtomic[T types.TensorType](a, b T) T {
 	return T(math.Pow(float64(a), float64(b)))
 }
@@ -53,3 +77,238 @@ func ReluAtomic[T types.TensorType](a T) T {
 func ExpAtomic[T types.TensorType](a T) T {
 	return T(math.Exp(float64(a)))
 }
+
+func IsFloat[T types.TensorType]() bool {
+	switch any(*new(T)).(type) {
+	case float32, float64:
+		return true
+	}
+	return false
+}
+
+func is_unsigned[T types.TensorType]() bool {
+	return T(0)-1 > 0
+}
+
+// math library. Integer tensors are computed in float64 and truncated back, except rounding which keeps them as is
+
+func SqrtAtomic[T types.TensorType](a T) T {
+	return T(math.Sqrt(float64(a)))
+}
+
+func RsqrtAtomic[T types.TensorType](a T) T {
+	return T(1 / math.Sqrt(float64(a)))
+}
+
+func AbsAtomic[T types.TensorType](a T) T {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// -1, 0 or 1. NaN is kept
+func SignAtomic[T types.TensorType](a T) T {
+	switch {
+	case a > 0:
+		return 1
+	case a < 0:
+		var one T = 1
+		return -one
+	}
+	return a
+}
+
+func SinAtomic[T types.TensorType](a T) T {
+	return T(math.Sin(float64(a)))
+}
+
+func CosAtomic[T types.TensorType](a T) T {
+	return T(math.Cos(float64(a)))
+}
+
+func TanAtomic[T types.TensorType](a T) T {
+	return T(math.Tan(float64(a)))
+}
+
+func TanhAtomic[T types.TensorType](a T) T {
+	return T(math.Tanh(float64(a)))
+}
+
+func Log2Atomic[T types.TensorType](a T) T {
+	return T(math.Log2(float64(a)))
+}
+
+func Log10Atomic[T types.TensorType](a T) T {
+	return T(math.Log10(float64(a)))
+}
+
+func Log1pAtomic[T types.TensorType](a T) T {
+	return T(math.Log1p(float64(a)))
+}
+
+func Expm1Atomic[T types.TensorType](a T) T {
+	return T(math.Expm1(float64(a)))
+}
+
+func ErfAtomic[T types.TensorType](a T) T {
+	return T(math.Erf(float64(a)))
+}
+
+func FloorAtomic[T types.TensorType](a T) T {
+	if !IsFloat[T]() {
+		return a
+	}
+	return T(math.Floor(float64(a)))
+}
+
+func CeilAtomic[T types.TensorType](a T) T {
+	if !IsFloat[T]() {
+		return a
+	}
+	return T(math.Ceil(float64(a)))
+}
+
+// rounds half to even like numpy
+func RoundAtomic[T types.TensorType](a T) T {
+	if !IsFloat[T]() {
+		return a
+	}
+	return T(math.RoundToEven(float64(a)))
+}
+
+func TruncAtomic[T types.TensorType](a T) T {
+	if !IsFloat[T]() {
+		return a
+	}
+	return T(math.Trunc(float64(a)))
+}
+
+// integer reciprocal of 0 gives 0 like the integer division by zero
+func ReciprocalAtomic[T types.TensorType](a T) T {
+	if !IsFloat[T]() && a == 0 {
+		return 0
+	}
+	return 1 / a
+}
+
+func SquareAtomic[T types.TensorType](a T) T {
+	return a * a
+}
+
+// elementwise max and min which propagate NaN
+func MaximumAtomic[T types.TensorType](a, b T) T {
+	if a != a || b != b {
+		if a != a {
+			return a
+		}
+		return b
+	}
+	return MaxAtomic(a, b)
+}
+
+func MinimumAtomic[T types.TensorType](a, b T) T {
+	if a != a || b != b {
+		if a != a {
+			return a
+		}
+		return b
+	}
+	return MinAtomic(a, b)
+}
+
+// remainder of integer division truncated towards zero
+func int_rem[T types.TensorType](a, b T) T {
+	if is_unsigned[T]() {
+		return T(uint64(a) % uint64(b))
+	}
+	return T(int64(a) % int64(b))
+}
+
+// remainder of the floor division, it has the sign of the divisor like numpy mod.
+// Integer division by zero gives 0
+func ModAtomic[T types.TensorType](a, b T) T {
+	if IsFloat[T]() {
+		m := math.Mod(float64(a), float64(b))
+		if m != 0 && (m < 0) != (b < 0) {
+			m += float64(b)
+		}
+		return T(m)
+	}
+	if b == 0 {
+		return 0
+	}
+	m := int_rem(a, b)
+	if m != 0 && (m < 0) != (b < 0) {
+		m += b
+	}
+	return m
+}
+
+// division rounded towards negative infinity. Integer division by zero gives 0
+func FloorDivAtomic[T types.TensorType](a, b T) T {
+	if IsFloat[T]() {
+		return T(math.Floor(float64(a) / float64(b)))
+	}
+	if b == 0 {
+		return 0
+	}
+	q := a / b
+	if int_rem(a, b) != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// bitwise. Only for integer types, values are processed as 64 bit words and truncated back to T
+
+func BitAndAtomic[T types.TensorType](a, b T) T {
+	return T(uint64(a) & uint64(b))
+}
+
+func BitOrAtomic[T types.TensorType](a, b T) T {
+	return T(uint64(a) | uint64(b))
+}
+
+func BitXorAtomic[T types.TensorType](a, b T) T {
+	return T(uint64(a) ^ uint64(b))
+}
+
+func BitNotAtomic[T types.TensorType](a T) T {
+	return T(^uint64(a))
+}
+
+// negative shift counts are treated as too large shifts
+func ShlAtomic[T types.TensorType](a, b T) T {
+	return T(uint64(a) << uint64(b))
+}
+
+// arithmetic shift for signed types and logical for unsigned
+func ShrAtomic[T types.TensorType](a, b T) T {
+	if is_unsigned[T]() {
+		return T(uint64(a) >> uint64(b))
+	}
+	return T(int64(a) >> uint64(b))
+}
+
+// number of set bits in the value's own width, so -1 of int8 gives 8
+func PopCountAtomic[T types.TensorType](a T) T {
+	width := unsafe.Sizeof(a) * 8
+	word := uint64(a)
+	if width < 64 {
+		word &= 1<<width - 1
+	}
+	return T(bits.OnesCount64(word))
+}
+
+// log(exp(a) + exp(b)) without overflow
+func LogAddExpAtomic[T types.TensorType](a, b T) T {
+	x, y := float64(a), float64(b)
+	if x < y {
+		x, y = y, x
+	}
+	if math.IsInf(y, -1) {
+		return T(x)
+	}
+	return T(x + math.Log1p(math.Exp(y-x)))
+}
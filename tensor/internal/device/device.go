@@ -16,6 +16,11 @@ import (
 type Implementation struct {
 	impl         int
 	all_suppored []string
+	// conversion instructions for 16-bit floats
+	f16c bool
+	bf16 bool
+	// the AVX GEMM micro kernel needs AVX2 and FMA
+	fma bool
 }
 
 const (
@@ -41,6 +46,18 @@ func DetectImpl() *Implementation {
 	if cpuid.CPU.AnyOf(cpuid.AMXBF16, cpuid.AMXBF16, cpuid.AMXINT8, cpuid.AMXTILE) {
 		impl.all_suppored = append(impl.all_suppored, "AMX")
 	}
+	if cpuid.CPU.Supports(cpuid.AVX, cpuid.F16C) {
+		impl.f16c = true
+		impl.all_suppored = append(impl.all_suppored, "F16C")
+	}
+	if cpuid.CPU.Supports(cpuid.AVX512F, cpuid.AVX512BF16) {
+		impl.bf16 = true
+		impl.all_suppored = append(impl.all_suppored, "AVX512BF16")
+	}
+	if cpuid.CPU.Supports(cpuid.AVX2, cpuid.FMA3) {
+		impl.fma = true
+		impl.all_suppored = append(impl.all_suppored, "FMA")
+	}
 	// select the best cpu impl
 	if cpuid.CPU.Supports(cpuid.AVX512F, cpuid.AVX512DQ) {
 		impl.impl = AVX512
@@ -73,25 +90,51 @@ func IsImplAvailable(i *Implementation) bool {
 }
 
 // binary
+
+// batched matmul, see internal.GemmMatx
 func MatMul[T types.TensorType](
 	i Implementation,
 	a, b, out []T,
-	a_shape, b_shape types.Shape,
-	a_strides, b_strides, out_strides []int,
+	m, k, n int,
+	a_batches, b_batches []int,
 ) {
 	af, bf, outf := types.Input_to_float32(a, b, out)
-	switch i.impl {
-	case AVX:
-		internal.MatMulMatx(af, bf, outf, a_shape, b_shape, a_strides, b_strides, out_strides, amd64.Dot_mm256)
-	case AVX512:
-		internal.MatMulMatx(af, bf, outf, a_shape, b_shape, a_strides, b_strides, out_strides, amd64.Dot_mm512)
+	if af == nil {
+		// no SIMD kernels for other types yet
+		internal.GemmMatx(a, b, out, m, k, n, a_batches, b_batches, internal.GoGemmKernel[T]())
+		return
+	}
+	if n == 1 {
+		// matrix-vector product. b is a contiguous vector, so every output is a dot product
+		switch i.impl {
+		case AVX:
+			internal.MatMulMatx(af, bf, outf, m, k, n, a_batches, b_batches, amd64.Dot_mm256)
+		case AVX512:
+			internal.MatMulMatx(af, bf, outf, m, k, n, a_batches, b_batches, amd64.Dot_mm512)
+		default:
+			internal.MatMulMatx(af, bf, outf, m, k, n, a_batches, b_batches, internal.Dot[float32])
+		}
+		return
+	}
+	var kernel internal.GemmKernel[float32]
+	switch {
+	case i.impl == AVX && i.fma:
+		kernel = internal.GemmKernel[float32]{MR: src.GEMM_MR, NR: src.GEMM_NR_256, Macro: src.Gemm_macro_mm256}
+	case i.impl == AVX512:
+		kernel = internal.GemmKernel[float32]{MR: src.GEMM_MR, NR: src.GEMM_NR_512, Macro: src.Gemm_macro_mm512}
 	default:
-		internal.MatMulMatx(af, bf, outf, a_shape, b_shape, a_strides, b_strides, out_strides, internal.Dot[T])
+		// plain AVX cpus without FMA go here as well
+		kernel = internal.GoGemmKernel[float32]()
 	}
+	internal.GemmMatx(af, bf, outf, m, k, n, a_batches, b_batches, kernel)
 }
 
 func Mul[T types.TensorType](i Implementation, a, b, c []T) {
-	switch i.impl {
+	impl := i.impl
+	if !has_simd_elementwise[T]() {
+		impl = Default
+	}
+	switch impl {
 	case AVX:
 		src.Mul_mm256(a, b, c)
 	case AVX512:
@@ -103,7 +146,12 @@ func Mul[T types.TensorType](i Implementation, a, b, c []T) {
 }
 
 func Div[T types.TensorType](i Implementation, a, b, c []T) {
-	switch i.impl {
+	impl := i.impl
+	if !has_simd_math[T]() {
+		// the AVX kernel is implemented for float32 only
+		impl = Default
+	}
+	switch impl {
 	case AVX:
 		internal.RunSimdImpl(a, b, c, src.Div_mm256)
 	case AVX512:
@@ -113,8 +161,22 @@ func Div[T types.TensorType](i Implementation, a, b, c []T) {
 	}
 }
 
+// SIMD elementwise kernels are implemented for float32 and int32 only.
+// int is 64 bit wide, so it goes to the pure go implementation
+func has_simd_elementwise[T types.TensorType]() bool {
+	switch any(*new(T)).(type) {
+	case float32, int32:
+		return true
+	}
+	return false
+}
+
 func Add[T types.TensorType](i Implementation, a, b, c []T) {
-	switch i.impl {
+	impl := i.impl
+	if !has_simd_elementwise[T]() {
+		impl = Default
+	}
+	switch impl {
 	case AVX:
 		src.Add_mm256(a, b, c)
 	case AVX512:
@@ -125,7 +187,11 @@ func Add[T types.TensorType](i Implementation, a, b, c []T) {
 }
 
 func Sub[T types.TensorType](i Implementation, a, b, c []T) {
-	switch i.impl {
+	impl := i.impl
+	if !has_simd_elementwise[T]() {
+		impl = Default
+	}
+	switch impl {
 	case AVX:
 		src.Sub_mm256(a, b, c)
 	case AVX512:
@@ -167,6 +233,151 @@ func Softmax[T types.TensorType](i Implementation, a, c []T, strides []int) {
 	internal.SoftmaxMatx(a, c, strides)
 }
 
+// math library. AVX kernels are implemented for float32 only
+func has_simd_math[T types.TensorType]() bool {
+	_, ok := any(*new(T)).(float32)
+	return ok
+}
+
+func unary_math[T types.TensorType](i Implementation, op src.MathOp, a, c []T, atomic func(T) T) {
+	if i.impl != Default && has_simd_math[T]() {
+		src.Math_mm256(op, a, c)
+		return
+	}
+	internal.ElementwiseNoSimdUnary(a, c, atomic)
+}
+
+func Sqrt[T types.TensorType](i Implementation, a, c []T) {
+	unary_math(i, src.MATH_SQRT, a, c, internal.SqrtAtomic[T])
+}
+
+func Rsqrt[T types.TensorType](i Implementation, a, c []T) {
+	unary_math(i, src.MATH_RSQRT, a, c, internal.RsqrtAtomic[T])
+}
+
+func Abs[T types.TensorType](i Implementation, a, c []T) {
+	unary_math(i, src.MATH_ABS, a, c, internal.AbsAtomic[T])
+}
+
+func Floor[T types.TensorType](i Implementation, a, c []T) {
+	unary_math(i, src.MATH_FLOOR, a, c, internal.FloorAtomic[T])
+}
+
+func Ceil[T types.TensorType](i Implementation, a, c []T) {
+	unary_math(i, src.MATH_CEIL, a, c, internal.CeilAtomic[T])
+}
+
+func Round[T types.TensorType](i Implementation, a, c []T) {
+	unary_math(i, src.MATH_ROUND, a, c, internal.RoundAtomic[T])
+}
+
+func Trunc[T types.TensorType](i Implementation, a, c []T) {
+	unary_math(i, src.MATH_TRUNC, a, c, internal.TruncAtomic[T])
+}
+
+func Square[T types.TensorType](i Implementation, a, c []T) {
+	unary_math(i, src.MATH_SQUARE, a, c, internal.SquareAtomic[T])
+}
+
+func Reciprocal[T types.TensorType](i Implementation, a, c []T) {
+	unary_math(i, src.MATH_RECIPROCAL, a, c, internal.ReciprocalAtomic[T])
+}
+
+// ops without SIMD kernels
+func Sign[T types.TensorType](i Implementation, a, c []T) {
+	internal.ElementwiseNoSimdUnary(a, c, internal.SignAtomic[T])
+}
+
+func Sin[T types.TensorType](i Implementation, a, c []T) {
+	internal.ElementwiseNoSimdUnary(a, c, internal.SinAtomic[T])
+}
+
+func Cos[T types.TensorType](i Implementation, a, c []T) {
+	internal.ElementwiseNoSimdUnary(a, c, internal.CosAtomic[T])
+}
+
+func Tan[T types.TensorType](i Implementation, a, c []T) {
+	internal.ElementwiseNoSimdUnary(a, c, internal.TanAtomic[T])
+}
+
+func Tanh[T types.TensorType](i Implementation, a, c []T) {
+	internal.ElementwiseNoSimdUnary(a, c, internal.TanhAtomic[T])
+}
+
+func Log2[T types.TensorType](i Implementation, a, c []T) {
+	internal.ElementwiseNoSimdUnary(a, c, internal.Log2Atomic[T])
+}
+
+func Log10[T types.TensorType](i Implementation, a, c []T) {
+	internal.ElementwiseNoSimdUnary(a, c, internal.Log10Atomic[T])
+}
+
+func Log1p[T types.TensorType](i Implementation, a, c []T) {
+	internal.ElementwiseNoSimdUnary(a, c, internal.Log1pAtomic[T])
+}
+
+func Expm1[T types.TensorType](i Implementation, a, c []T) {
+	internal.ElementwiseNoSimdUnary(a, c, internal.Expm1Atomic[T])
+}
+
+func Erf[T types.TensorType](i Implementation, a, c []T) {
+	internal.ElementwiseNoSimdUnary(a, c, internal.ErfAtomic[T])
+}
+
+// binary math
+func Maximum[T types.TensorType](i Implementation, a, b, c []T) {
+	if i.impl != Default && has_simd_math[T]() {
+		src.Maximum_mm256(a, b, c)
+		return
+	}
+	internal.ElementwiseNoSimd(a, b, c, internal.MaximumAtomic[T])
+}
+
+func Minimum[T types.TensorType](i Implementation, a, b, c []T) {
+	if i.impl != Default && has_simd_math[T]() {
+		src.Minimum_mm256(a, b, c)
+		return
+	}
+	internal.ElementwiseNoSimd(a, b, c, internal.MinimumAtomic[T])
+}
+
+func Mod[T types.TensorType](i Implementation, a, b, c []T) {
+	internal.ElementwiseNoSimd(a, b, c, internal.ModAtomic[T])
+}
+
+func FloorDiv[T types.TensorType](i Implementation, a, b, c []T) {
+	internal.ElementwiseNoSimd(a, b, c, internal.FloorDivAtomic[T])
+}
+
+// bitwise, integer types only
+func BitAnd[T types.TensorType](i Implementation, a, b, c []T) {
+	internal.ElementwiseNoSimd(a, b, c, internal.BitAndAtomic[T])
+}
+
+func BitOr[T types.TensorType](i Implementation, a, b, c []T) {
+	internal.ElementwiseNoSimd(a, b, c, internal.BitOrAtomic[T])
+}
+
+func BitXor[T types.TensorType](i Implementation, a, b, c []T) {
+	internal.ElementwiseNoSimd(a, b, c, internal.BitXorAtomic[T])
+}
+
+func Shl[T types.TensorType](i Implementation, a, b, c []T) {
+	internal.ElementwiseNoSimd(a, b, c, internal.ShlAtomic[T])
+}
+
+func Shr[T types.TensorType](i Implementation, a, b, c []T) {
+	internal.ElementwiseNoSimd(a, b, c, internal.ShrAtomic[T])
+}
+
+func BitNot[T types.TensorType](i Implementation, a, c []T) {
+	internal.ElementwiseNoSimdUnary(a, c, internal.BitNotAtomic[T])
+}
+
+func PopCount[T types.TensorType](i Implementation, a, c []T) {
+	internal.ElementwiseNoSimdUnary(a, c, internal.PopCountAtomic[T])
+}
+
 // masking
 func ApplyFunc[T types.TensorType](i Implementation, a []T, expr func(T) T, out []T) {
 	internal.ApplyFuncMatx(a, expr, out)
@@ -183,8 +394,64 @@ func Sum[T types.TensorType](i Implementation, a, c []T) {
 	internal.SumMatx(a, c)
 	// }
 }
-func SumAxis[T types.TensorType](i Implementation, a, c []T, shape types.Shape, axis int) {
-	internal.SumAxisMatx(a, c, shape, axis)
+
+// reductions along the middle dim of the data viewed as (outer, dim, inner)
+func SumAxis[T types.TensorType](i Implementation, a, c []T, outer, dim, inner int) {
+	internal.ReduceAxisMatx(a, c, outer, dim, inner, internal.AddAtomic[T])
+}
+
+func ProdAxis[T types.TensorType](i Implementation, a, c []T, outer, dim, inner int) {
+	internal.ReduceAxisMatx(a, c, outer, dim, inner, internal.MulAtomic[T])
+}
+
+func MaxAxis[T types.TensorType](i Implementation, a, c []T, outer, dim, inner int) {
+	internal.ReduceAxisMatx(a, c, outer, dim, inner, internal.MaxAtomic[T])
+}
+
+func MinAxis[T types.TensorType](i Implementation, a, c []T, outer, dim, inner int) {
+	internal.ReduceAxisMatx(a, c, outer, dim, inner, internal.MinAtomic[T])
+}
+
+func ArgMaxAxis[T types.TensorType](i Implementation, a []T, c []int, outer, dim, inner int) {
+	internal.ArgReduceAxisMatx(a, c, outer, dim, inner, internal.GreaterAtomic[T])
+}
+
+func ArgMinAxis[T types.TensorType](i Implementation, a []T, c []int, outer, dim, inner int) {
+	internal.ArgReduceAxisMatx(a, c, outer, dim, inner, internal.LessAtomic[T])
+}
+
+// cumulative
+func CumSumAxis[T types.TensorType](i Implementation, a, c []T, outer, dim, inner int) {
+	internal.ScanAxisMatx(a, c, outer, dim, inner, internal.AddAtomic[T])
+}
+
+func CumProdAxis[T types.TensorType](i Implementation, a, c []T, outer, dim, inner int) {
+	internal.ScanAxisMatx(a, c, outer, dim, inner, internal.MulAtomic[T])
+}
+
+func CumMaxAxis[T types.TensorType](i Implementation, a, c []T, outer, dim, inner int) {
+	internal.ScanAxisMatx(a, c, outer, dim, inner, internal.MaxAtomic[T])
+}
+
+func CumMinAxis[T types.TensorType](i Implementation, a, c []T, outer, dim, inner int) {
+	internal.ScanAxisMatx(a, c, outer, dim, inner, internal.MinAtomic[T])
+}
+
+func LogCumSumExpAxis[T types.TensorType](i Implementation, a, c []T, outer, dim, inner int) {
+	internal.ScanAxisMatx(a, c, outer, dim, inner, internal.LogAddExpAtomic[T])
+}
+
+// the last position of the running max is taken on ties
+func CumArgMaxAxis[T types.TensorType](i Implementation, a []T, c []int, outer, dim, inner int) {
+	internal.ArgScanAxisMatx(a, c, outer, dim, inner, func(a, b T) bool { return a >= b })
+}
+
+func CumArgMinAxis[T types.TensorType](i Implementation, a []T, c []int, outer, dim, inner int) {
+	internal.ArgScanAxisMatx(a, c, outer, dim, inner, func(a, b T) bool { return a <= b })
+}
+
+func Prod[T types.TensorType](i Implementation, a, c []T) {
+	internal.ProdMatx(a, c)
 }
 
 func Max[T types.TensorType](i Implementation, a, c []T) {
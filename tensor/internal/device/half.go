@@ -0,0 +1,51 @@
+package device
+
+import (
+	"gograd/tensor/internal"
+	"gograd/tensor/internal/intrinsics/src"
+	"gograd/tensor/types"
+	"sync"
+)
+
+// conversions of 16-bit floats. F16C and AVX512-BF16 kernels are used when available,
+// otherwise the values are converted in pure go
+
+func HalfToFloat32[H types.Half](i Implementation, a []H, out []float32) {
+	switch a := any(a).(type) {
+	case []types.Float16:
+		if i.impl != Default && i.f16c {
+			src.F16_to_f32_mm256(a, out)
+			return
+		}
+	case []types.BFloat16:
+		if i.impl == AVX512 {
+			src.Bf16_to_f32_mm512(a, out)
+			return
+		}
+	}
+	internal.Parallel(len(a), func(start, end int, _, _, _ []float32, _ *sync.Mutex) {
+		for j := start; j < end; j++ {
+			out[j] = types.HalfToFloat32(a[j])
+		}
+	}, nil, nil, nil)
+}
+
+func Float32ToHalf[H types.Half](i Implementation, a []float32, out []H) {
+	switch out := any(out).(type) {
+	case []types.Float16:
+		if i.impl != Default && i.f16c {
+			src.F32_to_f16_mm256(a, out)
+			return
+		}
+	case []types.BFloat16:
+		if i.impl == AVX512 && i.bf16 {
+			src.F32_to_bf16_mm512(a, out)
+			return
+		}
+	}
+	internal.Parallel(len(a), func(start, end int, a, _, _ []float32, _ *sync.Mutex) {
+		for j := start; j < end; j++ {
+			out[j] = types.HalfFromFloat32[H](a[j])
+		}
+	}, a, nil, nil)
+}
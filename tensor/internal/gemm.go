@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"gograd/tensor/types"
+	"sync"
+)
+
+// Packed, cache-blocked GEMM.
+//
+// C is split into (MC x NC) blocks which are computed independently, K is split into KC slices.
+// For every KC slice the blocks of A and B are packed into contiguous micro panels:
+// A panel holds MR rows stored column by column, B panel holds NR columns stored row by row.
+// So the micro kernel reads both panels sequentially and keeps the (MR x NR) tile of C in registers.
+const (
+	gemm_mc = 96
+	gemm_nc = 256
+	gemm_kc = 256
+)
+
+// Macro computes C[mc x nc] += A * B for the packed (mc x kc) A block and (kc x nc) B block.
+// 'c' starts at the first element of the block and has the leading dim 'ldc'
+type GemmKernel[T types.TensorType] struct {
+	MR, NR int
+	Macro  func(a, b, c []T, mc, nc, kc, ldc int)
+}
+
+// pure go kernel used when there is no SIMD kernel for the type
+func GoGemmKernel[T types.TensorType]() GemmKernel[T] {
+	return GemmKernel[T]{MR: 4, NR: 4, Macro: gemm_macro_go[T]}
+}
+
+// packs a[i0:i0+mc, p0:p0+kc] of the row-major (.., lda) matrix into micro panels of mr rows.
+// Rows beyond mc are zero padded
+func pack_a[T types.TensorType](a []T, lda, i0, mc, p0, kc, mr int, dst []T) {
+	pos := 0
+	for ir := 0; ir < mc; ir += mr {
+		rows := min(mr, mc-ir)
+		for p := 0; p < kc; p++ {
+			col := p0 + p
+			for r := 0; r < rows; r++ {
+				dst[pos+r] = a[(i0+ir+r)*lda+col]
+			}
+			for r := rows; r < mr; r++ {
+				dst[pos+r] = 0
+			}
+			pos += mr
+		}
+	}
+}
+
+// packs b[p0:p0+kc, j0:j0+nc] of the row-major (.., ldb) matrix into micro panels of nr columns.
+// Columns beyond nc are zero padded
+func pack_b[T types.TensorType](b []T, ldb, p0, kc, j0, nc, nr int, dst []T) {
+	pos := 0
+	for jr := 0; jr < nc; jr += nr {
+		cols := min(nr, nc-jr)
+		for p := 0; p < kc; p++ {
+			row := b[(p0+p)*ldb+j0+jr : (p0+p)*ldb+j0+jr+cols]
+			copy(dst[pos:pos+cols], row)
+			for j := cols; j < nr; j++ {
+				dst[pos+j] = 0
+			}
+			pos += nr
+		}
+	}
+}
+
+// Batched matrix multiplication using the packed GEMM.
+//
+// a is (a_batch, M, K), b is (b_batch, K, N), out is (batch, M, N). All are row-major.
+// a_batches[i] and b_batches[i] are the batches of a and b used for the i-th batch of the output.
+// Out must be zeroed, the kernels accumulate into it.
+// (batch, row block, col block) tasks are shared by all goroutines, every task writes its own block of out.
+func GemmMatx[T types.TensorType](
+	a_data, b_data, out_data []T,
+	m, k, n int,
+	a_batches, b_batches []int,
+	kernel GemmKernel[T],
+) {
+	row_blocks := (m + gemm_mc - 1) / gemm_mc
+	col_blocks := (n + gemm_nc - 1) / gemm_nc
+	blocks := row_blocks * col_blocks
+	n_tasks := len(a_batches) * blocks
+	workers := min(numCPU, n_tasks)
+
+	// panels are padded up to the multiple of MR and NR
+	a_panel_size := (gemm_mc + kernel.MR - 1) / kernel.MR * kernel.MR * gemm_kc
+	b_panel_size := (gemm_nc + kernel.NR - 1) / kernel.NR * kernel.NR * gemm_kc
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			a_packed := make([]T, a_panel_size)
+			b_packed := make([]T, b_panel_size)
+			for task := w; task < n_tasks; task += workers {
+				batch := task / blocks
+				i0 := (task % blocks) / col_blocks * gemm_mc
+				j0 := (task % blocks) % col_blocks * gemm_nc
+				mc, nc := min(gemm_mc, m-i0), min(gemm_nc, n-j0)
+				a := a_data[a_batches[batch]*m*k : (a_batches[batch]+1)*m*k]
+				b := b_data[b_batches[batch]*k*n : (b_batches[batch]+1)*k*n]
+				out := out_data[batch*m*n : (batch+1)*m*n]
+				for p0 := 0; p0 < k; p0 += gemm_kc {
+					kc := min(gemm_kc, k-p0)
+					pack_a(a, k, i0, mc, p0, kc, kernel.MR, a_packed)
+					pack_b(b, n, p0, kc, j0, nc, kernel.NR, b_packed)
+					kernel.Macro(a_packed, b_packed, out[i0*n+j0:], mc, nc, kc, n)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+// 4x4 register-blocked kernel. Tile of C is kept in local variables
+func gemm_kernel_4x4[T types.TensorType](kc int, a, b []T) (c [16]T) {
+	var c00, c01, c02, c03, c10, c11, c12, c13 T
+	var c20, c21, c22, c23, c30, c31, c32, c33 T
+	for p := 0; p < kc; p++ {
+		ap := a[p*4 : p*4+4 : p*4+4]
+		bp := b[p*4 : p*4+4 : p*4+4]
+		a0, a1, a2, a3 := ap[0], ap[1], ap[2], ap[3]
+		b0, b1, b2, b3 := bp[0], bp[1], bp[2], bp[3]
+		c00 += a0 * b0
+		c01 += a0 * b1
+		c02 += a0 * b2
+		c03 += a0 * b3
+		c10 += a1 * b0
+		c11 += a1 * b1
+		c12 += a1 * b2
+		c13 += a1 * b3
+		c20 += a2 * b0
+		c21 += a2 * b1
+		c22 += a2 * b2
+		c23 += a2 * b3
+		c30 += a3 * b0
+		c31 += a3 * b1
+		c32 += a3 * b2
+		c33 += a3 * b3
+	}
+	return [16]T{c00, c01, c02, c03, c10, c11, c12, c13, c20, c21, c22, c23, c30, c31, c32, c33}
+}
+
+func gemm_macro_go[T types.TensorType](a, b, c []T, mc, nc, kc, ldc int) {
+	for jr := 0; jr < nc; jr += 4 {
+		cols := min(4, nc-jr)
+		for ir := 0; ir < mc; ir += 4 {
+			rows := min(4, mc-ir)
+			tile := gemm_kernel_4x4(kc, a[ir*kc:], b[jr*kc:])
+			for i := 0; i < rows; i++ {
+				c_row := c[(ir+i)*ldc+jr : (ir+i)*ldc+jr+cols]
+				for j := range c_row {
+					c_row[j] += tile[i*4+j]
+				}
+			}
+		}
+	}
+}
@@ -2,13 +2,29 @@ package amd64
 
 import "unsafe"
 
+// the kernels sum up an uninitialized register when there are no full blocks,
+// so short vectors are handled here
+func dot_short(a, b []float32) float32 {
+	var ret float32
+	for i := 0; i < len(a); i++ {
+		ret += a[i] * b[i]
+	}
+	return ret
+}
+
 func Dot_mm256(a, b []float32) float32 {
+	if len(a) < 8 {
+		return dot_short(a, b)
+	}
 	var ret float32
 	_mm256_dot(unsafe.Pointer(&a[0]), unsafe.Pointer(&b[0]), unsafe.Pointer(uintptr(len(a))), unsafe.Pointer(&ret))
 	return ret
 }
 
 func Dot_mm512(a, b []float32) float32 {
+	if len(a) < 16 {
+		return dot_short(a, b)
+	}
 	var ret float32
 	_mm512_dot(unsafe.Pointer(&a[0]), unsafe.Pointer(&b[0]), unsafe.Pointer(uintptr(len(a))), unsafe.Pointer(&ret))
 	return ret
@@ -56,7 +56,7 @@ void _mm256i_mul_to(int32_t *a, int32_t *b, int32_t *c, int64_t n)
     {
         __m256i v1 = _mm256_loadu_si256((__m256i *)&a[i * 8]);
         __m256i v2 = _mm256_loadu_si256((__m256i *)&b[i * 8]);
-        __m256i v = _mm256_mul_epi32(v1, v2);
+        __m256i v = _mm256_mullo_epi32(v1, v2);
         _mm256_storeu_si256((__m256i *)&c[i * 8], v);
     }
 
@@ -77,7 +77,7 @@ void _mm256i_mul_to_const(int32_t *a, int32_t b, int32_t *c, int64_t n)
     for (int i = 0; i < epoch; i++)
     {
         __m256i v1 = _mm256_loadu_si256((__m256i*)&a[i * 8]);
-        __m256i v = _mm256_mul_epi32(v1, v2);
+        __m256i v = _mm256_mullo_epi32(v1, v2);
         _mm256_storeu_si256((__m256i*)&c[i * 8], v);
     }
 
@@ -0,0 +1,179 @@
+#include <stdint.h>
+#include <math.h>
+#include <immintrin.h>
+#include <omp.h>
+#include "avx_math.h"
+
+// defines a unary kernel. The vector expression uses 'v', the scalar one uses 'x'
+#define UNARY_KERNEL(name, vec_expr, scalar_expr)  \
+    static void name(float *a, float *c, int64_t n) \
+    {                                               \
+        int epoch = n / 8;                          \
+        int remain = n % 8;                         \
+        __m256 one = _mm256_set1_ps(1.0f);          \
+        __m256 sign = _mm256_set1_ps(-0.0f);        \
+        (void)one;                                  \
+        (void)sign;                                 \
+                                                    \
+        _Pragma("omp parallel for")                 \
+        for (int i = 0; i < epoch; i++)             \
+        {                                           \
+            __m256 v = _mm256_loadu_ps(a + i * 8);  \
+            _mm256_storeu_ps(c + i * 8, vec_expr);  \
+        }                                           \
+        int offset = epoch * 8;                     \
+        for (int i = 0; i < remain; i++)            \
+        {                                           \
+            float x = a[offset + i];                \
+            c[offset + i] = scalar_expr;            \
+        }                                           \
+    }
+
+UNARY_KERNEL(sqrt_kernel, _mm256_sqrt_ps(v), sqrtf(x))
+// exact 1/sqrt, _mm256_rsqrt_ps has only 12 bits of precision
+UNARY_KERNEL(rsqrt_kernel, _mm256_div_ps(one, _mm256_sqrt_ps(v)), 1.0f / sqrtf(x))
+UNARY_KERNEL(abs_kernel, _mm256_andnot_ps(sign, v), fabsf(x))
+UNARY_KERNEL(floor_kernel, _mm256_round_ps(v, _MM_FROUND_TO_NEG_INF | _MM_FROUND_NO_EXC), floorf(x))
+UNARY_KERNEL(ceil_kernel, _mm256_round_ps(v, _MM_FROUND_TO_POS_INF | _MM_FROUND_NO_EXC), ceilf(x))
+// half to even like numpy
+UNARY_KERNEL(round_kernel, _mm256_round_ps(v, _MM_FROUND_TO_NEAREST_INT | _MM_FROUND_NO_EXC), nearbyintf(x))
+UNARY_KERNEL(trunc_kernel, _mm256_round_ps(v, _MM_FROUND_TO_ZERO | _MM_FROUND_NO_EXC), truncf(x))
+UNARY_KERNEL(square_kernel, _mm256_mul_ps(v, v), x * x)
+UNARY_KERNEL(reciprocal_kernel, _mm256_div_ps(one, v), 1.0f / x)
+
+void _mm256_math(int op, float *a, float *c, int64_t n)
+{
+    switch (op)
+    {
+    case MATH_SQRT:
+        sqrt_kernel(a, c, n);
+        break;
+    case MATH_RSQRT:
+        rsqrt_kernel(a, c, n);
+        break;
+    case MATH_ABS:
+        abs_kernel(a, c, n);
+        break;
+    case MATH_FLOOR:
+        floor_kernel(a, c, n);
+        break;
+    case MATH_CEIL:
+        ceil_kernel(a, c, n);
+        break;
+    case MATH_ROUND:
+        round_kernel(a, c, n);
+        break;
+    case MATH_TRUNC:
+        trunc_kernel(a, c, n);
+        break;
+    case MATH_SQUARE:
+        square_kernel(a, c, n);
+        break;
+    case MATH_RECIPROCAL:
+        reciprocal_kernel(a, c, n);
+        break;
+    }
+}
+
+// max_ps(y, x) returns x if any of them is NaN, so only NaN in y has to be blended
+static inline __m256 maximum_ps(__m256 x, __m256 y)
+{
+    __m256 nan_y = _mm256_cmp_ps(y, y, _CMP_UNORD_Q);
+    return _mm256_blendv_ps(_mm256_max_ps(y, x), y, nan_y);
+}
+
+static inline __m256 minimum_ps(__m256 x, __m256 y)
+{
+    __m256 nan_y = _mm256_cmp_ps(y, y, _CMP_UNORD_Q);
+    return _mm256_blendv_ps(_mm256_min_ps(y, x), y, nan_y);
+}
+
+static inline float maximum(float x, float y)
+{
+    if (isnan(x) || isnan(y))
+        return isnan(x) ? x : y;
+    return x > y ? x : y;
+}
+
+static inline float minimum(float x, float y)
+{
+    if (isnan(x) || isnan(y))
+        return isnan(x) ? x : y;
+    return x < y ? x : y;
+}
+
+void _mm256_maximum_to(float *a, float *b, float *c, int64_t n)
+{
+    int epoch = n / 8;
+    int remain = n % 8;
+
+    #pragma omp parallel for
+    for (int i = 0; i < epoch; i++)
+    {
+        __m256 v1 = _mm256_loadu_ps(a + i * 8);
+        __m256 v2 = _mm256_loadu_ps(b + i * 8);
+        _mm256_storeu_ps(c + i * 8, maximum_ps(v1, v2));
+    }
+    int offset = epoch * 8;
+    for (int i = 0; i < remain; i++)
+    {
+        c[offset + i] = maximum(a[offset + i], b[offset + i]);
+    }
+}
+
+void _mm256_maximum_to_const(float *a, float b, float *c, int64_t n)
+{
+    int epoch = n / 8;
+    int remain = n % 8;
+    __m256 v2 = _mm256_set1_ps(b);
+
+    #pragma omp parallel for
+    for (int i = 0; i < epoch; i++)
+    {
+        __m256 v1 = _mm256_loadu_ps(a + i * 8);
+        _mm256_storeu_ps(c + i * 8, maximum_ps(v1, v2));
+    }
+    int offset = epoch * 8;
+    for (int i = 0; i < remain; i++)
+    {
+        c[offset + i] = maximum(a[offset + i], b);
+    }
+}
+
+void _mm256_minimum_to(float *a, float *b, float *c, int64_t n)
+{
+    int epoch = n / 8;
+    int remain = n % 8;
+
+    #pragma omp parallel for
+    for (int i = 0; i < epoch; i++)
+    {
+        __m256 v1 = _mm256_loadu_ps(a + i * 8);
+        __m256 v2 = _mm256_loadu_ps(b + i * 8);
+        _mm256_storeu_ps(c + i * 8, minimum_ps(v1, v2));
+    }
+    int offset = epoch * 8;
+    for (int i = 0; i < remain; i++)
+    {
+        c[offset + i] = minimum(a[offset + i], b[offset + i]);
+    }
+}
+
+void _mm256_minimum_to_const(float *a, float b, float *c, int64_t n)
+{
+    int epoch = n / 8;
+    int remain = n % 8;
+    __m256 v2 = _mm256_set1_ps(b);
+
+    #pragma omp parallel for
+    for (int i = 0; i < epoch; i++)
+    {
+        __m256 v1 = _mm256_loadu_ps(a + i * 8);
+        _mm256_storeu_ps(c + i * 8, minimum_ps(v1, v2));
+    }
+    int offset = epoch * 8;
+    for (int i = 0; i < remain; i++)
+    {
+        c[offset + i] = minimum(a[offset + i], b);
+    }
+}
@@ -0,0 +1,79 @@
+package src
+
+/*
+#include "avx_math.h"
+*/
+import "C"
+import (
+	"gograd/tensor/types"
+	"reflect"
+	"unsafe"
+)
+
+// unary math ops with AVX kernels. Values must match the enum in avx_math.h
+type MathOp int
+
+const (
+	MATH_SQRT MathOp = iota
+	MATH_RSQRT
+	MATH_ABS
+	MATH_FLOOR
+	MATH_CEIL
+	MATH_ROUND
+	MATH_TRUNC
+	MATH_SQUARE
+	MATH_RECIPROCAL
+)
+
+func Math_mm256[T types.TensorType](op MathOp, a, c []T) {
+	if len(a) == 0 || len(c) == 0 {
+		return
+	}
+	t := reflect.TypeOf(a[0]).Kind()
+	switch t {
+	case reflect.Float32:
+		C._mm256_math(C.int(op), (*C.float)(unsafe.Pointer(&a[0])), (*C.float)(unsafe.Pointer(&c[0])), C.longlong(len(a)))
+	default:
+		not_implemented_err("Math", t)
+	}
+}
+
+func Maximum_mm256[T types.TensorType](a, b, c []T) {
+	if len(a) == 0 || len(b) == 0 || len(c) == 0 {
+		return
+	}
+	t := reflect.TypeOf(a[0]).Kind()
+	switch t {
+	case reflect.Float32:
+		out := (*C.float)(unsafe.Pointer(&c[0]))
+		if len(b) == 1 {
+			C._mm256_maximum_to_const((*C.float)(unsafe.Pointer(&a[0])), C.float(float32(b[0])), out, C.longlong(len(a)))
+		} else if len(a) == 1 {
+			C._mm256_maximum_to_const((*C.float)(unsafe.Pointer(&b[0])), C.float(float32(a[0])), out, C.longlong(len(b)))
+		} else {
+			C._mm256_maximum_to((*C.float)(unsafe.Pointer(&a[0])), (*C.float)(unsafe.Pointer(&b[0])), out, C.longlong(len(a)))
+		}
+	default:
+		not_implemented_err("Maximum", t)
+	}
+}
+
+func Minimum_mm256[T types.TensorType](a, b, c []T) {
+	if len(a) == 0 || len(b) == 0 || len(c) == 0 {
+		return
+	}
+	t := reflect.TypeOf(a[0]).Kind()
+	switch t {
+	case reflect.Float32:
+		out := (*C.float)(unsafe.Pointer(&c[0]))
+		if len(b) == 1 {
+			C._mm256_minimum_to_const((*C.float)(unsafe.Pointer(&a[0])), C.float(float32(b[0])), out, C.longlong(len(a)))
+		} else if len(a) == 1 {
+			C._mm256_minimum_to_const((*C.float)(unsafe.Pointer(&b[0])), C.float(float32(a[0])), out, C.longlong(len(b)))
+		} else {
+			C._mm256_minimum_to((*C.float)(unsafe.Pointer(&a[0])), (*C.float)(unsafe.Pointer(&b[0])), out, C.longlong(len(a)))
+		}
+	default:
+		not_implemented_err("Minimum", t)
+	}
+}
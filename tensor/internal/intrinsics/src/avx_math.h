@@ -0,0 +1,27 @@
+#ifndef AVX_MATH_H
+#define AVX_MATH_H
+#include <stdint.h>
+
+// unary math ops, must match the MathOp constants in avx_math.go
+enum
+{
+    MATH_SQRT,
+    MATH_RSQRT,
+    MATH_ABS,
+    MATH_FLOOR,
+    MATH_CEIL,
+    MATH_ROUND,
+    MATH_TRUNC,
+    MATH_SQUARE,
+    MATH_RECIPROCAL,
+};
+
+void _mm256_math(int op, float *a, float *c, int64_t n);
+
+// elementwise maximum and minimum, NaN is propagated
+void _mm256_maximum_to(float *a, float *b, float *c, int64_t n);
+void _mm256_maximum_to_const(float *a, float b, float *c, int64_t n);
+void _mm256_minimum_to(float *a, float *b, float *c, int64_t n);
+void _mm256_minimum_to_const(float *a, float b, float *c, int64_t n);
+
+#endif
@@ -0,0 +1,121 @@
+#include <stdint.h>
+#include <string.h>
+#include <immintrin.h>
+#include "gemm.h"
+
+// Register-blocked micro kernels for the packed GEMM.
+// 'a' is a packed micro panel of MR rows: a[p*MR + r]
+// 'b' is a packed micro panel of NR cols: b[p*NR + j]
+// C[MR x NR] += A * B, C is row-major with the leading dim 'ldc'.
+// Kernels are compiled for the specific instruction set, the caller chooses one at runtime.
+
+#define ROW_256(r)                                   \
+    {                                                \
+        __m256 ar = _mm256_broadcast_ss(a + r);      \
+        c##r##0 = _mm256_fmadd_ps(ar, b0, c##r##0);  \
+        c##r##1 = _mm256_fmadd_ps(ar, b1, c##r##1);  \
+    }
+
+#define STORE_256(r)                                                                     \
+    {                                                                                    \
+        float *cr = c + r * ldc;                                                         \
+        _mm256_storeu_ps(cr, _mm256_add_ps(_mm256_loadu_ps(cr), c##r##0));               \
+        _mm256_storeu_ps(cr + 8, _mm256_add_ps(_mm256_loadu_ps(cr + 8), c##r##1));       \
+    }
+
+__attribute__((target("avx2,fma"))) static void kernel_6x16(int64_t kc, const float *a, const float *b, float *c, int64_t ldc)
+{
+    __m256 c00 = _mm256_setzero_ps(), c01 = _mm256_setzero_ps();
+    __m256 c10 = _mm256_setzero_ps(), c11 = _mm256_setzero_ps();
+    __m256 c20 = _mm256_setzero_ps(), c21 = _mm256_setzero_ps();
+    __m256 c30 = _mm256_setzero_ps(), c31 = _mm256_setzero_ps();
+    __m256 c40 = _mm256_setzero_ps(), c41 = _mm256_setzero_ps();
+    __m256 c50 = _mm256_setzero_ps(), c51 = _mm256_setzero_ps();
+    for (int64_t p = 0; p < kc; p++)
+    {
+        __m256 b0 = _mm256_loadu_ps(b);
+        __m256 b1 = _mm256_loadu_ps(b + 8);
+        ROW_256(0) ROW_256(1) ROW_256(2) ROW_256(3) ROW_256(4) ROW_256(5)
+        a += GEMM_MR;
+        b += GEMM_NR_256;
+    }
+    STORE_256(0) STORE_256(1) STORE_256(2) STORE_256(3) STORE_256(4) STORE_256(5)
+}
+
+#define ROW_512(r)                                   \
+    {                                                \
+        __m512 ar = _mm512_set1_ps(a[r]);            \
+        c##r##0 = _mm512_fmadd_ps(ar, b0, c##r##0);  \
+        c##r##1 = _mm512_fmadd_ps(ar, b1, c##r##1);  \
+    }
+
+#define STORE_512(r)                                                                     \
+    {                                                                                    \
+        float *cr = c + r * ldc;                                                         \
+        _mm512_storeu_ps(cr, _mm512_add_ps(_mm512_loadu_ps(cr), c##r##0));               \
+        _mm512_storeu_ps(cr + 16, _mm512_add_ps(_mm512_loadu_ps(cr + 16), c##r##1));     \
+    }
+
+__attribute__((target("avx512f"))) static void kernel_6x32(int64_t kc, const float *a, const float *b, float *c, int64_t ldc)
+{
+    __m512 c00 = _mm512_setzero_ps(), c01 = _mm512_setzero_ps();
+    __m512 c10 = _mm512_setzero_ps(), c11 = _mm512_setzero_ps();
+    __m512 c20 = _mm512_setzero_ps(), c21 = _mm512_setzero_ps();
+    __m512 c30 = _mm512_setzero_ps(), c31 = _mm512_setzero_ps();
+    __m512 c40 = _mm512_setzero_ps(), c41 = _mm512_setzero_ps();
+    __m512 c50 = _mm512_setzero_ps(), c51 = _mm512_setzero_ps();
+    for (int64_t p = 0; p < kc; p++)
+    {
+        __m512 b0 = _mm512_loadu_ps(b);
+        __m512 b1 = _mm512_loadu_ps(b + 16);
+        ROW_512(0) ROW_512(1) ROW_512(2) ROW_512(3) ROW_512(4) ROW_512(5)
+        a += GEMM_MR;
+        b += GEMM_NR_512;
+    }
+    STORE_512(0) STORE_512(1) STORE_512(2) STORE_512(3) STORE_512(4) STORE_512(5)
+}
+
+typedef void (*micro_kernel)(int64_t kc, const float *a, const float *b, float *c, int64_t ldc);
+
+// runs the micro kernel over all micro panels of the packed (mc x kc) A block and (kc x nc) B block.
+// Panels are zero padded, so edge tiles are computed into a temporary tile
+// and only the valid part is added to C
+static void gemm_macro(micro_kernel kernel, int64_t nr, float *a, float *b, float *c, int64_t mc, int64_t nc, int64_t kc, int64_t ldc)
+{
+    float tile[GEMM_MR * GEMM_NR_512];
+    for (int64_t jr = 0; jr < nc; jr += nr)
+    {
+        int64_t n = nc - jr < nr ? nc - jr : nr;
+        for (int64_t ir = 0; ir < mc; ir += GEMM_MR)
+        {
+            int64_t m = mc - ir < GEMM_MR ? mc - ir : GEMM_MR;
+            float *a_panel = a + ir * kc;
+            float *b_panel = b + jr * kc;
+            float *c_tile = c + ir * ldc + jr;
+            if (m == GEMM_MR && n == nr)
+            {
+                kernel(kc, a_panel, b_panel, c_tile, ldc);
+                continue;
+            }
+            memset(tile, 0, sizeof(tile));
+            kernel(kc, a_panel, b_panel, tile, nr);
+            for (int64_t i = 0; i < m; i++)
+            {
+                for (int64_t j = 0; j < n; j++)
+                {
+                    c_tile[i * ldc + j] += tile[i * nr + j];
+                }
+            }
+        }
+    }
+}
+
+void _mm256_gemm_macro(float *a, float *b, float *c, int64_t mc, int64_t nc, int64_t kc, int64_t ldc)
+{
+    gemm_macro(kernel_6x16, GEMM_NR_256, a, b, c, mc, nc, kc, ldc);
+}
+
+void _mm512_gemm_macro(float *a, float *b, float *c, int64_t mc, int64_t nc, int64_t kc, int64_t ldc)
+{
+    gemm_macro(kernel_6x32, GEMM_NR_512, a, b, c, mc, nc, kc, ldc);
+}
@@ -0,0 +1,30 @@
+package src
+
+/*
+#include "gemm.h"
+*/
+import "C"
+import "unsafe"
+
+// sizes of the micro tiles computed by the GEMM kernels
+const (
+	GEMM_MR     = int(C.GEMM_MR)
+	GEMM_NR_256 = int(C.GEMM_NR_256)
+	GEMM_NR_512 = int(C.GEMM_NR_512)
+)
+
+// computes C[mc x nc] += A * B for the packed blocks, see internal.GemmMatx.
+// 'c' starts at the first element of the block and has the leading dim 'ldc'
+func Gemm_macro_mm256(a, b, c []float32, mc, nc, kc, ldc int) {
+	C._mm256_gemm_macro(
+		(*C.float)(unsafe.Pointer(&a[0])), (*C.float)(unsafe.Pointer(&b[0])), (*C.float)(unsafe.Pointer(&c[0])),
+		C.longlong(mc), C.longlong(nc), C.longlong(kc), C.longlong(ldc),
+	)
+}
+
+func Gemm_macro_mm512(a, b, c []float32, mc, nc, kc, ldc int) {
+	C._mm512_gemm_macro(
+		(*C.float)(unsafe.Pointer(&a[0])), (*C.float)(unsafe.Pointer(&b[0])), (*C.float)(unsafe.Pointer(&c[0])),
+		C.longlong(mc), C.longlong(nc), C.longlong(kc), C.longlong(ldc),
+	)
+}
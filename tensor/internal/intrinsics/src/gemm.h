@@ -0,0 +1,12 @@
+#ifndef GEMM_H
+#define GEMM_H
+#include <stdint.h>
+
+#define GEMM_MR 6
+#define GEMM_NR_256 16
+#define GEMM_NR_512 32
+
+void _mm256_gemm_macro(float *a, float *b, float *c, int64_t mc, int64_t nc, int64_t kc, int64_t ldc);
+void _mm512_gemm_macro(float *a, float *b, float *c, int64_t mc, int64_t nc, int64_t kc, int64_t ldc);
+
+#endif
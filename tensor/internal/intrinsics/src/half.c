@@ -0,0 +1,93 @@
+#include <stdint.h>
+#include <string.h>
+#include <immintrin.h>
+#include <omp.h>
+#include "half.h"
+
+// the kernels are compiled for their own targets, so the package flags stay the same
+
+__attribute__((target("avx,f16c"))) void _mm256_f16_to_f32(uint16_t *a, float *c, int64_t n)
+{
+    int epoch = n / 8;
+    int remain = n % 8;
+
+    #pragma omp parallel for
+    for (int i = 0; i < epoch; i++)
+    {
+        __m128i v = _mm_loadu_si128((__m128i *)(a + i * 8));
+        _mm256_storeu_ps(c + i * 8, _mm256_cvtph_ps(v));
+    }
+    int offset = epoch * 8;
+    for (int i = 0; i < remain; i++)
+    {
+        c[offset + i] = _cvtsh_ss(a[offset + i]);
+    }
+}
+
+__attribute__((target("avx,f16c"))) void _mm256_f32_to_f16(float *a, uint16_t *c, int64_t n)
+{
+    int epoch = n / 8;
+    int remain = n % 8;
+
+    #pragma omp parallel for
+    for (int i = 0; i < epoch; i++)
+    {
+        __m256 v = _mm256_loadu_ps(a + i * 8);
+        __m128i h = _mm256_cvtps_ph(v, _MM_FROUND_TO_NEAREST_INT | _MM_FROUND_NO_EXC);
+        _mm_storeu_si128((__m128i *)(c + i * 8), h);
+    }
+    int offset = epoch * 8;
+    for (int i = 0; i < remain; i++)
+    {
+        c[offset + i] = _cvtss_sh(a[offset + i], _MM_FROUND_TO_NEAREST_INT | _MM_FROUND_NO_EXC);
+    }
+}
+
+// bfloat16 is the upper half of a float32
+__attribute__((target("avx512f"))) void _mm512_bf16_to_f32(uint16_t *a, float *c, int64_t n)
+{
+    int epoch = n / 16;
+    int remain = n % 16;
+
+    #pragma omp parallel for
+    for (int i = 0; i < epoch; i++)
+    {
+        __m256i v = _mm256_loadu_si256((__m256i *)(a + i * 16));
+        __m512i w = _mm512_slli_epi32(_mm512_cvtepu16_epi32(v), 16);
+        _mm512_storeu_ps(c + i * 16, _mm512_castsi512_ps(w));
+    }
+    int offset = epoch * 16;
+    for (int i = 0; i < remain; i++)
+    {
+        uint32_t bits = (uint32_t)a[offset + i] << 16;
+        memcpy(c + offset + i, &bits, sizeof(bits));
+    }
+}
+
+// rounds to the nearest even, NaNs stay NaN
+__attribute__((target("avx512f,avx512bf16"))) void _mm512_f32_to_bf16(float *a, uint16_t *c, int64_t n)
+{
+    int epoch = n / 16;
+    int remain = n % 16;
+
+    #pragma omp parallel for
+    for (int i = 0; i < epoch; i++)
+    {
+        __m512 v = _mm512_loadu_ps(a + i * 16);
+        __m256bh h = _mm512_cvtneps_pbh(v);
+        _mm256_storeu_si256((__m256i *)(c + i * 16), (__m256i)h);
+    }
+    int offset = epoch * 16;
+    for (int i = 0; i < remain; i++)
+    {
+        uint32_t bits;
+        memcpy(&bits, a + offset + i, sizeof(bits));
+        if ((bits & 0x7fffffff) > 0x7f800000)
+        {
+            c[offset + i] = (uint16_t)((bits >> 16) | 0x40);
+            continue;
+        }
+        bits += 0x7fff + ((bits >> 16) & 1);
+        c[offset + i] = (uint16_t)(bits >> 16);
+    }
+}
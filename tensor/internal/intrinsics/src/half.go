@@ -0,0 +1,40 @@
+package src
+
+/*
+#include "half.h"
+*/
+import "C"
+import (
+	"gograd/tensor/types"
+	"unsafe"
+)
+
+// conversions of 16-bit floats. The caller checks the cpu support
+
+func F16_to_f32_mm256(a []types.Float16, c []float32) {
+	if len(a) == 0 {
+		return
+	}
+	C._mm256_f16_to_f32((*C.uint16_t)(unsafe.Pointer(&a[0])), (*C.float)(unsafe.Pointer(&c[0])), C.longlong(len(a)))
+}
+
+func F32_to_f16_mm256(a []float32, c []types.Float16) {
+	if len(a) == 0 {
+		return
+	}
+	C._mm256_f32_to_f16((*C.float)(unsafe.Pointer(&a[0])), (*C.uint16_t)(unsafe.Pointer(&c[0])), C.longlong(len(a)))
+}
+
+func Bf16_to_f32_mm512(a []types.BFloat16, c []float32) {
+	if len(a) == 0 {
+		return
+	}
+	C._mm512_bf16_to_f32((*C.uint16_t)(unsafe.Pointer(&a[0])), (*C.float)(unsafe.Pointer(&c[0])), C.longlong(len(a)))
+}
+
+func F32_to_bf16_mm512(a []float32, c []types.BFloat16) {
+	if len(a) == 0 {
+		return
+	}
+	C._mm512_f32_to_bf16((*C.float)(unsafe.Pointer(&a[0])), (*C.uint16_t)(unsafe.Pointer(&c[0])), C.longlong(len(a)))
+}
@@ -0,0 +1,11 @@
+#ifndef HALF_H
+#define HALF_H
+#include <stdint.h>
+
+// conversions between float32 and 16-bit floats. Float16 requires F16C, BFloat16 requires AVX512F (and AVX512-BF16 to round)
+void _mm256_f16_to_f32(uint16_t *a, float *c, int64_t n);
+void _mm256_f32_to_f16(float *a, uint16_t *c, int64_t n);
+void _mm512_bf16_to_f32(uint16_t *a, float *c, int64_t n);
+void _mm512_f32_to_bf16(float *a, uint16_t *c, int64_t n);
+
+#endif
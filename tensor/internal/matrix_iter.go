@@ -30,12 +30,42 @@ func _traverse[T types.TensorType](
 
 // general traversal algorithm for ND tensor with any memory contiguity.
 //
-// As the algorithm walks through it stores its progress to 'out' tensor - i.e. makes the first tensor contiguous
+// As the algorithm walks through it stores its progress to 'out' tensor - i.e. makes the first tensor contiguous.
+// 'offset' is the position of the first element in 'a'
 func TraverseAsContiguousND[T types.TensorType](a, out []T,
-	a_strides []int, a_shape types.Shape,
+	a_strides []int, a_shape types.Shape, offset int,
 ) {
 	i := 0
-	_traverse(a, out, a_strides, a_shape, 0, 0, &i)
+	_traverse(a, out, a_strides, a_shape, 0, offset, &i)
+}
+
+func _traverse_back[T types.TensorType](
+	a, out []T,
+	out_strides []int, out_shape types.Shape,
+	axis, step int, flat *int,
+) {
+	stride := out_strides[axis]
+	dim := int(out_shape[axis])
+
+	if axis+1 == len(out_strides) {
+		for i := 0; i < dim; i++ {
+			out[step+i*stride] = a[*flat]
+			*flat += 1
+		}
+		return
+	}
+	for i := 0; i < dim; i++ {
+		_traverse_back(a, out, out_strides, out_shape, axis+1, step+i*stride, flat)
+	}
+}
+
+// reverse of TraverseAsContiguousND:
+// walks through contiguous 'a' and writes its elements into 'out' with any memory layout
+func TraverseFromContiguousND[T types.TensorType](a, out []T,
+	out_strides []int, out_shape types.Shape, offset int,
+) {
+	i := 0
+	_traverse_back(a, out, out_strides, out_shape, 0, offset, &i)
 }
 
 func TraverseAsContiguous2D[T types.TensorType](a, out []T, ashape types.Shape) {
@@ -80,7 +110,7 @@ func Parallel[T types.TensorType](
 // sets specific value to []T buffer using loop unrolling opt.
 func Fill_data_loop[T types.TensorType](buffer []T, value T) {
 	lb := len(buffer)
-	for i := 0; i < lb/8; i += 8 {
+	for i := 0; i < lb-lb%8; i += 8 {
 		buffer[i] = value
 		buffer[i+1] = value
 		buffer[i+2] = value
@@ -98,7 +128,7 @@ func Fill_data_loop[T types.TensorType](buffer []T, value T) {
 func Convert_type_loop[OLD_T, NEW_T types.TensorType](data []OLD_T, out_data []NEW_T) {
 	lb := len(data)
 	n := 8
-	for i := 0; i < lb/n; i += n {
+	for i := 0; i < lb-lb%n; i += n {
 		out_data[i] = NEW_T(data[i])
 		out_data[i+1] = NEW_T(data[i+1])
 		out_data[i+2] = NEW_T(data[i+2])
@@ -115,7 +145,7 @@ func Convert_type_loop[OLD_T, NEW_T types.TensorType](data []OLD_T, out_data []N
 
 func Transpose_cont2D_loop[T types.TensorType](data, transposed []T, i, cols, rows int) {
 	i_cols := i * cols
-	for j := 0; j < cols/8; j += 8 {
+	for j := 0; j < cols-cols%8; j += 8 {
 		i_cols_j := i_cols + j
 		transposed[j*rows+i] = data[i_cols_j]
 		transposed[(j+1)*rows+i] = data[i_cols_j+1]
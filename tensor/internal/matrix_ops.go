@@ -90,8 +90,8 @@ func RunSimdImplUnary[T types.TensorType](a, out []T, impl func([]T, []T)) {
 	Parallel(len(a), chunk, a, nil, makeOutMat(out, len(a)))
 }
 
-func Dot[T types.TensorType](a, b []float32) float32 {
-	var c float32
+func Dot[T types.TensorType](a, b []T) T {
+	var c T
 	for i := 0; i < len(a); i++ {
 		c += a[i] * b[i]
 	}
@@ -191,32 +191,216 @@ func SumMatx[T types.TensorType](a, out []T) {
 	Parallel(len(a), sum_chunk, a, nil, makeOutMat(out, len(a)))
 }
 
-func SumAxisMatx[T types.TensorType](data, out []T, shape types.Shape, axis int) {
-	inner_step := int(shape[axis])
-	outer_step := len(data) / inner_step
+// reduces data viewed as (outer, dim, inner) along the middle dim. Out has shape (outer, inner)
+func ReduceAxisMatx[T types.TensorType](data, out []T, outer, dim, inner int, atomic func(T, T) T) {
+	if inner == 1 {
+		// every output is a reduction of a contiguous row
+		Parallel(outer,
+			func(start, end int, data, dummy, out []T, mu *sync.Mutex) {
+				for o := start; o < end; o++ {
+					row := data[o*dim : (o+1)*dim]
+					acc := row[0]
+					for _, v := range row[1:] {
+						acc = atomic(acc, v)
+					}
+					out[o] = acc
+				}
+			}, data, nil, out)
+		return
+	}
+	// split by inner columns, so every goroutine walks over contiguous memory
+	Parallel(inner,
+		func(start, end int, data, dummy, out []T, mu *sync.Mutex) {
+			if start >= end {
+				return
+			}
+			for o := 0; o < outer; o++ {
+				out_row := out[o*inner+start : o*inner+end]
+				copy(out_row, data[o*dim*inner+start:o*dim*inner+end])
+				for k := 1; k < dim; k++ {
+					offset := (o*dim + k) * inner
+					for i, v := range data[offset+start : offset+end] {
+						out_row[i] = atomic(out_row[i], v)
+					}
+				}
+			}
+		}, data, nil, out)
+}
+
+// inputs with fewer independent lanes than cpus and at least this many elements per lane
+// are scanned in parallel blocks
+const parallel_scan_min_dim = 1 << 14
 
-	var stride_0 int = int(shape[1])
-	var stride_1 int = 1
-	if axis == 1 {
-		stride_0 = 1
-		stride_1 = int(shape[1])
+// inclusive scan of data viewed as (outer, dim, inner) along the middle dim. Out has the same shape.
+// The atomic must be associative
+func ScanAxisMatx[T types.TensorType](data, out []T, outer, dim, inner int, atomic func(T, T) T) {
+	if outer*dim*inner == 0 {
+		return
 	}
-	Parallel(outer_step,
+	if outer*inner < numCPU && dim >= parallel_scan_min_dim {
+		for o := 0; o < outer; o++ {
+			block := o * dim * inner
+			blockScan(data[block:block+dim*inner], out[block:block+dim*inner], dim, inner, atomic)
+		}
+		return
+	}
+	if inner == 1 {
+		Parallel(outer,
+			func(start, end int, data, dummy, out []T, mu *sync.Mutex) {
+				for o := start; o < end; o++ {
+					row, out_row := data[o*dim:(o+1)*dim], out[o*dim:(o+1)*dim]
+					acc := row[0]
+					out_row[0] = acc
+					for k, v := range row[1:] {
+						acc = atomic(acc, v)
+						out_row[k+1] = acc
+					}
+				}
+			}, data, nil, out)
+		return
+	}
+	// split by inner columns, every goroutine scans its columns row by row
+	Parallel(inner,
 		func(start, end int, data, dummy, out []T, mu *sync.Mutex) {
-			for i := start; i < end; i++ {
-				inner_sum := T(0)
-				i_stride := i * stride_1
-				for j := 0; j < inner_step; j++ {
-					// TODO swap loops , improve cache locality => enable avx
-					inner_sum += data[j*stride_0+i_stride]
+			if start >= end {
+				return
+			}
+			for o := 0; o < outer; o++ {
+				base := o * dim * inner
+				copy(out[base+start:base+end], data[base+start:base+end])
+				for k := 1; k < dim; k++ {
+					prev := out[base+(k-1)*inner+start : base+(k-1)*inner+end]
+					row := data[base+k*inner+start : base+k*inner+end]
+					out_row := out[base+k*inner+start : base+k*inner+end]
+					for i, v := range row {
+						out_row[i] = atomic(prev[i], v)
+					}
 				}
-				// mu.Lock()
-				// defer mu.Unlock()
-				out[i] += inner_sum
 			}
 		}, data, nil, out)
 }
 
+// scans (dim, inner) data along dim in three passes:
+// every block of rows is scanned independently, then the carries of the previous blocks are
+// combined sequentially and finally added to every block in parallel
+func blockScan[T types.TensorType](data, out []T, dim, inner int, atomic func(T, T) T) {
+	n_blocks := min(numCPU, dim)
+	block_size := (dim + n_blocks - 1) / n_blocks
+	scan_rows := func(from, to int) {
+		copy(out[from*inner:(from+1)*inner], data[from*inner:(from+1)*inner])
+		for k := from + 1; k < to; k++ {
+			for i := 0; i < inner; i++ {
+				out[k*inner+i] = atomic(out[(k-1)*inner+i], data[k*inner+i])
+			}
+		}
+	}
+	var wg sync.WaitGroup
+	for from := 0; from < dim; from += block_size {
+		wg.Add(1)
+		go func(from int) {
+			defer wg.Done()
+			scan_rows(from, min(from+block_size, dim))
+		}(from)
+	}
+	wg.Wait()
+
+	// carry of the block is the scanned value of all rows before it
+	carries := make([]T, 0, n_blocks*inner)
+	carry := out[(block_size-1)*inner : block_size*inner]
+	for from := block_size; from < dim; from += block_size {
+		carries = append(carries, carry...)
+		last := min(from+block_size, dim) - 1
+		next := make([]T, inner)
+		for i := range next {
+			next[i] = atomic(carry[i], out[last*inner+i])
+		}
+		carry = next
+	}
+	for b := 0; b*inner < len(carries); b++ {
+		wg.Add(1)
+		go func(b int) {
+			defer wg.Done()
+			carry := carries[b*inner : (b+1)*inner]
+			from := (b + 1) * block_size
+			for k := from; k < min(from+block_size, dim); k++ {
+				for i := 0; i < inner; i++ {
+					out[k*inner+i] = atomic(carry[i], out[k*inner+i])
+				}
+			}
+		}(b)
+	}
+	wg.Wait()
+}
+
+// running positions of the 'best' elements of data viewed as (outer, dim, inner) along the middle dim.
+// 'better(a, b)' reports whether a should replace b
+func ArgScanAxisMatx[T types.TensorType](data []T, out []int, outer, dim, inner int, better func(T, T) bool) {
+	if outer*dim*inner == 0 {
+		return
+	}
+	var wg sync.WaitGroup
+	chunk_size := (outer*inner + numCPU - 1) / numCPU
+	for start := 0; start < outer*inner; start += chunk_size {
+		end := min(start+chunk_size, outer*inner)
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for j := start; j < end; j++ {
+				o, i := j/inner, j%inner
+				base := o*dim*inner + i
+				best, best_k := data[base], 0
+				for k := 0; k < dim; k++ {
+					if v := data[base+k*inner]; better(v, best) {
+						best, best_k = v, k
+					}
+					out[base+k*inner] = best_k
+				}
+			}
+		}(start, end)
+	}
+	wg.Wait()
+}
+
+// finds positions of the 'best' elements of data viewed as (outer, dim, inner) along the middle dim.
+// 'better(a, b)' reports whether a should replace b. The first found element wins on ties
+func ArgReduceAxisMatx[T types.TensorType](data []T, out []int, outer, dim, inner int, better func(T, T) bool) {
+	var wg sync.WaitGroup
+	chunk_size := (outer*inner + numCPU - 1) / numCPU
+	for start := 0; start < outer*inner; start += chunk_size {
+		end := min(start+chunk_size, outer*inner)
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for j := start; j < end; j++ {
+				o, i := j/inner, j%inner
+				base := o * dim * inner
+				best, best_k := data[base+i], 0
+				for k := 1; k < dim; k++ {
+					v := data[base+k*inner+i]
+					if better(v, best) {
+						best, best_k = v, k
+					}
+				}
+				out[j] = best_k
+			}
+		}(start, end)
+	}
+	wg.Wait()
+}
+
+func ProdMatx[T types.TensorType](a, out []T) {
+	prod_chunk := func(start, end int, a, dummy, out []T, mu *sync.Mutex) {
+		var chunk_prod T = 1
+		for i := start; i < end; i++ {
+			chunk_prod *= a[i]
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		out[0] *= chunk_prod
+	}
+	Parallel(len(a), prod_chunk, a, nil, makeOutMat(out, len(a)))
+}
+
 func MaxMatx[T types.TensorType](a, out []T) {
 	max_chunk := func(start, end int, a, dummy, out []T, mu *sync.Mutex) {
 		var _max T = a[0]
@@ -283,42 +467,47 @@ func SoftmaxMatx[T types.TensorType](a, out []T, strides []int) {
 }
 
 // matmul
-func MatMulMatx(
-	a_data, b_data, out_data []float32,
-	a_shape, b_shape types.Shape,
-	a_strides, b_strides, out_strides []int,
-	dot_impl func([]float32, []float32) float32,
+// Batched matrix multiplication where every output element is a separate dot product.
+// See GemmMatx for the general case, this one is used for matrix-vector products.
+//
+// a is (a_batch, M, K), b is transposed (b_batch, N, K), out is (batch, M, N). All are row-major.
+// Note that with N=1 the (K,1) matrix and its transposed (1,K) share the same layout.
+// a_batches[i] and b_batches[i] are the batches of a and b used for the i-th batch of the output,
+// so the broadcasted batches are not copied.
+// The work is split into (batch, row block, col block) tasks which are shared by all goroutines.
+func MatMulMatx[T types.TensorType](
+	a_data, b_data, out_data []T,
+	m, k, n int,
+	a_batches, b_batches []int,
+	dot_impl func([]T, []T) T,
 ) {
-	a_dim0 := int(a_shape[0])
-	b_dim0 := int(b_shape[0])
-	out_stride0 := out_strides[0]
-	a_stride0 := a_strides[0]
-	b_stride0 := b_strides[0]
-
 	block_size := 64
+	row_blocks := (m + block_size - 1) / block_size
+	col_blocks := (n + block_size - 1) / block_size
+	blocks := row_blocks * col_blocks
+	n_tasks := len(a_batches) * blocks
+	workers := min(numCPU, n_tasks)
 
 	var wg sync.WaitGroup
-
-	for i := 0; i < a_dim0; i += block_size {
-		for j := 0; j < b_dim0; j += block_size {
-			wg.Add(1)
-			go func(i, j int) {
-				defer wg.Done()
-				for bi := 0; bi < block_size; bi++ {
-					for bj := 0; bj < block_size; bj++ {
-						row := i + bi
-						col := j + bj
-						if row >= a_dim0 || col >= b_dim0 {
-							continue
-						}
-						out_data[out_stride0*row+col] = dot_impl(
-							a_data[a_stride0*row:a_stride0*(row+1)],
-							b_data[b_stride0*col:b_stride0*(col+1)],
-						)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(w int) {
+			defer wg.Done()
+			for task := w; task < n_tasks; task += workers {
+				batch := task / blocks
+				i := (task % blocks) / col_blocks * block_size
+				j := (task % blocks) % col_blocks * block_size
+				a := a_data[a_batches[batch]*m*k : (a_batches[batch]+1)*m*k]
+				b := b_data[b_batches[batch]*n*k : (b_batches[batch]+1)*n*k]
+				out := out_data[batch*m*n : (batch+1)*m*n]
+				for row := i; row < min(i+block_size, m); row++ {
+					a_row := a[row*k : (row+1)*k]
+					for col := j; col < min(j+block_size, n); col++ {
+						out[row*n+col] = dot_impl(a_row, b[col*k:(col+1)*k])
 					}
 				}
-			}(i, j)
-		}
+			}
+		}(w)
 	}
 	wg.Wait()
 }
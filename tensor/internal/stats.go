@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"gograd/tensor/types"
+	"sync"
+)
+
+// statistics kernels. Values are accumulated in float64, so small float types don't lose precision
+
+// rows shorter than this are summed sequentially
+const pairwise_block = 128
+
+// long rows of the few lanes are split between goroutines
+const parallel_lane_min_dim = 1 << 14
+
+// pairwise sum of fn(v) of the values. The error grows as O(log n) instead of O(n)
+func pairwise_sum[T types.TensorType](values []T, fn func(T) float64) float64 {
+	if len(values) <= pairwise_block {
+		var sum float64
+		for _, v := range values {
+			sum += fn(v)
+		}
+		return sum
+	}
+	half := len(values) / 2
+	return pairwise_sum(values[:half], fn) + pairwise_sum(values[half:], fn)
+}
+
+// parallel pairwise sum of a long row
+func parallel_sum[T types.TensorType](values []T, fn func(T) float64) float64 {
+	chunk_size := (len(values) + numCPU - 1) / numCPU
+	partial := make([]float64, numCPU)
+	var wg sync.WaitGroup
+	for c := 0; c*chunk_size < len(values); c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			partial[c] = pairwise_sum(values[c*chunk_size:min((c+1)*chunk_size, len(values))], fn)
+		}(c)
+	}
+	wg.Wait()
+	return pairwise_sum(partial, func(v float64) float64 { return v })
+}
+
+// sums fn(lane, v) of data viewed as (outer, dim, inner) along the middle dim in float64.
+// lane = o*inner + i is the index in out. Rows are summed pairwise,
+// columns are summed in blocks of rows which are added to the total
+func SumAxisFloat64[T types.TensorType](data []T, out []float64, outer, dim, inner int, fn func(int, T) float64) {
+	if inner == 1 {
+		if outer < numCPU && dim >= parallel_lane_min_dim {
+			for o := 0; o < outer; o++ {
+				out[o] = parallel_sum(data[o*dim:(o+1)*dim], func(v T) float64 { return fn(o, v) })
+			}
+			return
+		}
+		Parallel(outer,
+			func(start, end int, data, dummy, _ []T, mu *sync.Mutex) {
+				for o := start; o < end; o++ {
+					out[o] = pairwise_sum(data[o*dim:(o+1)*dim], func(v T) float64 { return fn(o, v) })
+				}
+			}, data, nil, nil)
+		return
+	}
+	Parallel(inner,
+		func(start, end int, data, dummy, _ []T, mu *sync.Mutex) {
+			if start >= end {
+				return
+			}
+			block := make([]float64, end-start)
+			for o := 0; o < outer; o++ {
+				total := out[o*inner+start : o*inner+end]
+				clear(total)
+				for from := 0; from < dim; from += pairwise_block {
+					clear(block)
+					for k := from; k < min(from+pairwise_block, dim); k++ {
+						row := data[(o*dim+k)*inner+start : (o*dim+k)*inner+end]
+						for i, v := range row {
+							block[i] += fn(o*inner+start+i, v)
+						}
+					}
+					for i, v := range block {
+						total[i] += v
+					}
+				}
+			}
+		}, data, nil, nil)
+}
+
+// mean and the sum of squared deviations from the mean along the middle dim of data viewed as (outer, dim, inner).
+// Uses two passes, which is stable for data with a large mean
+func MomentsAxisMatx[T types.TensorType](data []T, mean, m2 []float64, outer, dim, inner int) {
+	SumAxisFloat64(data, mean, outer, dim, inner, func(_ int, v T) float64 { return float64(v) })
+	for j := range mean {
+		mean[j] /= float64(dim)
+	}
+	SumAxisFloat64(data, m2, outer, dim, inner, func(lane int, v T) float64 {
+		d := float64(v) - mean[lane]
+		return d * d
+	})
+}
+
+// calls fn with every lane of data viewed as (outer, dim, inner) copied into a float64 buffer.
+// Lanes are processed in parallel, the buffer is reused by the goroutine
+func ForEachLaneFloat64[T types.TensorType](data []T, outer, dim, inner int, fn func(lane int, values []float64)) {
+	Parallel(outer*inner,
+		func(start, end int, data, dummy, _ []T, mu *sync.Mutex) {
+			values := make([]float64, dim)
+			for j := start; j < end; j++ {
+				o, i := j/inner, j%inner
+				for k := range values {
+					values[k] = float64(data[(o*dim+k)*inner+i])
+				}
+				fn(j, values)
+			}
+		}, data, nil, nil)
+}
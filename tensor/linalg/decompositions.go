@@ -0,0 +1,221 @@
+package linalg
+
+import (
+	"errors"
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"math"
+	"slices"
+)
+
+// in-place LU factorization with partial pivoting of the (n,n) matrix.
+// L (without the unit diagonal) and U are stored in the same matrix,
+// perm[i] is the row of the original matrix moved to the row i.
+// Returns the sign of the permutation and whether the matrix is singular
+func lu_factor[T types.Float](lu []T, n int, perm []int) (T, bool) {
+	for i := range perm {
+		perm[i] = i
+	}
+	var sign T = 1
+	singular := false
+	for j := 0; j < n; j++ {
+		// the largest absolute value in the column is the pivot
+		pivot := j
+		for i := j + 1; i < n; i++ {
+			if abs(lu[i*n+j]) > abs(lu[pivot*n+j]) {
+				pivot = i
+			}
+		}
+		if pivot != j {
+			for c := 0; c < n; c++ {
+				lu[j*n+c], lu[pivot*n+c] = lu[pivot*n+c], lu[j*n+c]
+			}
+			perm[j], perm[pivot] = perm[pivot], perm[j]
+			sign = -sign
+		}
+		d := lu[j*n+j]
+		if d == 0 {
+			singular = true
+			continue
+		}
+		row_j := lu[j*n+j+1 : (j+1)*n]
+		for i := j + 1; i < n; i++ {
+			f := lu[i*n+j] / d
+			lu[i*n+j] = f
+			if f == 0 {
+				continue
+			}
+			row_i := lu[i*n+j+1 : (i+1)*n]
+			for c, v := range row_j {
+				row_i[c] -= f * v
+			}
+		}
+	}
+	return sign, singular
+}
+
+// LU decomposition with partial pivoting of square matrices: A = P @ L @ U,
+// where P is a permutation matrix, L is lower triangular with the unit diagonal and U is upper triangular.
+//
+// Example:
+// a = [
+// [1,2],
+// [3,4]]
+// p, l, u := LU(a) => [[0,1],[1,0]], [[1,0],[0.333,1]], [[3,4],[0,0.667]]
+func LU[T types.Float](a *tensor.Tensor[T]) (p, l, u *tensor.Tensor[T]) {
+	if a.Err != nil {
+		return errTensor[T](a.Err), errTensor[T](a.Err), errTensor[T](a.Err)
+	}
+	batch, n, err := square_shape(a.Shape())
+	if err != nil {
+		return errTensor[T](err), errTensor[T](err), errTensor[T](err)
+	}
+	data := slices.Clone(a.Data())
+	size := n * n
+	p_data := make([]T, len(data))
+	l_data := make([]T, len(data))
+	u_data := make([]T, len(data))
+	perm := make([]int, n)
+	for k := 0; k < batch_size(batch); k++ {
+		lu := data[k*size : (k+1)*size]
+		lu_factor(lu, n, perm)
+		p_mat, l_mat, u_mat := p_data[k*size:(k+1)*size], l_data[k*size:(k+1)*size], u_data[k*size:(k+1)*size]
+		for i := 0; i < n; i++ {
+			p_mat[perm[i]*n+i] = 1
+			l_mat[i*n+i] = 1
+			copy(l_mat[i*n:i*n+i], lu[i*n:i*n+i])
+			copy(u_mat[i*n+i:(i+1)*n], lu[i*n+i:(i+1)*n])
+		}
+	}
+	shape := with_matrix(batch, n, n)
+	return tensor.CreateTensorNoCopy(p_data, shape),
+		tensor.CreateTensorNoCopy(l_data, shape),
+		tensor.CreateTensorNoCopy(u_data, shape)
+}
+
+// Cholesky decomposition of symmetric positive-definite matrices: A = L @ L.T,
+// where L is lower triangular. Only the lower triangle of A is used.
+//
+// Sets an error if a matrix is not positive-definite.
+func Cholesky[T types.Float](a *tensor.Tensor[T]) *tensor.Tensor[T] {
+	if a.Err != nil {
+		return errTensor[T](a.Err)
+	}
+	batch, n, err := square_shape(a.Shape())
+	if err != nil {
+		return errTensor[T](err)
+	}
+	data := a.Data()
+	size := n * n
+	l_data := make([]T, len(data))
+	for k := 0; k < batch_size(batch); k++ {
+		a_mat, l_mat := data[k*size:(k+1)*size], l_data[k*size:(k+1)*size]
+		for j := 0; j < n; j++ {
+			d := a_mat[j*n+j]
+			for p := 0; p < j; p++ {
+				d -= l_mat[j*n+p] * l_mat[j*n+p]
+			}
+			// negated check catches NaN as well
+			if !(d > 0) {
+				return errTensor[T](errors.New("matrix is not positive definite"))
+			}
+			d = T(math.Sqrt(float64(d)))
+			l_mat[j*n+j] = d
+			for i := j + 1; i < n; i++ {
+				s := a_mat[i*n+j]
+				for p := 0; p < j; p++ {
+					s -= l_mat[i*n+p] * l_mat[j*n+p]
+				}
+				l_mat[i*n+j] = s / d
+			}
+		}
+	}
+	return tensor.CreateTensorNoCopy(l_data, with_matrix(batch, n, n))
+}
+
+// Reduced QR decomposition using Householder reflections: A = Q @ R.
+// For A with shape (M,N) and K = min(M,N), Q has shape (M,K) with orthonormal columns
+// and R is upper triangular with shape (K,N).
+func QR[T types.Float](a *tensor.Tensor[T]) (q, r *tensor.Tensor[T]) {
+	if a.Err != nil {
+		return errTensor[T](a.Err), errTensor[T](a.Err)
+	}
+	batch, m, n, err := matrix_shape(a.Shape())
+	if err != nil {
+		return errTensor[T](err), errTensor[T](err)
+	}
+	k := min(m, n)
+	data := slices.Clone(a.Data())
+	q_data := make([]T, batch_size(batch)*m*k)
+	r_data := make([]T, batch_size(batch)*k*n)
+	// householder vectors
+	vs := make([][]T, k)
+	for b := 0; b < batch_size(batch); b++ {
+		w := data[b*m*n : (b+1)*m*n]
+		for j := 0; j < k; j++ {
+			vs[j] = householder(w, m, n, j)
+		}
+		r_mat := r_data[b*k*n : (b+1)*k*n]
+		for i := 0; i < k; i++ {
+			copy(r_mat[i*n+i:(i+1)*n], w[i*n+i:(i+1)*n])
+		}
+		// Q = H_0 @ ... @ H_k-1 @ I[:, :k], the reflections are applied from the last one
+		q_mat := q_data[b*m*k : (b+1)*m*k]
+		for i := 0; i < k; i++ {
+			q_mat[i*k+i] = 1
+		}
+		for j := k - 1; j >= 0; j-- {
+			reflect(vs[j], q_mat, m, k, j, 0)
+		}
+	}
+	return tensor.CreateTensorNoCopy(q_data, with_matrix(batch, m, k)),
+		tensor.CreateTensorNoCopy(r_data, with_matrix(batch, k, n))
+}
+
+// builds the householder reflection which zeroes the column j of the (m,n) matrix below the diagonal
+// and applies it to the matrix. Returns the normalized reflection vector or nil if the column is already zero
+func householder[T types.Float](w []T, m, n, j int) []T {
+	var norm T
+	for i := j; i < m; i++ {
+		norm += w[i*n+j] * w[i*n+j]
+	}
+	if norm == 0 {
+		return nil
+	}
+	alpha := T(math.Sqrt(float64(norm)))
+	if w[j*n+j] > 0 {
+		alpha = -alpha
+	}
+	v := make([]T, m-j)
+	for i := range v {
+		v[i] = w[(j+i)*n+j]
+	}
+	v[0] -= alpha
+	var v_norm T
+	for _, x := range v {
+		v_norm += x * x
+	}
+	v_norm = T(math.Sqrt(float64(v_norm)))
+	for i := range v {
+		v[i] /= v_norm
+	}
+	reflect(v, w, m, n, j, j)
+	return v
+}
+
+// applies H = I - 2 v v^T to rows [j, m) and columns [from, n) of the (m,n) matrix
+func reflect[T types.Float](v, w []T, m, n, j, from int) {
+	if v == nil {
+		return
+	}
+	for c := from; c < n; c++ {
+		var dot T
+		for i, x := range v {
+			dot += x * w[(j+i)*n+c]
+		}
+		dot *= 2
+		for i, x := range v {
+			w[(j+i)*n+c] -= dot * x
+		}
+	}
+}
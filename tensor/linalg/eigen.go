@@ -0,0 +1,169 @@
+package linalg
+
+import (
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"math"
+	"slices"
+)
+
+// maximum number of Jacobi sweeps, usually it converges in less than 10
+const max_sweeps = 100
+
+func to_float64[T types.Float](data []T) []float64 {
+	out := make([]float64, len(data))
+	for i, v := range data {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+func from_float64[T types.Float](data []float64, out []T) {
+	for i, v := range data {
+		out[i] = T(v)
+	}
+}
+
+// machine epsilon of the type
+func eps[T types.Float]() float64 {
+	var v T
+	if _, ok := any(v).(float32); ok {
+		return 0x1p-23
+	}
+	return 0x1p-52
+}
+
+// parameters of the Jacobi rotation which zeroes the off-diagonal element of the 2x2 symmetric matrix
+// [[app, apq], [apq, aqq]]
+func jacobi_rotation(app, aqq, apq float64) (c, s float64) {
+	theta := (aqq - app) / (2 * apq)
+	t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+	if theta < 0 {
+		t = -t
+	}
+	c = 1 / math.Sqrt(t*t+1)
+	return c, t * c
+}
+
+// rotates columns p and q of the (rows,cols) matrix
+func rotate_cols(a []float64, rows, cols, p, q int, c, s float64) {
+	for k := 0; k < rows; k++ {
+		akp, akq := a[k*cols+p], a[k*cols+q]
+		a[k*cols+p] = c*akp - s*akq
+		a[k*cols+q] = s*akp + c*akq
+	}
+}
+
+// eigendecomposition of the symmetric (n,n) matrix with cyclic Jacobi method.
+// a is overwritten. Returns eigenvalues in ascending order and eigenvectors as columns of v
+func jacobi_eigh(a []float64, n int) (w, v []float64) {
+	v = make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		v[i*n+i] = 1
+	}
+	for sweep := 0; sweep < max_sweeps; sweep++ {
+		rotated := false
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := a[p*n+q]
+				app, aqq := a[p*n+p], a[q*n+q]
+				if math.Abs(apq) <= 0x1p-52*math.Sqrt(math.Abs(app*aqq)) {
+					continue
+				}
+				rotated = true
+				c, s := jacobi_rotation(app, aqq, apq)
+				// A = J.T @ A @ J
+				rotate_cols(a, n, n, p, q, c, s)
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p*n+k], a[q*n+k]
+					a[p*n+k] = c*apk - s*aqk
+					a[q*n+k] = s*apk + c*aqk
+				}
+				rotate_cols(v, n, n, p, q, c, s)
+			}
+		}
+		if !rotated {
+			break
+		}
+	}
+	w = make([]float64, n)
+	for i := range w {
+		w[i] = a[i*n+i]
+	}
+	order := sorted_order(w, false)
+	return permute(w, order), permute_cols(v, n, n, order)
+}
+
+// indices which sort the values
+func sorted_order(values []float64, descending bool) []int {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		if descending {
+			i, j = j, i
+		}
+		switch {
+		case values[i] < values[j]:
+			return -1
+		case values[i] > values[j]:
+			return 1
+		}
+		return 0
+	})
+	return order
+}
+
+func permute(values []float64, order []int) []float64 {
+	out := make([]float64, len(values))
+	for i, j := range order {
+		out[i] = values[j]
+	}
+	return out
+}
+
+func permute_cols(a []float64, rows, cols int, order []int) []float64 {
+	out := make([]float64, len(a))
+	for k := 0; k < rows; k++ {
+		for i, j := range order {
+			out[k*cols+i] = a[k*cols+j]
+		}
+	}
+	return out
+}
+
+// Eigenvalues and eigenvectors of real symmetric matrices. Only the lower triangle of A is used.
+// Eigenvalues are in ascending order, eigenvectors are the columns of v, so A = v @ diag(w) @ v.T
+//
+// Example:
+// a = [
+// [2,1],
+// [1,2]]
+// w, v := EighSymmetric(a) => [1,3], [[-0.707,0.707],[0.707,0.707]]
+func EighSymmetric[T types.Float](a *tensor.Tensor[T]) (w, v *tensor.Tensor[T]) {
+	if a.Err != nil {
+		return errTensor[T](a.Err), errTensor[T](a.Err)
+	}
+	batch, n, err := square_shape(a.Shape())
+	if err != nil {
+		return errTensor[T](err), errTensor[T](err)
+	}
+	data := to_float64(a.Data())
+	w_data := make([]T, batch_size(batch)*n)
+	v_data := make([]T, len(data))
+	for k := 0; k < batch_size(batch); k++ {
+		mat := data[k*n*n : (k+1)*n*n]
+		// symmetrize from the lower triangle
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				mat[i*n+j] = mat[j*n+i]
+			}
+		}
+		w_mat, v_mat := jacobi_eigh(mat, n)
+		from_float64(w_mat, w_data[k*n:(k+1)*n])
+		from_float64(v_mat, v_data[k*n*n:(k+1)*n*n])
+	}
+	return tensor.CreateTensorNoCopy(w_data, with_matrix(batch, n)),
+		tensor.CreateTensorNoCopy(v_data, with_matrix(batch, n, n))
+}
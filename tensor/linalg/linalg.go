@@ -0,0 +1,113 @@
+// Linear algebra routines for float tensors: decompositions, solvers, inverse and determinant.
+//
+// All functions accept batches of matrices with shape (..., M, N), the leading dims are treated as batch dims.
+// Errors are returned through the Err field of the result tensors.
+package linalg
+
+import (
+	"fmt"
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"slices"
+)
+
+func errTensor[T types.Float](err error) *tensor.Tensor[T] {
+	return &tensor.Tensor[T]{Err: err}
+}
+
+// splits the shape to the batch dims and the matrix dims
+func matrix_shape(shape types.Shape) (batch types.Shape, rows, cols int, err error) {
+	if len(shape) < 2 {
+		return nil, 0, 0, fmt.Errorf("expected at least 2D tensor, got shape %v", shape)
+	}
+	n := len(shape)
+	return shape[:n-2], int(shape[n-2]), int(shape[n-1]), nil
+}
+
+// same as matrix_shape but the matrix must be square
+func square_shape(shape types.Shape) (batch types.Shape, n int, err error) {
+	batch, rows, cols, err := matrix_shape(shape)
+	if err != nil {
+		return nil, 0, err
+	}
+	if rows != cols {
+		return nil, 0, fmt.Errorf("expected square matrices, got shape %v", shape)
+	}
+	return batch, rows, nil
+}
+
+func batch_size(batch types.Shape) int {
+	size := 1
+	for _, dim := range batch {
+		size *= int(dim)
+	}
+	return size
+}
+
+func with_matrix(batch types.Shape, dims ...int) types.Shape {
+	shape := slices.Clone(batch)
+	for _, dim := range dims {
+		shape = append(shape, types.Dim(dim))
+	}
+	return shape
+}
+
+// shape for one value per matrix. 0-d scalar if there is no batch dims
+func per_matrix(batch types.Shape) types.Shape {
+	if len(batch) == 0 {
+		return types.Shape{}
+	}
+	return slices.Clone(batch)
+}
+
+// broadcasts two batch shapes like numpy
+func broadcast_batch(a, b types.Shape) (types.Shape, error) {
+	out := make(types.Shape, max(len(a), len(b)))
+	for i := range out {
+		dim_a, dim_b := types.Dim(1), types.Dim(1)
+		if j := len(a) - len(out) + i; j >= 0 {
+			dim_a = a[j]
+		}
+		if j := len(b) - len(out) + i; j >= 0 {
+			dim_b = b[j]
+		}
+		if dim_a != dim_b && dim_a != 1 && dim_b != 1 {
+			return nil, fmt.Errorf("batch dims %v and %v cannot be broadcasted", a, b)
+		}
+		out[i] = max(dim_a, dim_b)
+	}
+	return out, nil
+}
+
+// maps every batch of the broadcasted batch shape to the batch index of the operand
+func batch_indices(out_batch, batch types.Shape) []int {
+	indices := make([]int, batch_size(out_batch))
+	index := make([]int, len(out_batch))
+	for k := range indices {
+		flat, stride := 0, 1
+		for i := len(batch) - 1; i >= 0; i-- {
+			dim := int(batch[i])
+			if dim > 1 {
+				flat += index[len(out_batch)-len(batch)+i] * stride
+			}
+			stride *= dim
+		}
+		indices[k] = flat
+		// next multi-index
+		for i := len(index) - 1; i >= 0; i-- {
+			index[i]++
+			if index[i] < int(out_batch[i]) {
+				break
+			}
+			index[i] = 0
+		}
+	}
+	return indices
+}
+
+func abs[T types.Float](v T) T {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
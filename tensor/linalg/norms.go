@@ -0,0 +1,110 @@
+package linalg
+
+import (
+	"fmt"
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"math"
+)
+
+// Vector norm of all elements or along given axes: sum(|x|^ord)^(1/ord).
+// Special orders are math.Inf(1) for max(|x|), math.Inf(-1) for min(|x|) and 0 for the number of non-zero elements.
+//
+// Example:
+// a = [
+// [3,-4],
+// [0, 1]]
+// VectorNorm(a, 2, false) => [5.099]
+// VectorNorm(a, 1, false, -1) => [7, 1]
+// VectorNorm(a, math.Inf(1), true, 0) => [[3, 4]]
+func VectorNorm[T types.Float](a *tensor.Tensor[T], ord float64, keep_dims bool, axes ...int) *tensor.Tensor[T] {
+	if a.Err != nil {
+		return errTensor[T](a.Err)
+	}
+	abs_a := a.ApplyFunc(abs[T])
+	switch {
+	case math.IsInf(ord, 1):
+		return abs_a.Max(keep_dims, axes...)
+	case math.IsInf(ord, -1):
+		return abs_a.Min(keep_dims, axes...)
+	case ord == 0:
+		return a.ApplyFunc(func(v T) T {
+			if v != 0 {
+				return 1
+			}
+			return 0
+		}).Sum(keep_dims, axes...)
+	case ord == 1:
+		return abs_a.Sum(keep_dims, axes...)
+	case ord == 2:
+		return a.Mul(a).Sum(keep_dims, axes...).ApplyFunc(func(v T) T {
+			return T(math.Sqrt(float64(v)))
+		})
+	}
+	return abs_a.ApplyFunc(func(v T) T {
+		return T(math.Pow(float64(v), ord))
+	}).Sum(keep_dims, axes...).ApplyFunc(func(v T) T {
+		return T(math.Pow(float64(v), 1/ord))
+	})
+}
+
+// order of the matrix norm
+type MatrixOrd int
+
+const (
+	// sqrt of the sum of squares
+	Frobenius MatrixOrd = iota
+	// sum of singular values
+	Nuclear
+	// max of the absolute column sums
+	MatrixL1
+	// largest singular value
+	MatrixL2
+	// max of the absolute row sums
+	MatrixInf
+)
+
+// Matrix norm of the last two dims. Result has the batch shape or (..., 1, 1) if keep_dims is set.
+func MatrixNorm[T types.Float](a *tensor.Tensor[T], ord MatrixOrd, keep_dims bool) *tensor.Tensor[T] {
+	if a.Err != nil {
+		return errTensor[T](a.Err)
+	}
+	batch, _, _, err := matrix_shape(a.Shape())
+	if err != nil {
+		return errTensor[T](err)
+	}
+	var norm *tensor.Tensor[T]
+	switch ord {
+	case Frobenius:
+		norm = VectorNorm(a, 2, true, -2, -1)
+	case Nuclear:
+		norm = SingularValues(a).Sum(true, -1)
+	case MatrixL1:
+		norm = a.ApplyFunc(abs[T]).Sum(true, -2).Max(true, -1)
+	case MatrixL2:
+		norm = SingularValues(a).Max(true, -1)
+	case MatrixInf:
+		norm = a.ApplyFunc(abs[T]).Sum(true, -1).Max(true, -2)
+	default:
+		return errTensor[T](fmt.Errorf("unknown matrix norm order %v", ord))
+	}
+	if keep_dims {
+		return norm.Reshape(with_matrix(batch, 1, 1)...)
+	}
+	return norm.Reshape(per_matrix(batch)...)
+}
+
+// Condition number of matrices in the L2 norm: the ratio of the largest and the smallest singular values.
+// It is +Inf for singular matrices.
+func Cond[T types.Float](a *tensor.Tensor[T]) *tensor.Tensor[T] {
+	res, err := svd_batched(a, false, false)
+	if err != nil {
+		return errTensor[T](err)
+	}
+	out := make([]float64, batch_size(res.batch))
+	for b := range out {
+		s := res.s[b*res.k : (b+1)*res.k]
+		out[b] = s[0] / s[len(s)-1]
+	}
+	return to_tensor[T](out, per_matrix(res.batch))
+}
@@ -0,0 +1,236 @@
+package linalg
+
+import (
+	"errors"
+	"fmt"
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"math"
+)
+
+var errSingular = errors.New("matrix is singular")
+
+// solves A @ x = b for the (n,n) triangular matrix and rhs x with k columns, in-place
+func tri_solve[T types.Float](a, x []T, n, k int, lower, unit_diag bool) {
+	for step := 0; step < n; step++ {
+		i := step
+		if !lower {
+			i = n - step - 1
+		}
+		row := x[i*k : (i+1)*k]
+		from, to := 0, i
+		if !lower {
+			from, to = i+1, n
+		}
+		for p := from; p < to; p++ {
+			f := a[i*n+p]
+			if f == 0 {
+				continue
+			}
+			prev := x[p*k : (p+1)*k]
+			for c := range row {
+				row[c] -= f * prev[c]
+			}
+		}
+		if !unit_diag {
+			d := a[i*n+i]
+			for c := range row {
+				row[c] /= d
+			}
+		}
+	}
+}
+
+// solves A @ x = b using the factorization from lu_factor. x must contain the permuted b
+func lu_solve[T types.Float](lu, x []T, n, k int) {
+	tri_solve(lu, x, n, k, true, true)
+	tri_solve(lu, x, n, k, false, false)
+}
+
+// rhs is a vector only if it is 1D, otherwise it is a (batch of) matrix like in numpy 2
+func rhs_shape(b_shape types.Shape) (batch types.Shape, rows, cols int, is_vec bool, err error) {
+	if len(b_shape) == 1 {
+		return nil, int(b_shape[0]), 1, true, nil
+	}
+	batch, rows, cols, err = matrix_shape(b_shape)
+	return batch, rows, cols, false, err
+}
+
+// applies the solver to every pair of matrices of a (..., N,N) and rhs b (..., N,K) or (N,).
+// Batch dims are broadcasted
+func solve_batched[T types.Float](
+	a, b *tensor.Tensor[T],
+	solver func(a_mat, x []T, n, k int) error,
+) *tensor.Tensor[T] {
+	if a.Err != nil {
+		return errTensor[T](a.Err)
+	}
+	if b.Err != nil {
+		return errTensor[T](b.Err)
+	}
+	a_batch, n, err := square_shape(a.Shape())
+	if err != nil {
+		return errTensor[T](err)
+	}
+	b_batch, rows, k, is_vec, err := rhs_shape(b.Shape())
+	if err != nil {
+		return errTensor[T](err)
+	}
+	if rows != n {
+		return errTensor[T](fmt.Errorf("matrix with shape %v and rhs with shape %v are not aligned", a.Shape(), b.Shape()))
+	}
+	out_batch, err := broadcast_batch(a_batch, b_batch)
+	if err != nil {
+		return errTensor[T](err)
+	}
+	a_indices := batch_indices(out_batch, a_batch)
+	b_indices := batch_indices(out_batch, b_batch)
+	a_data, b_data := a.Data(), b.Data()
+	out := make([]T, len(a_indices)*n*k)
+	for i, a_idx := range a_indices {
+		x := out[i*n*k : (i+1)*n*k]
+		copy(x, b_data[b_indices[i]*n*k:(b_indices[i]+1)*n*k])
+		if err := solver(a_data[a_idx*n*n:(a_idx+1)*n*n], x, n, k); err != nil {
+			return errTensor[T](err)
+		}
+	}
+	if is_vec {
+		return tensor.CreateTensorNoCopy(out, with_matrix(out_batch, n))
+	}
+	return tensor.CreateTensorNoCopy(out, with_matrix(out_batch, n, k))
+}
+
+// Solves the linear system A @ x = b for square A.
+// b can be a matrix (..., N,K) or a vector (N,). Batch dims of A and b are broadcasted.
+//
+// Sets an error if A is singular.
+//
+// Example:
+// a = [
+// [3,1],
+// [1,2]]
+// Solve(a, [9,8]) => [2,3]
+func Solve[T types.Float](a, b *tensor.Tensor[T]) *tensor.Tensor[T] {
+	var lu []T
+	var perm []int
+	return solve_batched(a, b, func(a_mat, x []T, n, k int) error {
+		if lu == nil {
+			lu, perm = make([]T, n*n), make([]int, n)
+		}
+		copy(lu, a_mat)
+		if _, singular := lu_factor(lu, n, perm); singular {
+			return errSingular
+		}
+		// apply the permutation to the rhs
+		rhs := append([]T(nil), x...)
+		for i, row := range perm {
+			copy(x[i*k:(i+1)*k], rhs[row*k:(row+1)*k])
+		}
+		lu_solve(lu, x, n, k)
+		return nil
+	})
+}
+
+// Solves A @ x = b for triangular A. Only the lower (or upper) triangle of A is used.
+// b can be a matrix (..., N,K) or a vector (N,). Batch dims of A and b are broadcasted.
+//
+// Sets an error if A has zeros on the diagonal.
+func SolveTriangular[T types.Float](a, b *tensor.Tensor[T], lower bool) *tensor.Tensor[T] {
+	return solve_batched(a, b, func(a_mat, x []T, n, k int) error {
+		for i := 0; i < n; i++ {
+			if a_mat[i*n+i] == 0 {
+				return errSingular
+			}
+		}
+		tri_solve(a_mat, x, n, k, lower, false)
+		return nil
+	})
+}
+
+// Inverse of square matrices.
+//
+// Sets an error if a matrix is singular.
+func Inv[T types.Float](a *tensor.Tensor[T]) *tensor.Tensor[T] {
+	if a.Err != nil {
+		return errTensor[T](a.Err)
+	}
+	batch, n, err := square_shape(a.Shape())
+	if err != nil {
+		return errTensor[T](err)
+	}
+	data := a.Data()
+	out := make([]T, len(data))
+	lu := make([]T, n*n)
+	perm := make([]int, n)
+	for k := 0; k < batch_size(batch); k++ {
+		copy(lu, data[k*n*n:(k+1)*n*n])
+		if _, singular := lu_factor(lu, n, perm); singular {
+			return errTensor[T](errSingular)
+		}
+		// permuted identity matrix
+		x := out[k*n*n : (k+1)*n*n]
+		for i, row := range perm {
+			x[i*n+row] = 1
+		}
+		lu_solve(lu, x, n, n)
+	}
+	return tensor.CreateTensorNoCopy(out, with_matrix(batch, n, n))
+}
+
+// LU factorizations of all matrices and the signs of their permutations
+func lu_batches[T types.Float](a *tensor.Tensor[T]) (batch types.Shape, lus, signs []T, n int, err error) {
+	if a.Err != nil {
+		return nil, nil, nil, 0, a.Err
+	}
+	batch, n, err = square_shape(a.Shape())
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	lus = append([]T(nil), a.Data()...)
+	signs = make([]T, batch_size(batch))
+	perm := make([]int, n)
+	for k := range signs {
+		signs[k], _ = lu_factor(lus[k*n*n:(k+1)*n*n], n, perm)
+	}
+	return batch, lus, signs, n, nil
+}
+
+// Determinant of square matrices. Returns a scalar for 2D input and a tensor with batch shape otherwise.
+func Det[T types.Float](a *tensor.Tensor[T]) *tensor.Tensor[T] {
+	batch, lus, dets, n, err := lu_batches(a)
+	if err != nil {
+		return errTensor[T](err)
+	}
+	for k := range dets {
+		for i := 0; i < n; i++ {
+			dets[k] *= lus[k*n*n+i*n+i]
+		}
+	}
+	return tensor.CreateTensorNoCopy(dets, per_matrix(batch))
+}
+
+// Sign and natural logarithm of the absolute value of the determinant.
+// It doesn't overflow for large matrices unlike Det.
+// For singular matrices the sign is 0 and the logarithm is -Inf.
+func SlogDet[T types.Float](a *tensor.Tensor[T]) (sign, logabsdet *tensor.Tensor[T]) {
+	batch, lus, signs, n, err := lu_batches(a)
+	if err != nil {
+		return errTensor[T](err), errTensor[T](err)
+	}
+	logs := make([]T, len(signs))
+	for k := range signs {
+		var log_sum float64
+		for i := 0; i < n; i++ {
+			d := lus[k*n*n+i*n+i]
+			if d < 0 {
+				signs[k] = -signs[k]
+			}
+			log_sum += math.Log(math.Abs(float64(d)))
+		}
+		if math.IsInf(log_sum, -1) {
+			signs[k] = 0
+		}
+		logs[k] = T(log_sum)
+	}
+	return tensor.CreateTensorNoCopy(signs, per_matrix(batch)), tensor.CreateTensorNoCopy(logs, per_matrix(batch))
+}
@@ -0,0 +1,278 @@
+package linalg
+
+import (
+	"fmt"
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"math"
+)
+
+// singular value decomposition of the (m,n) matrix with m >= n using one-sided Jacobi method.
+// Returns u (m,n), singular values in descending order and v (n,n), so A = u @ diag(s) @ v.T.
+// Columns of u for zero singular values are filled with the orthonormal completion
+func jacobi_svd(a []float64, m, n int) (u, s, v []float64) {
+	w := append([]float64(nil), a...)
+	v = make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		v[i*n+i] = 1
+	}
+	for sweep := 0; sweep < max_sweeps; sweep++ {
+		rotated := false
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				// 2x2 gram matrix of the columns p, q
+				var alpha, beta, gamma float64
+				for k := 0; k < m; k++ {
+					wp, wq := w[k*n+p], w[k*n+q]
+					alpha += wp * wp
+					beta += wq * wq
+					gamma += wp * wq
+				}
+				if math.Abs(gamma) <= 0x1p-52*math.Sqrt(alpha*beta) {
+					continue
+				}
+				rotated = true
+				c, s := jacobi_rotation(alpha, beta, gamma)
+				rotate_cols(w, m, n, p, q, c, s)
+				rotate_cols(v, n, n, p, q, c, s)
+			}
+		}
+		if !rotated {
+			break
+		}
+	}
+	s = make([]float64, n)
+	for j := range s {
+		var norm float64
+		for k := 0; k < m; k++ {
+			norm += w[k*n+j] * w[k*n+j]
+		}
+		s[j] = math.Sqrt(norm)
+	}
+	order := sorted_order(s, true)
+	s = permute(s, order)
+	w = permute_cols(w, m, n, order)
+	v = permute_cols(v, n, n, order)
+
+	// normalized columns are the left singular vectors
+	rank := 0
+	tol := s[0] * float64(m) * 0x1p-52
+	for j := 0; j < n && s[j] > tol; j++ {
+		for k := 0; k < m; k++ {
+			w[k*n+j] /= s[j]
+		}
+		rank++
+	}
+	complete_basis(w, m, n, rank)
+	return w, s, v
+}
+
+// fills columns [from, cols) of the (rows,cols) matrix, so all columns are orthonormal.
+// Columns [0, from) must be orthonormal already
+func complete_basis(q []float64, rows, cols, from int) {
+	col := make([]float64, rows)
+	basis := 0
+	for j := from; j < cols; j++ {
+		for ; basis < rows; basis++ {
+			clear(col)
+			col[basis] = 1
+			// Gram-Schmidt is applied twice for the numerical stability
+			for pass := 0; pass < 2; pass++ {
+				for i := 0; i < j; i++ {
+					var dot float64
+					for k := 0; k < rows; k++ {
+						dot += q[k*cols+i] * col[k]
+					}
+					for k := 0; k < rows; k++ {
+						col[k] -= dot * q[k*cols+i]
+					}
+				}
+			}
+			var norm float64
+			for _, x := range col {
+				norm += x * x
+			}
+			// the basis vector is (almost) in the span of previous columns
+			if norm = math.Sqrt(norm); norm < 0.5 {
+				continue
+			}
+			for k := 0; k < rows; k++ {
+				q[k*cols+j] = col[k] / norm
+			}
+			basis++
+			break
+		}
+	}
+}
+
+func transpose(a []float64, rows, cols int) []float64 {
+	out := make([]float64, len(a))
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			out[j*rows+i] = a[i*cols+j]
+		}
+	}
+	return out
+}
+
+// widens the (rows,cols) matrix to (rows,new_cols) with zero columns
+func widen(a []float64, rows, cols, new_cols int) []float64 {
+	out := make([]float64, rows*new_cols)
+	for i := 0; i < rows; i++ {
+		copy(out[i*new_cols:i*new_cols+cols], a[i*cols:(i+1)*cols])
+	}
+	return out
+}
+
+// SVD of any (m,n) matrix. Returns u (m,k), s (k) and vt (k,n) for thin decomposition
+// and u (m,m), vt (n,n) for full one, k = min(m,n)
+func svd_matrix(a []float64, m, n int, full bool) (u, s, vt []float64) {
+	if m < n {
+		// A.T = v @ diag(s) @ u.T
+		v, s, ut := svd_matrix(transpose(a, m, n), n, m, full)
+		cols := m
+		if full {
+			cols = n
+		}
+		return transpose(ut, m, m), s, transpose(v, n, cols)
+	}
+	u, s, v := jacobi_svd(a, m, n)
+	if full && m > n {
+		u = widen(u, m, n, m)
+		complete_basis(u, m, m, n)
+	}
+	return u, s, transpose(v, n, n)
+}
+
+// SVD of all matrices of the tensor in float64
+type svd_result struct {
+	batch    types.Shape
+	m, n, k  int
+	u, s, vt []float64
+	u_cols   int
+	vt_rows  int
+}
+
+func svd_batched[T types.Float](a *tensor.Tensor[T], full, with_vecs bool) (*svd_result, error) {
+	if a.Err != nil {
+		return nil, a.Err
+	}
+	batch, m, n, err := matrix_shape(a.Shape())
+	if err != nil {
+		return nil, err
+	}
+	res := &svd_result{batch: batch, m: m, n: n, k: min(m, n), u_cols: min(m, n), vt_rows: min(m, n)}
+	if full {
+		res.u_cols, res.vt_rows = m, n
+	}
+	data := to_float64(a.Data())
+	size := batch_size(batch)
+	res.s = make([]float64, 0, size*res.k)
+	if with_vecs {
+		res.u = make([]float64, 0, size*m*res.u_cols)
+		res.vt = make([]float64, 0, size*res.vt_rows*n)
+	}
+	for b := 0; b < size; b++ {
+		u, s, vt := svd_matrix(data[b*m*n:(b+1)*m*n], m, n, full)
+		res.s = append(res.s, s...)
+		if with_vecs {
+			res.u = append(res.u, u...)
+			res.vt = append(res.vt, vt...)
+		}
+	}
+	return res, nil
+}
+
+func to_tensor[T types.Float](data []float64, shape types.Shape) *tensor.Tensor[T] {
+	out := make([]T, len(data))
+	from_float64(data, out)
+	return tensor.CreateTensorNoCopy(out, shape)
+}
+
+// Singular value decomposition: A = u @ diag(s) @ vt.
+// For A with shape (M,N) and K = min(M,N) thin decomposition returns u (M,K), s (K) and vt (K,N),
+// full decomposition returns u (M,M), s (K) and vt (N,N).
+// Singular values are in descending order.
+func SVD[T types.Float](a *tensor.Tensor[T], full_matrices bool) (u, s, vt *tensor.Tensor[T]) {
+	res, err := svd_batched(a, full_matrices, true)
+	if err != nil {
+		return errTensor[T](err), errTensor[T](err), errTensor[T](err)
+	}
+	return to_tensor[T](res.u, with_matrix(res.batch, res.m, res.u_cols)),
+		to_tensor[T](res.s, with_matrix(res.batch, res.k)),
+		to_tensor[T](res.vt, with_matrix(res.batch, res.vt_rows, res.n))
+}
+
+// Singular values in descending order with shape (..., K)
+func SingularValues[T types.Float](a *tensor.Tensor[T]) *tensor.Tensor[T] {
+	res, err := svd_batched(a, false, false)
+	if err != nil {
+		return errTensor[T](err)
+	}
+	return to_tensor[T](res.s, with_matrix(res.batch, res.k))
+}
+
+// singular values smaller than the cutoff are treated as zeros.
+// Non-positive rcond is replaced by max(M,N) * eps
+func cutoff[T types.Float](s []float64, m, n int, rcond float64) float64 {
+	if rcond <= 0 {
+		rcond = float64(max(m, n)) * eps[T]()
+	}
+	return rcond * s[0]
+}
+
+// Moore-Penrose pseudo-inverse computed via SVD. Result has shape (..., N,M).
+// Singular values smaller than rcond * max(s) are treated as zeros.
+// If rcond is not positive, max(M,N) * eps of the type is used.
+func Pinv[T types.Float](a *tensor.Tensor[T], rcond float64) *tensor.Tensor[T] {
+	res, err := svd_batched(a, false, true)
+	if err != nil {
+		return errTensor[T](err)
+	}
+	m, n, k := res.m, res.n, res.k
+	out := make([]float64, batch_size(res.batch)*n*m)
+	for b := 0; b < batch_size(res.batch); b++ {
+		s := res.s[b*k : (b+1)*k]
+		u := res.u[b*m*k : (b+1)*m*k]
+		vt := res.vt[b*k*n : (b+1)*k*n]
+		pinv := out[b*n*m : (b+1)*n*m]
+		tol := cutoff[T](s, m, n, rcond)
+		// pinv = vt.T @ diag(1/s) @ u.T
+		for p := 0; p < k && s[p] > tol; p++ {
+			for i := 0; i < n; i++ {
+				f := vt[p*n+i] / s[p]
+				for j := 0; j < m; j++ {
+					pinv[i*m+j] += f * u[j*k+p]
+				}
+			}
+		}
+	}
+	return to_tensor[T](out, with_matrix(res.batch, n, m))
+}
+
+// Rank of matrices as the number of singular values greater than tol.
+// If tol is 0, max(s) * max(M,N) * eps of the type is used.
+// Returns a scalar for 2D input and a tensor with batch shape otherwise.
+func MatrixRank[T types.Float](a *tensor.Tensor[T], tol float64) *tensor.Tensor[int] {
+	res, err := svd_batched(a, false, false)
+	if err != nil {
+		return &tensor.Tensor[int]{Err: err}
+	}
+	if tol < 0 {
+		return &tensor.Tensor[int]{Err: fmt.Errorf("tolerance must not be negative, got %v", tol)}
+	}
+	ranks := make([]int, batch_size(res.batch))
+	for b := range ranks {
+		s := res.s[b*res.k : (b+1)*res.k]
+		cut := tol
+		if cut == 0 {
+			cut = cutoff[T](s, res.m, res.n, 0)
+		}
+		for _, v := range s {
+			if v > cut {
+				ranks[b]++
+			}
+		}
+	}
+	return tensor.CreateTensorNoCopy(ranks, per_matrix(res.batch))
+}
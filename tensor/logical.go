@@ -0,0 +1,195 @@
+package tensor
+
+import (
+	"fmt"
+	types "gograd/tensor/types"
+)
+
+// Comparisons produce BoolTensor masks, Where and the masked ops consume them.
+// Use AsBool to get a mask from a tensor of 0 and non zero values and FromBool for the reverse.
+
+// comparison producing a bool mask, operands are broadcasted
+func (tensor *Tensor[T]) compare(other *Tensor[T], fn func(T, T) bool) *BoolTensor {
+	if tensor.Err != nil {
+		return errBool(tensor.Err)
+	}
+	if other.Err != nil {
+		return errBool(other.Err)
+	}
+	a, b := tensor.AsContiguous(), other.AsContiguous()
+	data, shape, err := broadcast_apply(a.data(), a.shape, b.data(), b.shape, fn)
+	if err != nil {
+		return errBool(err)
+	}
+	return &BoolTensor{data: data, shape: shape}
+}
+
+//
+// comparisons
+//
+
+// Elementwise a == b. Operands are broadcasted.
+//
+// Example:
+// a = [1,2,3]
+// a.Eq(Scalar(2)) => [false, true, false]
+func (tensor *Tensor[T]) Eq(other *Tensor[T]) *BoolTensor {
+	if is_half[T]() && tensor.Err == nil && other.Err == nil {
+		return half_to_float32(tensor).Eq(half_to_float32(other))
+	}
+	return tensor.compare(other, func(a, b T) bool { return a == b })
+}
+
+// Elementwise a != b
+func (tensor *Tensor[T]) Ne(other *Tensor[T]) *BoolTensor {
+	if is_half[T]() && tensor.Err == nil && other.Err == nil {
+		return half_to_float32(tensor).Ne(half_to_float32(other))
+	}
+	return tensor.compare(other, func(a, b T) bool { return a != b })
+}
+
+// Elementwise a < b
+func (tensor *Tensor[T]) Lt(other *Tensor[T]) *BoolTensor {
+	if is_half[T]() && tensor.Err == nil && other.Err == nil {
+		return half_to_float32(tensor).Lt(half_to_float32(other))
+	}
+	return tensor.compare(other, func(a, b T) bool { return a < b })
+}
+
+// Elementwise a <= b
+func (tensor *Tensor[T]) Le(other *Tensor[T]) *BoolTensor {
+	if is_half[T]() && tensor.Err == nil && other.Err == nil {
+		return half_to_float32(tensor).Le(half_to_float32(other))
+	}
+	return tensor.compare(other, func(a, b T) bool { return a <= b })
+}
+
+// Elementwise a > b
+func (tensor *Tensor[T]) Gt(other *Tensor[T]) *BoolTensor {
+	if is_half[T]() && tensor.Err == nil && other.Err == nil {
+		return half_to_float32(tensor).Gt(half_to_float32(other))
+	}
+	return tensor.compare(other, func(a, b T) bool { return a > b })
+}
+
+// Elementwise a >= b
+func (tensor *Tensor[T]) Ge(other *Tensor[T]) *BoolTensor {
+	if is_half[T]() && tensor.Err == nil && other.Err == nil {
+		return half_to_float32(tensor).Ge(half_to_float32(other))
+	}
+	return tensor.compare(other, func(a, b T) bool { return a >= b })
+}
+
+//
+// selection
+//
+
+// checks that the mask can be broadcasted to the shape of the tensor without changing it
+func (tensor *Tensor[T]) check_mask(mask *BoolTensor) error {
+	if mask.Err != nil {
+		return mask.Err
+	}
+	if !mask.shape.AreBroadcastable(tensor.shape) || !tensor.shape.BroadcastShapes(mask.shape).Equals(tensor.shape) {
+		return fmt.Errorf("mask with shape %v cannot be broadcasted to the shape %v", mask.shape, tensor.shape)
+	}
+	return nil
+}
+
+// Chooses elements from a where cond is true and from b otherwise.
+// cond, a and b are broadcasted together.
+//
+// Example:
+// cond = [true, false, true]
+// Where(cond, [1,2,3], Scalar(0)) => [1,0,3]
+// Where(a.Gt(Scalar(0)), a, Scalar(0)) => relu
+func Where[T types.TensorType](cond *BoolTensor, a, b *Tensor[T]) (*Tensor[T], error) {
+	if cond.Err != nil {
+		return nil, cond.Err
+	}
+	for _, t := range []*Tensor[T]{a, b} {
+		if t.Err != nil {
+			return nil, t.Err
+		}
+	}
+	shape := cond.shape
+	for _, t := range []*Tensor[T]{a, b} {
+		if !shape.AreBroadcastable(t.shape) {
+			return nil, fmt.Errorf("shapes: %v, %v, %v are not broadcastable", cond.shape, a.shape, b.shape)
+		}
+		shape = shape.BroadcastShapes(t.shape)
+	}
+	cond_data := cond.broadcast_data(shape)
+	a_data := a.Broadcast(shape...).AsContiguous().data()
+	b_data := b.Broadcast(shape...).AsContiguous().data()
+	out := CreateEmptyTensor[T](shape...)
+	out_data := out.data()
+	for i, c := range cond_data {
+		if c {
+			out_data[i] = a_data[i]
+		} else {
+			out_data[i] = b_data[i]
+		}
+	}
+	return out, nil
+}
+
+// Replaces elements with the value where the mask is true.
+// The mask is broadcasted to the shape of the tensor.
+//
+// Example:
+// a = [[1,2],[3,4]]
+// a.MaskedFill(a.Gt(Scalar(2)), 0) => [[1,2],[0,0]]
+func (tensor *Tensor[T]) MaskedFill(mask *BoolTensor, value T, out ...*Tensor[T]) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if err := tensor.check_mask(mask); err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	filled := tensor.Clone()
+	data := filled.data()
+	for i, m := range mask.broadcast_data(tensor.shape) {
+		if m {
+			data[i] = value
+		}
+	}
+	out_tensor := get_param(out...)
+	if out_tensor == nil {
+		return filled
+	}
+	if out_tensor.Err != nil {
+		return out_tensor
+	}
+	if _, err := PrepareOutTensor(out_tensor, filled.shape); err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	out_tensor.copyFromContiguous(filled)
+	return out_tensor
+}
+
+// Returns 1D tensor with elements where the mask is true, in the row-major order.
+// The mask is broadcasted to the shape of the tensor.
+// The result has shape [0] if the mask has no true values.
+//
+// Example:
+// a = [[1,2],[3,4]]
+// a.MaskedSelect(a.Ge(Scalar(2))) => [2,3,4]
+func (tensor *Tensor[T]) MaskedSelect(mask *BoolTensor) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if err := tensor.check_mask(mask); err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	data := tensor.AsContiguous().data()
+	selected := make([]T, 0)
+	for i, m := range mask.broadcast_data(tensor.shape) {
+		if m {
+			selected = append(selected, data[i])
+		}
+	}
+	return CreateTensorNoCopy(selected, types.Shape{types.Dim(len(selected))})
+}
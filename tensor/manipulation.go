@@ -0,0 +1,515 @@
+package tensor
+
+import (
+	"errors"
+	"fmt"
+	"gograd/tensor/internal"
+	types "gograd/tensor/types"
+	"slices"
+	"sync"
+)
+
+// resolves a single axis, negative axis is counted from the end
+func (tensor *Tensor[T]) resolve_axis(axis int) (int, error) {
+	ndim := len(tensor.shape)
+	if axis < -ndim || axis >= ndim {
+		return 0, fmt.Errorf("axis %v is out of bounds for %v dims", axis, ndim)
+	}
+	if axis < 0 {
+		axis += ndim
+	}
+	return axis, nil
+}
+
+// identity permutation of the tensor axes
+func (tensor *Tensor[T]) identity_perm() []uint {
+	perm := make([]uint, len(tensor.shape))
+	for i := range perm {
+		perm[i] = uint(i)
+	}
+	return perm
+}
+
+// Reverses the order of elements along the axes. All axes are flipped if none are given.
+// Returns a view which shares the memory with the tensor.
+//
+// Example:
+// a = [
+// [1,2,3],
+// [4,5,6]]
+// a.Flip(1) => [[3,2,1],[6,5,4]]
+// a.Flip() => [[6,5,4],[3,2,1]]
+func (tensor *Tensor[T]) Flip(axes ...int) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	axes, err := normalize_axes(tensor.shape, axes)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	if len(axes) == 0 {
+		axes = make([]int, len(tensor.shape))
+		for i := range axes {
+			axes[i] = i
+		}
+	}
+	// the view starts from the last element of the flipped axes and goes backwards
+	first := tensor.offset
+	strides := slices.Clone(tensor.strides)
+	for _, axis := range axes {
+		first += (int(tensor.shape[axis]) - 1) * strides[axis]
+		strides[axis] = -strides[axis]
+	}
+	return tensor.makeView(first, tensor.shape, strides)
+}
+
+// Interchanges two axes. Returns a view which shares the memory with the tensor.
+//
+// Example:
+// (2,3,4).SwapAxes(0, -1) => (4,3,2)
+func (tensor *Tensor[T]) SwapAxes(axis1, axis2 int) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	a, err := tensor.resolve_axis(axis1)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	b, err := tensor.resolve_axis(axis2)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	perm := tensor.identity_perm()
+	perm[a], perm[b] = perm[b], perm[a]
+	return tensor.View().T(perm...)
+}
+
+// Moves the axis to the new position, other axes keep their order.
+// Returns a view which shares the memory with the tensor.
+//
+// Example:
+// (2,3,4).MoveAxis(0, -1) => (3,4,2)
+// (2,3,4).MoveAxis(2, 0) => (4,2,3)
+func (tensor *Tensor[T]) MoveAxis(source, destination int) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	src, err := tensor.resolve_axis(source)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	dst, err := tensor.resolve_axis(destination)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	perm := slices.Delete(tensor.identity_perm(), src, src+1)
+	perm = slices.Insert(perm, dst, uint(src))
+	return tensor.View().T(perm...)
+}
+
+// Rotates the tensor by 90 degrees k times in the plane of two axes, (0,1) by default.
+// The rotation goes from the first axis towards the second one, negative k rotates backwards.
+// Returns a view which shares the memory with the tensor.
+//
+// Example:
+// a = [
+// [1,2],
+// [3,4]]
+// a.Rot90(1) => [[2,4],[1,3]]
+// a.Rot90(2) => [[4,3],[2,1]]
+func (tensor *Tensor[T]) Rot90(k int, axes ...int) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if len(axes) == 0 {
+		axes = []int{0, 1}
+	}
+	if len(axes) != 2 {
+		tensor.Err = fmt.Errorf("rotation plane must be set by 2 axes, got %v", axes)
+		return tensor
+	}
+	a, err := tensor.resolve_axis(axes[0])
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	b, err := tensor.resolve_axis(axes[1])
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	if a == b {
+		tensor.Err = fmt.Errorf("rotation axes must be different, got %v", axes)
+		return tensor
+	}
+	switch (k%4 + 4) % 4 {
+	case 1:
+		return tensor.Flip(b).SwapAxes(a, b)
+	case 2:
+		return tensor.Flip(a, b)
+	case 3:
+		return tensor.SwapAxes(a, b).Flip(b)
+	}
+	return tensor.View()
+}
+
+// Shifts elements along the axis, elements that go beyond the last position are moved to the beginning.
+// Negative shift goes backwards.
+//
+// Example:
+// a = [1,2,3,4,5]
+// a.Roll(2, 0) => [4,5,1,2,3]
+// a.Roll(-1, 0) => [2,3,4,5,1]
+func (tensor *Tensor[T]) Roll(shift, axis int) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	axis, err := tensor.resolve_axis(axis)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	dim := int(tensor.shape[axis])
+	if dim == 0 {
+		// nothing to shift
+		return tensor.Clone()
+	}
+	shift = (shift%dim + dim) % dim
+	if shift == 0 {
+		return tensor.Clone()
+	}
+	rolled, err := Concat(axis, tensor.sliceAxis(axis, dim-shift, dim), tensor.sliceAxis(axis, 0, dim-shift))
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	return rolled
+}
+
+// Constructs a tensor by repeating the tensor reps times along every axis.
+// If reps are shorter than the shape, leading axes are not repeated.
+// If reps are longer, the tensor gets leading one-sized dims.
+//
+// Example:
+// a = [[1,2]]
+// a.Tile(2, 2) => [[1,2,1,2],[1,2,1,2]]
+// a.Tile(2) => [[1,2,1,2]]
+func (tensor *Tensor[T]) Tile(reps ...int) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	shape, reps, err := tile_shapes(tensor.shape, reps)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	// every dim d gets an outer one-sized dim which is broadcasted to the number of repetitions:
+	// (d0,d1) -> (1,d0,1,d1) -> (r0,d0,r1,d1) -> (r0*d0,r1*d1)
+	interleaved := make(types.Shape, 0, 2*len(shape))
+	broadcasted := make(types.Shape, 0, 2*len(shape))
+	tiled := make(types.Shape, len(shape))
+	for i, dim := range shape {
+		interleaved = append(interleaved, 1, dim)
+		broadcasted = append(broadcasted, types.Dim(reps[i]), dim)
+		tiled[i] = types.Dim(reps[i]) * dim
+	}
+	src := tensor.AsContiguous().View().Reshape(interleaved...)
+	return src.broadcastView(broadcasted).Clone().Reshape(tiled...)
+}
+
+// shape with leading one-sized dims and reps aligned with it
+func tile_shapes(shape types.Shape, reps []int) (types.Shape, []int, error) {
+	if len(reps) == 0 {
+		return nil, nil, errors.New("at least 1 repetition is required")
+	}
+	for _, r := range reps {
+		if r < 1 {
+			return nil, nil, fmt.Errorf("repetitions must be positive, got %v", reps)
+		}
+	}
+	n := max(len(shape), len(reps))
+	aligned_shape := make(types.Shape, n)
+	aligned_reps := make([]int, n)
+	for i := range aligned_shape {
+		aligned_shape[i] = 1
+		aligned_reps[i] = 1
+	}
+	copy(aligned_shape[n-len(shape):], shape)
+	copy(aligned_reps[n-len(reps):], reps)
+	return aligned_shape, aligned_reps, nil
+}
+
+// Gradient of Tile, the gradients of all repetitions are summed up
+func TileGrad[T types.TensorType](grad *Tensor[T], shape types.Shape, reps ...int) (*Tensor[T], error) {
+	if grad.Err != nil {
+		return nil, grad.Err
+	}
+	aligned, reps, err := tile_shapes(shape, reps)
+	if err != nil {
+		return nil, err
+	}
+	interleaved := make(types.Shape, 0, 2*len(aligned))
+	rep_axes := make([]int, len(aligned))
+	for i, dim := range aligned {
+		interleaved = append(interleaved, types.Dim(reps[i]), dim)
+		rep_axes[i] = 2 * i
+	}
+	sum := grad.AsContiguous().View().Reshape(interleaved...).Sum(false, rep_axes...)
+	if sum.Err != nil {
+		return nil, sum.Err
+	}
+	return sum.Reshape(shape...), nil
+}
+
+// index which repeats every position along the dim
+func repeat_index(repeats []int, dim int) (*Tensor[int], error) {
+	if len(repeats) != 1 && len(repeats) != dim {
+		return nil, fmt.Errorf("number of repeats must be 1 or %v, got %v", dim, len(repeats))
+	}
+	index := make([]int, 0, dim)
+	for p := 0; p < dim; p++ {
+		r := repeats[min(p, len(repeats)-1)]
+		if r < 1 {
+			return nil, fmt.Errorf("repeats must be positive, got %v", repeats)
+		}
+		for range r {
+			index = append(index, p)
+		}
+	}
+	return CreateTensor(index, types.Shape{types.Dim(len(index))}), nil
+}
+
+// Repeats every element along the axis. A single value repeats all elements the same number of times,
+// otherwise every element has its own number of repeats.
+//
+// Example:
+// a = [
+// [1,2],
+// [3,4]]
+// a.RepeatInterleave([]int{2}, 1) => [[1,1,2,2],[3,3,4,4]]
+// a.RepeatInterleave([]int{1,2}, 0) => [[1,2],[3,4],[3,4]]
+func (tensor *Tensor[T]) RepeatInterleave(repeats []int, axis int) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	axis, err := tensor.resolve_axis(axis)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	index, err := repeat_index(repeats, int(tensor.shape[axis]))
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	return tensor.IndexSelect(axis, index)
+}
+
+// Gradient of RepeatInterleave, the gradients of all copies of an element are summed up.
+// dim is the size of the axis before repeating
+func RepeatInterleaveGrad[T types.TensorType](grad *Tensor[T], repeats []int, axis, dim int) (*Tensor[T], error) {
+	if grad.Err != nil {
+		return nil, grad.Err
+	}
+	axis, err := grad.resolve_axis(axis)
+	if err != nil {
+		return nil, err
+	}
+	index, err := repeat_index(repeats, dim)
+	if err != nil {
+		return nil, err
+	}
+	shape := slices.Clone(grad.shape)
+	shape[axis] = types.Dim(dim)
+	res := Zeros[T](shape...).IndexAdd(axis, index, grad)
+	return res, res.Err
+}
+
+// keeps the elements of the last two dims where keep(row, col) is true, others are set to zero
+func (tensor *Tensor[T]) triangle(keep func(row, col int) bool) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	ndim := len(tensor.shape)
+	if ndim < 2 {
+		tensor.Err = fmt.Errorf("tensor must be at least 2D, got shape %v", tensor.shape)
+		return tensor
+	}
+	out := tensor.Clone()
+	rows, cols := int(tensor.shape[ndim-2]), int(tensor.shape[ndim-1])
+	data := out.data()
+	internal.Parallel(len(data)/cols,
+		func(start, end int, _, _, _ []int, mu *sync.Mutex) {
+			for r := start; r < end; r++ {
+				row := data[r*cols : (r+1)*cols]
+				for c := range row {
+					if !keep(r%rows, c) {
+						row[c] = 0
+					}
+				}
+			}
+		}, nil, nil, nil)
+	return out
+}
+
+// Lower triangle of the matrices in the last two dims, elements above the k-th diagonal are set to zero.
+// k > 0 is above the main diagonal, k < 0 is below it.
+//
+// Example:
+// a = [
+// [1,2,3],
+// [4,5,6]]
+// a.Tril(0) => [[1,0,0],[4,5,0]]
+// a.Tril(1) => [[1,2,0],[4,5,6]]
+func (tensor *Tensor[T]) Tril(k int) *Tensor[T] {
+	return tensor.triangle(func(row, col int) bool { return col-row <= k })
+}
+
+// Upper triangle of the matrices in the last two dims, elements below the k-th diagonal are set to zero.
+//
+// Example:
+// a = [
+// [1,2,3],
+// [4,5,6]]
+// a.Triu(0) => [[1,2,3],[0,5,6]]
+// a.Triu(1) => [[0,2,3],[0,0,6]]
+func (tensor *Tensor[T]) Triu(k int) *Tensor[T] {
+	return tensor.triangle(func(row, col int) bool { return col-row >= k })
+}
+
+type PadMode int
+
+const (
+	// pads with a constant value, 0 by default
+	PadConstant PadMode = iota
+	// reflects the values around the edge without repeating it: [1,2,3] -> [3,2,1,2,3,2,1]
+	PadReflect
+	// repeats the edge value: [1,2,3] -> [1,1,1,2,3,3,3]
+	PadReplicate
+	// wraps the values around: [1,2,3] -> [2,3,1,2,3,1,2]
+	PadCircular
+)
+
+// positions of the source elements along the padded dim
+func pad_index(dim, before, after int, mode PadMode) *Tensor[int] {
+	index := make([]int, before+dim+after)
+	period := 2 * (dim - 1)
+	for i := range index {
+		p := i - before
+		switch mode {
+		case PadReflect:
+			if period == 0 {
+				p = 0
+				break
+			}
+			p = (p%period + period) % period
+			if p >= dim {
+				p = period - p
+			}
+		case PadReplicate:
+			p = min(max(p, 0), dim-1)
+		case PadCircular:
+			p = (p%dim + dim) % dim
+		}
+		index[i] = p
+	}
+	return CreateTensor(index, types.Shape{types.Dim(len(index))})
+}
+
+// checks the pad widths and the mode
+func check_pad(shape types.Shape, widths [][2]int, mode PadMode) error {
+	if mode < PadConstant || mode > PadCircular {
+		return fmt.Errorf("unknown pad mode %v", mode)
+	}
+	if len(widths) != len(shape) {
+		return fmt.Errorf("pad widths must be set for all %v axes, got %v", len(shape), widths)
+	}
+	for _, w := range widths {
+		if w[0] < 0 || w[1] < 0 {
+			return fmt.Errorf("pad widths must be non-negative, got %v", widths)
+		}
+	}
+	return nil
+}
+
+// Pads the tensor with widths[axis] = {before, after} elements along every axis.
+// Axes are padded one by one, so the corners are filled from the already padded values.
+// value is used by PadConstant mode, it is 0 by default.
+//
+// Example:
+// a = [1,2,3]
+// a.Pad([][2]int{{2,1}}, PadConstant) => [0,0,1,2,3,0]
+// a.Pad([][2]int{{2,1}}, PadReflect) => [3,2,1,2,3,2]
+// a.Pad([][2]int{{2,1}}, PadReplicate) => [1,1,1,2,3,3]
+// a.Pad([][2]int{{2,1}}, PadCircular) => [2,3,1,2,3,1]
+func (tensor *Tensor[T]) Pad(widths [][2]int, mode PadMode, value ...T) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if err := check_pad(tensor.shape, widths, mode); err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	var fill T
+	if len(value) > 0 {
+		fill = value[0]
+	}
+	padded := tensor
+	for axis, w := range widths {
+		if w[0] == 0 && w[1] == 0 {
+			continue
+		}
+		dim := int(padded.shape[axis])
+		if mode != PadConstant {
+			padded = padded.IndexSelect(axis, pad_index(dim, w[0], w[1], mode))
+			continue
+		}
+		shape := slices.Clone(padded.shape)
+		shape[axis] = types.Dim(w[0] + dim + w[1])
+		out := CreateEmptyTensor[T](shape...).Fill(fill)
+		out.sliceAxis(axis, w[0], w[0]+dim).copyFromContiguous(padded.AsContiguous())
+		padded = out
+	}
+	if padded == tensor {
+		return tensor.Clone()
+	}
+	return padded
+}
+
+// Gradient of Pad. Padded positions of constant mode get no gradient,
+// in other modes the gradient goes to the source elements
+func PadGrad[T types.TensorType](grad *Tensor[T], widths [][2]int, mode PadMode) (*Tensor[T], error) {
+	if grad.Err != nil {
+		return nil, grad.Err
+	}
+	if err := check_pad(grad.shape, widths, mode); err != nil {
+		return nil, err
+	}
+	// axes are unpadded in the reversed order
+	for axis := len(widths) - 1; axis >= 0; axis-- {
+		w := widths[axis]
+		if w[0] == 0 && w[1] == 0 {
+			continue
+		}
+		dim := int(grad.shape[axis]) - w[0] - w[1]
+		if dim < 1 {
+			return nil, fmt.Errorf("pad widths %v don't match the gradient shape %v", widths, grad.shape)
+		}
+		if mode == PadConstant {
+			grad = grad.sliceAxis(axis, w[0], w[0]+dim)
+			continue
+		}
+		shape := slices.Clone(grad.shape)
+		shape[axis] = types.Dim(dim)
+		grad = Zeros[T](shape...).IndexAdd(axis, pad_index(dim, w[0], w[1], mode), grad)
+		if grad.Err != nil {
+			return nil, grad.Err
+		}
+	}
+	return grad.AsContiguous(), nil
+}
@@ -1,107 +1,80 @@
 package tensor
 
 import (
-	"errors"
 	"fmt"
 	types "gograd/tensor/types"
+	"slices"
 )
 
-// tensor.IndexMask() can work in two modes. When 'enumerate' is false
-// each value of the mask will represent each element(or subtensor) of 'tensor'.
+// extends the mask of the leading dims with trailing dims of size 1, so it is broadcasted to the tensor
+func (tensor *Tensor[T]) leading_mask(mask *BoolTensor) (*BoolTensor, error) {
+	if mask.Err != nil {
+		return nil, mask.Err
+	}
+	ndim := len(mask.shape)
+	if ndim > len(tensor.shape) || !mask.shape.Equals(tensor.shape[:ndim]) {
+		return nil, fmt.Errorf("mask with shape %v must match the leading dims of the shape %v", mask.shape, tensor.shape)
+	}
+	shape := slices.Clone(mask.shape)
+	for range tensor.shape[ndim:] {
+		shape = append(shape, 1)
+	}
+	return &BoolTensor{data: mask.data, shape: shape}, nil
+}
+
+// Selects the subtensors where the mask is true, like a[mask] in numpy.
+// The mask has the leading dims of the tensor, the result has the number of true values
+// as the first dim followed by the remaining dims of the tensor.
+// Use IndexSelect or TakeAlongAxis to select by integer positions.
 //
 // Example:
 //
-// tensor_a: [[1,2,3][4,5,6][7,8,9]]
-// mask: [1,0,2]
-//
-// If enumerate is turned off:
-//
-// tensor_a.IndexMask(mask, false) => [[4,5,6],[1,2,3],[7,8,9]]. Here we get shuffled tensor with 1st, 0th and 2nd subtensors
+// tensor_a: [[1,2,3],[4,5,6],[7,8,9]]
 //
-// if enumerate is enabled:
+// tensor_a.IndexMask([true, false, true]) => [[1,2,3],[7,8,9]]
 //
-// tensor_a.IndexMask(mask, true) => [2,4,9]
-//
-// Also enumerate can be recreated using this mask:
-//
-// tensor_a.IndexMask([0,1],[1,0],[2,2]], false) => [2,4,9] - same result
-func (tensor *Tensor[T]) IndexMask(mask_tensor *Tensor[T], enumerate bool) *Tensor[T] {
+// tensor_a.IndexMask([[false, true, false], [true, false, false], [false, false, true]]) => [2,4,9]
+func (tensor *Tensor[T]) IndexMask(mask *BoolTensor) *Tensor[T] {
 	if tensor.Err != nil {
 		return tensor
 	}
-	// TODO support for nDim masks
-	if len(mask_tensor.Shape().Squeeze()) > 2 {
-		tensor.Err = errors.New("mask_tensor must have 2 dims max")
-		return tensor
-	}
-	dims := len(tensor.Shape())
-	if dims < len(mask_tensor.Shape()) {
-		tensor.Err = fmt.Errorf("mask_tensor must have no more dimensions than `tensor`. %v < %v",
-			dims, len(mask_tensor.Shape()))
+	expanded, err := tensor.leading_mask(mask)
+	if err != nil {
+		tensor.Err = err
 		return tensor
 	}
-
-	mask_tensor = mask_tensor.AsContiguous()
-
-	to_reduce := make([]int, len(mask_tensor.shape)-1)
-	for i := 0; i < len(mask_tensor.shape)-1; i++ {
-		to_reduce[i] = i + 1
-	}
-	out_shape := append(types.Shape{}, tensor.shape...)
-	if len(to_reduce) > 0 {
-		out_shape = tensor.shape.ReduceDim(to_reduce...).SqueezeInner()
+	selected := tensor.MaskedSelect(expanded)
+	if selected.Err != nil {
+		return selected
 	}
-
-	// tlist := &TensorList[T]{}
-	stacked := CreateEmptyTensor[T](out_shape...)
-	start := 0
-	mask_tensor_int := AsType[T, int](mask_tensor)
-
-	for i := 0; i < int(tensor.Shape()[0]); i++ {
-		mask_i := mask_tensor_int.Index(i).data()
-		// mask_i := AsType[T, int](mask_tensor.Index(i)).data()
-		var masked *Tensor[T]
-		if enumerate {
-			masked = tensor.Index(i).Index(mask_i...).Unsqueeze(0)
-		} else {
-			masked = tensor.Index(mask_i...).Unsqueeze(0)
+	count := 0
+	for _, m := range mask.data {
+		if m {
+			count++
 		}
-		// tlist.Append(masked)
-		end := int(masked.Size())
-		copy(stacked.data()[start:start+end], masked.data())
-		start += end
 	}
-	// return tlist.StackedTensors
-	return stacked
+	shape := append(types.Shape{types.Dim(count)}, tensor.shape[len(mask.shape):]...)
+	return selected.Reshape(shape...)
 }
 
-func (tensor *Tensor[T]) SetByIndexMask(mask_tensor *Tensor[T], enumerate bool, value T) {
+// Sets the value to the subtensors where the mask is true, like a[mask] = value in numpy.
+// The mask has the leading dims of the tensor, the tensor is updated in-place.
+//
+// Example:
+//
+// tensor_a: [[1,2,3],[4,5,6]]
+//
+// tensor_a.SetByIndexMask([false, true], 0) => [[1,2,3],[0,0,0]]
+func (tensor *Tensor[T]) SetByIndexMask(mask *BoolTensor, value T) {
 	if tensor.Err != nil {
 		return
 	}
-	if mask_tensor.Shape()[0] != tensor.Shape()[0] {
-		tensor.Err = errors.New("mask_tensor and tensor must have same 0-dim")
+	expanded, err := tensor.leading_mask(mask)
+	if err != nil {
+		tensor.Err = err
 		return
 	}
-	if len(mask_tensor.Squeeze().Shape()) > 2 {
-		tensor.Err = errors.New("mask_tensor must have 2 dims max")
-		return
-	}
-	dims := len(tensor.Shape())
-	if dims < len(mask_tensor.Shape()) {
-		tensor.Err = fmt.Errorf("mask_tensor must have no more dimensions than `tensor`. %v < %v",
-			dims, len(mask_tensor.Shape()))
-		return
-	}
-	mask_tensor = mask_tensor.AsContiguous()
-
-	for i := 0; i < int(tensor.Shape()[0]); i++ {
-		mask_i := AsType[T, int](mask_tensor.Index(i)).data()
-		if enumerate {
-			mask_i = append([]int{i}, mask_i...)
-		}
-		tensor.Set(mask_i, value)
-	}
+	tensor.MaskedFill(expanded, value, tensor)
 }
 
 // tries to find a value in tensor and returns its index
@@ -118,9 +91,8 @@ func (tensor *Tensor[T]) Find(value T) ([]int, error) {
 
 	it := tensor.CreateIterator()
 	for it.Iterate() {
-		i := it.Index()
 		idx := it.Next()
-		if tensor.data()[i] == value {
+		if tensor.Get_fast(idx...) == value {
 			return idx, nil
 		}
 	}
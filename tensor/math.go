@@ -0,0 +1,205 @@
+package tensor
+
+import (
+	"gograd/tensor/internal"
+	"gograd/tensor/internal/device"
+)
+
+// Elementwise math functions.
+// Sqrt, Rsqrt, Abs, Floor, Ceil, Round, Trunc, Square, Reciprocal, Maximum and Minimum
+// use AVX kernels for float32. Other functions and types fall back to the parallel go loops.
+
+//
+// unary
+//
+
+func (tensor *Tensor[T]) Sqrt(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Sqrt, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.SqrtAtomic[T], device.Sqrt[T], get_param(out...))
+}
+
+// 1 / sqrt(x)
+func (tensor *Tensor[T]) Rsqrt(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Rsqrt, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.RsqrtAtomic[T], device.Rsqrt[T], get_param(out...))
+}
+
+func (tensor *Tensor[T]) Abs(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Abs, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.AbsAtomic[T], device.Abs[T], get_param(out...))
+}
+
+// -1, 0 or 1 depending on the sign of the value. NaN stays NaN
+func (tensor *Tensor[T]) Sign(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Sign, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.SignAtomic[T], device.Sign[T], get_param(out...))
+}
+
+func (tensor *Tensor[T]) Sin(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Sin, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.SinAtomic[T], device.Sin[T], get_param(out...))
+}
+
+func (tensor *Tensor[T]) Cos(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Cos, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.CosAtomic[T], device.Cos[T], get_param(out...))
+}
+
+func (tensor *Tensor[T]) Tan(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Tan, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.TanAtomic[T], device.Tan[T], get_param(out...))
+}
+
+func (tensor *Tensor[T]) Tanh(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Tanh, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.TanhAtomic[T], device.Tanh[T], get_param(out...))
+}
+
+func (tensor *Tensor[T]) Log2(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Log2, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.Log2Atomic[T], device.Log2[T], get_param(out...))
+}
+
+func (tensor *Tensor[T]) Log10(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Log10, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.Log10Atomic[T], device.Log10[T], get_param(out...))
+}
+
+// ln(1 + x), accurate for small x
+func (tensor *Tensor[T]) Log1p(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Log1p, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.Log1pAtomic[T], device.Log1p[T], get_param(out...))
+}
+
+// exp(x) - 1, accurate for small x
+func (tensor *Tensor[T]) Expm1(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Expm1, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.Expm1Atomic[T], device.Expm1[T], get_param(out...))
+}
+
+// Gauss error function
+func (tensor *Tensor[T]) Erf(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Erf, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.ErfAtomic[T], device.Erf[T], get_param(out...))
+}
+
+func (tensor *Tensor[T]) Floor(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Floor, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.FloorAtomic[T], device.Floor[T], get_param(out...))
+}
+
+func (tensor *Tensor[T]) Ceil(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Ceil, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.CeilAtomic[T], device.Ceil[T], get_param(out...))
+}
+
+// Rounds half to even like numpy: 0.5 => 0, 1.5 => 2, 2.5 => 2
+func (tensor *Tensor[T]) Round(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Round, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.RoundAtomic[T], device.Round[T], get_param(out...))
+}
+
+// Rounds towards zero
+func (tensor *Tensor[T]) Trunc(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Trunc, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.TruncAtomic[T], device.Trunc[T], get_param(out...))
+}
+
+// 1 / x. For integer types it truncates, so the result is 0 for |x| > 1 and for x == 0
+func (tensor *Tensor[T]) Reciprocal(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Reciprocal, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.ReciprocalAtomic[T], device.Reciprocal[T], get_param(out...))
+}
+
+func (tensor *Tensor[T]) Square(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Square, get_param(out...))
+	}
+	return unaryElementwiseRoutine(tensor, internal.SquareAtomic[T], device.Square[T], get_param(out...))
+}
+
+//
+// binary
+//
+
+// Elementwise maximum of two tensors. NaN is propagated.
+//
+// Example:
+// a = [1,5,3]
+// a.Maximum([4,2,6]) => [4,5,6]
+func (tensor *Tensor[T]) Maximum(other *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_binary(tensor, other, (*Tensor[float32]).Maximum, get_param(out...))
+	}
+	return baseBinElementwiseOp(tensor, other, internal.MaximumAtomic[T], device.Maximum[T], get_param(out...))
+}
+
+// Elementwise minimum of two tensors. NaN is propagated.
+func (tensor *Tensor[T]) Minimum(other *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_binary(tensor, other, (*Tensor[float32]).Minimum, get_param(out...))
+	}
+	return baseBinElementwiseOp(tensor, other, internal.MinimumAtomic[T], device.Minimum[T], get_param(out...))
+}
+
+// Remainder of the floor division like numpy mod, the result has the sign of the divisor.
+// Integer division by zero gives 0.
+//
+// Example:
+// a = [-3,3]
+// a.Mod(Scalar(2)) => [1,1]
+func (tensor *Tensor[T]) Mod(other *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_binary(tensor, other, (*Tensor[float32]).Mod, get_param(out...))
+	}
+	return baseBinElementwiseOp(tensor, other, internal.ModAtomic[T], device.Mod[T], get_param(out...))
+}
+
+// Division rounded towards negative infinity. a == a.FloorDiv(b) * b + a.Mod(b).
+// Integer division by zero gives 0.
+//
+// Example:
+// a = [-3,3]
+// a.FloorDiv(Scalar(2)) => [-2,1]
+func (tensor *Tensor[T]) FloorDiv(other *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_binary(tensor, other, (*Tensor[float32]).FloorDiv, get_param(out...))
+	}
+	return baseBinElementwiseOp(tensor, other, internal.FloorDivAtomic[T], device.FloorDiv[T], get_param(out...))
+}
@@ -1,16 +1,26 @@
 package tensor
 
 import (
-	"errors"
 	"fmt"
 	"gograd/tensor/internal"
 	"gograd/tensor/internal/device"
 	"gograd/tensor/types"
-	"reflect"
 )
 
 var AUTO_IMPL device.Implementation = *device.DetectImpl().ShowDebugInfo()
 
+// shapes are equal up to the leading one-sized dims, e.g. (1,6) and (6).
+// Equal sizes are not enough: (3,1) & (1,3) are broadcasted to (3,3)
+func equal_ignoring_leading_ones(a, b types.Shape) bool {
+	for len(a) > 0 && a[0] == 1 {
+		a = a[1:]
+	}
+	for len(b) > 0 && b[0] == 1 {
+		b = b[1:]
+	}
+	return a.Equals(b)
+}
+
 // general use Binary operator
 func baseBinElementwiseOp[T types.TensorType](
 	tensor_a,
@@ -30,6 +40,24 @@ func baseBinElementwiseOp[T types.TensorType](
 	if out != nil && out.Err != nil {
 		return out
 	}
+	if is_half[T]() {
+		// ops supporting 16-bit floats compute in float32 before getting here
+		tensor_a.Err = half_op_err[T]()
+		return tensor_a
+	}
+	if out != nil && !out.IsContiguous() {
+		// output is a view. Compute the result and write it back using view's strides
+		res := baseBinElementwiseOp(tensor_a, tensor_b, scalar_impl, vector_impl, nil)
+		if res.Err != nil {
+			return res
+		}
+		if _, err := PrepareOutTensor(out, res.shape); err != nil {
+			tensor_a.Err = err
+			return tensor_a
+		}
+		out.copyFromContiguous(res)
+		return out
+	}
 	var outTensor *Tensor[T]
 	var err error
 
@@ -51,15 +79,19 @@ func baseBinElementwiseOp[T types.TensorType](
 			return tensor_a
 		}
 		// most trivial case (1,) & (1,)
-		vector_impl(AUTO_IMPL, tensor_a.data(), tensor_b.data(), outTensor.data())
+		vector_impl(AUTO_IMPL, tensor_a.AsContiguous().data(), tensor_b.AsContiguous().data(), outTensor.data())
 		return outTensor
 	}
 
 	are_contiguous := tensor_a.IsContiguous() && tensor_b.IsContiguous()
 
-	if len(tensor_a.data()) == len(tensor_b.data()) {
-		// same broadcastable shapes (N,M) & (N,M)
-		outTensor, err = PrepareOutTensor(out, tensor_a.shape)
+	if tensor_a.Size() == tensor_b.Size() && equal_ignoring_leading_ones(tensor_a.shape, tensor_b.shape) {
+		// same broadcastable shapes (N,M) & (N,M) or (1,N,M) & (N,M). The output gets the longer shape
+		out_shape := tensor_a.shape
+		if len(tensor_b.shape) > len(out_shape) {
+			out_shape = tensor_b.shape
+		}
+		outTensor, err = PrepareOutTensor(out, out_shape)
 		if err != nil {
 			tensor_a.Err = err
 			return tensor_a
@@ -68,12 +100,14 @@ func baseBinElementwiseOp[T types.TensorType](
 
 		if are_contiguous && vector_impl != nil { // vec or avx
 			vector_impl(AUTO_IMPL, tensor_a.data(), tensor_b.data(), out_data)
-		} else if !are_contiguous || vector_impl == nil {
-			iter := tensor_a.CreateIterator()
+		} else {
+			// views of both operands with the rank of the output, so they are indexed the same way
+			a_view, b_view := tensor_a.broadcastView(out_shape), tensor_b.broadcastView(out_shape)
+			iter := outTensor.CreateIterator()
 			for iter.Iterate() {
 				idx := iter.Next()
 				outIdx := get_flat_idx_fast(outTensor.strides, idx...)
-				out_data[outIdx] = scalar_impl(tensor_a.Get_fast(idx...), tensor_b.Get_fast(idx...))
+				out_data[outIdx] = scalar_impl(a_view.Get_fast(idx...), b_view.Get_fast(idx...))
 			}
 		}
 	} else if tensor_b.shape.IsScalarLike() {
@@ -85,7 +119,7 @@ func baseBinElementwiseOp[T types.TensorType](
 			return tensor_a
 		}
 		out_data := outTensor.data()
-		vector_impl(AUTO_IMPL, tensor_a.data(), tensor_b.data(), out_data)
+		vector_impl(AUTO_IMPL, tensor_a.AsContiguous().data(), tensor_b.AsContiguous().data(), out_data)
 	} else if tensor_a.shape.IsScalarLike() {
 		// tensor_a is scalar
 		// (1,) & (N, M, ...)
@@ -95,7 +129,8 @@ func baseBinElementwiseOp[T types.TensorType](
 			return tensor_a
 		}
 		out_data := outTensor.data()
-		vector_impl(AUTO_IMPL, tensor_b.data(), tensor_a.data(), out_data)
+		// keep the order of operands, Sub and Div are not commutative
+		vector_impl(AUTO_IMPL, tensor_a.AsContiguous().data(), tensor_b.AsContiguous().data(), out_data)
 	} else {
 		// tensors should have equal shapes or at least one of them should be scalar-like
 		if !tensor_a.shape.AreBroadcastable(tensor_b.shape) {
@@ -108,21 +143,15 @@ func baseBinElementwiseOp[T types.TensorType](
 		// apply operation for non scalar broadcastable tensors
 		broadcasted_shape := tensor_a.Shape().BroadcastShapes(tensor_b.Shape())
 
-		// determine which tensor (at least 1) should be broadcasted
+		// determine which tensor (at least 1) should be broadcasted.
+		// Tensor with fewer dims is broadcasted as well, e.g. (2,3,4) & (3,4)
 		var broadcasted_tensor_a *Tensor[T] = nil
 		var broadcasted_tensor_b *Tensor[T] = nil
-		for i, brc_dim := range broadcasted_shape {
-			if broadcasted_tensor_a != nil && broadcasted_tensor_b != nil {
-				continue
-			}
-			if i < len(tensor_a.shape) && tensor_a.shape[i] < brc_dim && broadcasted_tensor_a == nil {
-				// need to broadcast tensor_a
-				broadcasted_tensor_a = tensor_a.Broadcast(broadcasted_shape...)
-			}
-			if i < len(tensor_b.shape) && tensor_b.shape[i] < brc_dim && broadcasted_tensor_b == nil {
-				// need to broadcast tensor_b
-				broadcasted_tensor_b = tensor_b.Broadcast(broadcasted_shape...)
-			}
+		if !tensor_a.shape.Equals(broadcasted_shape) {
+			broadcasted_tensor_a = tensor_a.Broadcast(broadcasted_shape...)
+		}
+		if !tensor_b.shape.Equals(broadcasted_shape) {
+			broadcasted_tensor_b = tensor_b.Broadcast(broadcasted_shape...)
 		}
 		outTensor, err = PrepareOutTensor(out, broadcasted_shape)
 		if err != nil {
@@ -163,11 +192,29 @@ func unaryElementwiseRoutine[T types.TensorType](
 	if scalar_impl == nil && vector_impl == nil {
 		panic("no implementation found")
 	}
+	if is_half[T]() {
+		tensor.Err = half_op_err[T]()
+		return tensor
+	}
+	if out != nil && !out.IsContiguous() {
+		// output is a view. Compute the result and write it back using view's strides
+		res := unaryElementwiseRoutine(tensor, scalar_impl, vector_impl, nil)
+		if res.Err != nil {
+			return res
+		}
+		if _, err := PrepareOutTensor(out, res.shape); err != nil {
+			tensor.Err = err
+			return tensor
+		}
+		out.copyFromContiguous(res)
+		return out
+	}
 	outTensor, err := PrepareOutTensor(out, tensor.Shape())
 	if err != nil {
 		tensor.Err = err
 		return tensor
 	}
+	tensor = tensor.AsContiguous()
 	if tensor.shape.IsScalarLike() && scalar_impl != nil {
 		outTensor.data()[0] = scalar_impl(tensor.Item())
 		return outTensor
@@ -187,52 +234,90 @@ func unaryElementwiseRoutine[T types.TensorType](
 //
 
 func (tensor *Tensor[T]) Add(other_tensor *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_binary(tensor, other_tensor, (*Tensor[float32]).Add, get_param(out...))
+	}
 	return baseBinElementwiseOp(tensor, other_tensor, internal.AddAtomic[T], device.Add[T], get_param(out...))
 }
 
 func (tensor *Tensor[T]) Sub(other_tensor *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_binary(tensor, other_tensor, (*Tensor[float32]).Sub, get_param(out...))
+	}
 	return baseBinElementwiseOp(tensor, other_tensor, internal.SubAtomic[T], device.Sub[T], get_param(out...))
 }
 
 func (tensor *Tensor[T]) Mul(other_tensor *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_binary(tensor, other_tensor, (*Tensor[float32]).Mul, get_param(out...))
+	}
 	return baseBinElementwiseOp(tensor, other_tensor, internal.MulAtomic[T], device.Mul[T], get_param(out...))
 }
 
 func (tensor *Tensor[T]) Div(other_tensor *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_binary(tensor, other_tensor, (*Tensor[float32]).Div, get_param(out...))
+	}
 	return baseBinElementwiseOp(tensor, other_tensor, internal.DivAtomic[T], device.Div[T], get_param(out...))
 }
 
 func (tensor *Tensor[T]) Pow(other_tensor *Tensor[T], out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_binary(tensor, other_tensor, (*Tensor[float32]).Pow, get_param(out...))
+	}
 	return baseBinElementwiseOp(tensor, other_tensor, internal.PowAtomic[T], device.Pow[T], get_param(out...))
 }
 
 // unary
 func (tensor *Tensor[T]) Neg(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Neg, get_param(out...))
+	}
 	return unaryElementwiseRoutine(tensor, internal.NegAtomic[T], device.Neg[T], get_param(out...))
 }
 
 func (tensor *Tensor[T]) Sigmoid(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Sigmoid, get_param(out...))
+	}
 	return unaryElementwiseRoutine(tensor, internal.SigmoidAtomic[T], device.Sigmoid[T], get_param(out...))
 }
 
 func (tensor *Tensor[T]) Ln(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Ln, get_param(out...))
+	}
 	return unaryElementwiseRoutine(tensor, internal.LnAtomic[T], nil, get_param(out...))
 }
 
 // combination of Ln().Neg()
 func (tensor *Tensor[T]) LnNeg(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).LnNeg, get_param(out...))
+	}
 	return unaryElementwiseRoutine(tensor, internal.LnNegAtomic[T], device.LnNeg[T], get_param(out...))
 }
 
 func (tensor *Tensor[T]) Relu(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Relu, get_param(out...))
+	}
 	return unaryElementwiseRoutine(tensor, internal.ReluAtomic[T], device.Relu[T], get_param(out...))
 }
 
 func (tensor *Tensor[T]) Exp(out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, (*Tensor[float32]).Exp, get_param(out...))
+	}
 	return unaryElementwiseRoutine(tensor, internal.ExpAtomic[T], device.Exp[T], get_param(out...))
 }
 
 func (tensor *Tensor[T]) Clip(min, max float32, out ...*Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, func(t *Tensor[float32], _ ...*Tensor[float32]) *Tensor[float32] {
+			return t.Clip(min, max)
+		}, get_param(out...))
+	}
 	clip_fn := func(v T) T {
 		if v < T(min) {
 			return T(min)
@@ -246,6 +331,11 @@ func (tensor *Tensor[T]) Clip(min, max float32, out ...*Tensor[T]) *Tensor[T] {
 }
 
 func (tensor *Tensor[T]) Softmax(out *Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_unary(tensor, func(t *Tensor[float32], _ ...*Tensor[float32]) *Tensor[float32] {
+			return t.Softmax(nil)
+		}, out)
+	}
 	out, err := PrepareOutTensor(out, tensor.shape)
 	if err != nil {
 		return tensor
@@ -267,109 +357,117 @@ func (tensor *Tensor[T]) GradientStep(grad, lr *Tensor[T]) *Tensor[T] {
 // MATRIX OPERATIONS
 //
 
+// Alias for MatMul
 func (tensor *Tensor[T]) Dot(other *Tensor[T]) *Tensor[T] {
-	if tensor.Err != nil {
-		return tensor
-	}
-	if other.Err != nil {
-		return other
-	}
-	if len(tensor.shape) != len(other.shape) {
-		tensor.Err = errors.New("tensors must have equal number of dims")
-		return tensor
-	}
-
-	if len(tensor.shape) == 2 {
-		return tensor.MatMul(other)
-	}
-
-	outer_dims_a := tensor.shape[:len(tensor.shape)-2]
-	outer_dims_b := other.shape[:len(tensor.shape)-2]
-	if !outer_dims_a.Equals(outer_dims_b) {
-		tensor.Err = errors.New("tensors must have equal outer dims")
-		return tensor
-	}
-	var outer_shape_prod types.Dim = 1
-	for _, dim := range outer_dims_a {
-		outer_shape_prod *= dim
-	}
-	tensors_stack := make([]*Tensor[T], int(outer_shape_prod))
-	shape_iter := CreateIterator(int(outer_shape_prod), outer_dims_a)
+	return tensor.MatMul(other)
+}
 
-	for shape_iter.Iterate() {
-		i := shape_iter.Index()
-		idx := shape_iter.Next()
-		mat_a := tensor.Index(idx...)
-		mat_b := other.Index(idx...)
-		out := mat_a.MatMul(mat_b)
-		tensors_stack[i] = out
+// maps every batch of the broadcasted batch shape to the flat batch index of the operand
+func batch_indices(out_batch, batch types.Shape) []int {
+	var size types.Dim = 1
+	for _, dim := range out_batch {
+		size *= dim
+	}
+	indices := make([]int, size)
+	if len(batch) == 0 {
+		return indices
+	}
+	// operand's batch dims are aligned to the right, broadcasted dims have zero stride
+	strides := make([]int, len(out_batch))
+	stride := 1
+	for i := len(batch) - 1; i >= 0; i-- {
+		j := len(out_batch) - len(batch) + i
+		if batch[i] != 1 {
+			strides[j] = stride
+		}
+		stride *= int(batch[i])
 	}
-
-	out, err := Stack(tensors_stack...)
-	if err != nil {
-		tensor.Err = err
-		return tensor
+	it := CreateIterator(int(size), out_batch)
+	for it.Iterate() {
+		i := it.Index()
+		indices[i] = get_flat_idx_fast(strides, it.Next()...)
 	}
-
-	out_shape := make(types.Shape, len(tensor.shape))
-	copy(out_shape[:len(outer_dims_a)], outer_dims_a)
-	out_shape[len(out_shape)-2] = tensor.shape[len(tensor.shape)-2]
-	out_shape[len(out_shape)-1] = other.shape[len(other.shape)-1]
-
-	return out.Reshape(out_shape...)
+	return indices
 }
 
+// Matrix product of two tensors. Works like numpy matmul:
+//
+// (M,K) @ (K,N) => (M,N)
+//
+// Leading dims are treated as batches and broadcasted: (B,1,M,K) @ (C,K,N) => (B,C,M,N).
+//
+// 1D operands are treated as a row (for the left one) or a column (for the right one) vector,
+// the added dim is removed from the result: (K) @ (B,K,N) => (B,N), (M,K) @ (K) => (M).
 func (tensor *Tensor[T]) MatMul(other *Tensor[T]) *Tensor[T] {
+	if is_half[T]() {
+		return half_binary(tensor, other, func(a, b *Tensor[float32], _ ...*Tensor[float32]) *Tensor[float32] {
+			return a.MatMul(b)
+		}, nil)
+	}
 	if tensor.Err != nil {
 		return tensor
 	}
 	if other.Err != nil {
 		return other
 	}
-	if tensor.DType().Kind() != reflect.Float32 {
-		tensor.Err = errors.New("matMul() only supports tensors of type []float32")
-		return tensor
+	a, b := tensor, other
+	if len(a.shape) == 1 {
+		a = a.View().Unsqueeze(0)
+	}
+	if len(b.shape) == 1 {
+		b = b.View().Unsqueeze(1)
 	}
-	if len(tensor.shape) != 2 || len(other.shape) != 2 {
-		tensor.Err = errors.New("tensors must be two-dim")
+	a_nd, b_nd := len(a.shape), len(b.shape)
+	m, k, n := int(a.shape[a_nd-2]), int(a.shape[a_nd-1]), int(b.shape[b_nd-1])
+	if k != int(b.shape[b_nd-2]) {
+		tensor.Err = fmt.Errorf("tensors inner shapes are different. %v != %v", k, b.shape[b_nd-2])
 		return tensor
 	}
-	if tensor.shape[1] != other.shape[0] {
-		tensor.Err = fmt.Errorf(
-			"tensors inner shapes are different. %v != %v", tensor.shape[1], other.shape[0],
-		)
+	a_batch, b_batch := a.shape[:a_nd-2], b.shape[:b_nd-2]
+	if !a_batch.AreBroadcastable(b_batch) {
+		tensor.Err = fmt.Errorf("batch dims %v and %v are not broadcastable", a_batch, b_batch)
 		return tensor
 	}
-	// if one of tensors is scalar, matmul converges to Mul()
-	if tensor.shape.IsScalarLike() || other.shape.IsScalarLike() {
-		return tensor.Mul(other)
+	out_batch := make(types.Shape, max(len(a_batch), len(b_batch)))
+	for i := range out_batch {
+		var dim_a, dim_b types.Dim = 1, 1
+		if j := i - len(out_batch) + len(a_batch); j >= 0 {
+			dim_a = a_batch[j]
+		}
+		if j := i - len(out_batch) + len(b_batch); j >= 0 {
+			dim_b = b_batch[j]
+		}
+		out_batch[i] = max(dim_a, dim_b)
 	}
-	adim0, bdim1 := tensor.shape[0], other.shape[1]
-	out_data := make([]T, int(adim0*bdim1))
-	out_shape := types.Shape{adim0, bdim1}
 
-	// isVec2Scalar := adim0 == 1 && bdim1 == 1
-
-	tensor = tensor.AsContiguous()
-	// needs to be in column-major format for the AVX support
-	if other.IsContiguous() {
-		other = other.TrC2D()
-	} else {
-		other = other.TrC()
-	}
+	a = a.AsContiguous()
+	b = b.AsContiguous()
 
+	out_shape := append(append(types.Shape{}, out_batch...), types.Dim(m), types.Dim(n))
+	out := CreateEmptyTensor[T](out_shape...)
 	device.MatMul(
 		AUTO_IMPL,
-		tensor.data(),
-		other.data(),
-		out_data,
-		tensor.shape,
-		other.shape,
-		tensor.strides,
-		other.strides,
-		out_shape.GetStrides(),
+		a.data(),
+		b.data(),
+		out.data(),
+		m, k, n,
+		batch_indices(out_batch, a_batch),
+		batch_indices(out_batch, b_batch),
 	)
-	return CreateTensor(out_data, out_shape)
+
+	// remove dims added for 1D operands
+	if len(tensor.shape) == 1 || len(other.shape) == 1 {
+		final_shape := out_shape[:len(out_batch)]
+		if len(tensor.shape) != 1 {
+			final_shape = append(final_shape, types.Dim(m))
+		}
+		if len(other.shape) != 1 {
+			final_shape = append(final_shape, types.Dim(n))
+		}
+		// 1D @ 1D gives a 0-d scalar
+		return out.Reshape(final_shape...)
+	}
+	return out
 }
 
 func SplitTensor[T types.TensorType](
@@ -1,8 +1,11 @@
 package tensor
 
 import (
+	"fmt"
+	"gograd/tensor/internal"
 	"gograd/tensor/internal/device"
 	types "gograd/tensor/types"
+	"slices"
 )
 
 func reduce_shape[T types.TensorType](
@@ -11,7 +14,7 @@ func reduce_shape[T types.TensorType](
 	keep_dims bool,
 ) *Tensor[T] {
 	if !keep_dims {
-		return Scalar[T](value)
+		return scalar(value)
 	} else {
 		ones := make(types.Shape, init_dims)
 		for i := range ones {
@@ -21,88 +24,287 @@ func reduce_shape[T types.TensorType](
 	}
 }
 
-// Example:
-// a = [
-// [1,2],
-// [3,4]]
-// a.SumAlongAxis(0, false) => [4, 6]
-// a.SumAlongAxis(0, true) => [[4, 6]]
-func (tensor *Tensor[T]) SumAlongAxis(
-	axis uint,
+// resolves negative axes, checks bounds and duplicates. Returns sorted axes
+func normalize_axes(shape types.Shape, axes []int) ([]int, error) {
+	norm := make([]int, len(axes))
+	for i, axis := range axes {
+		if axis < -len(shape) || axis >= len(shape) {
+			return nil, fmt.Errorf("axis %v is out of bounds for tensor with %v dims", axis, len(shape))
+		}
+		if axis < 0 {
+			axis += len(shape)
+		}
+		if slices.Contains(norm[:i], axis) {
+			return nil, fmt.Errorf("axis %v is repeated", axes[i])
+		}
+		norm[i] = axis
+	}
+	slices.Sort(norm)
+	return norm, nil
+}
+
+// views the shape as (outer, dim, inner) where dim is a product of dims [from, to)
+func split_shape(shape types.Shape, from, to int) (int, int, int) {
+	outer, dim, inner := 1, 1, 1
+	for i, d := range shape {
+		switch {
+		case i < from:
+			outer *= int(d)
+		case i < to:
+			dim *= int(d)
+		default:
+			inner *= int(d)
+		}
+	}
+	return outer, dim, inner
+}
+
+// all axes of the shape
+func all_axes(shape types.Shape) []int {
+	axes := make([]int, len(shape))
+	for i := range axes {
+		axes[i] = i
+	}
+	return axes
+}
+
+// applies the reduction kernel to the given axes, all axes are reduced if none are given.
+// Adjacent axes are reduced in one pass. Reducing zero-sized axes gives the identity value,
+// reductions without identity (e.g. max) fail on them
+func (tensor *Tensor[T]) reduceAxes(
 	keep_dims bool,
+	axes []int,
+	kernel func(device.Implementation, []T, []T, int, int, int),
+	identity ...T,
 ) *Tensor[T] {
-	if tensor.Err != nil {
+	if len(axes) == 0 {
+		axes = all_axes(tensor.shape)
+	}
+	axes, err := normalize_axes(tensor.shape, axes)
+	if err != nil {
+		tensor.Err = err
 		return tensor
 	}
-	if len(tensor.Shape()) == 2 {
-		tensor = tensor.AsContiguous()
-		out_shape := tensor.Shape().ReduceDim(int(axis))
+	reduced := tensor.AsContiguous()
+	// go from the last axis, so reducing doesn't move the axes which are not processed yet
+	for end := len(axes); end > 0; {
+		start := end - 1
+		for start > 0 && axes[start-1] == axes[start]-1 {
+			start--
+		}
+		from, to := axes[start], axes[end-1]+1
+		outer, dim, inner := split_shape(reduced.shape, from, to)
+
+		out_shape := slices.Clone(reduced.shape)
+		for i := from; i < to; i++ {
+			out_shape[i] = 1
+		}
 		out := CreateEmptyTensor[T](out_shape...)
-		device.SumAxis(AUTO_IMPL, tensor.Data(), out.Data(), tensor.Shape(), int(axis))
-		if !keep_dims {
-			return out.Squeeze()
+		switch {
+		case dim == 0 && len(identity) == 0:
+			tensor.Err = fmt.Errorf("zero-size axes %v of shape %v cannot be reduced", axes, tensor.shape)
+			return tensor
+		case dim == 0:
+			out.Fill(identity[0])
+		case outer*inner > 0:
+			kernel(AUTO_IMPL, reduced.data(), out.data(), outer, dim, inner)
 		}
-		return out
+		reduced = out
+		end = start
+	}
+	if !keep_dims {
+		return reduced.Reshape(drop_axes(reduced.shape, axes)...)
 	}
+	return reduced
+}
 
-	args := make([]*idxRange, len(tensor.Shape()))
-	for i := 0; i < len(tensor.Shape()); i++ {
-		if i == int(axis) {
-			args[i] = I(0)
-			continue
+func drop_axes(shape types.Shape, axes []int) types.Shape {
+	out := make(types.Shape, 0, len(shape))
+	for i, dim := range shape {
+		if !slices.Contains(axes, i) {
+			out = append(out, dim)
 		}
-		args[i] = Axis()
 	}
+	// all axes are reduced to a 0-d scalar
+	return out
+}
 
-	// get sub tensor by axis
-	reduced := tensor.IndexAdv_(args...)
-	dim := int(tensor.Shape()[axis])
-	// iterate over remaining subtensors along axis and sum them
-	for i := 1; i < dim; i++ {
-		args[axis] = I(i)
-		reduced.Add(tensor.IndexAdv_(args...), reduced)
-	}
-	if keep_dims {
-		return reduced.Unsqueeze(int(axis))
-	}
-	return reduced
+// Example:
+// a = [
+// [1,2],
+// [3,4]]
+// a.SumAlongAxis(0, false) => [4, 6]
+// a.SumAlongAxis(0, true) => [[4, 6]]
+func (tensor *Tensor[T]) SumAlongAxis(
+	axis uint,
+	keep_dims bool,
+) *Tensor[T] {
+	return tensor.Sum(keep_dims, int(axis))
 }
 
-func (tensor *Tensor[T]) Sum(keep_dims bool) *Tensor[T] {
+// Sums all elements or sums along given axes. Negative axes are counted from the end.
+//
+// Example:
+// a = [
+// [1,2],
+// [3,4]]
+// a.Sum(false) => [10]
+// a.Sum(false, -1) => [3, 7]
+// a.Sum(true, 0, 1) => [[10]]
+func (tensor *Tensor[T]) Sum(keep_dims bool, axes ...int) *Tensor[T] {
+	if is_half[T]() {
+		return half_apply(tensor, func(t *Tensor[float32]) *Tensor[float32] {
+			return t.Sum(keep_dims, axes...)
+		})
+	}
 	if tensor.Err != nil {
 		return tensor
 	}
+	if len(axes) > 0 || tensor.Size() == 0 {
+		return tensor.reduceAxes(keep_dims, axes, device.SumAxis[T], 0)
+	}
+	tensor = tensor.AsContiguous()
 	sum := []T{0}
 	device.Sum(AUTO_IMPL, tensor.data(), sum)
 	return reduce_shape(len(tensor.Shape()), sum[0], keep_dims)
 }
 
-func (tensor *Tensor[T]) Mean(keep_dims bool) *Tensor[T] {
+// Product of all elements or product along given axes
+func (tensor *Tensor[T]) Prod(keep_dims bool, axes ...int) *Tensor[T] {
+	if is_half[T]() {
+		return half_apply(tensor, func(t *Tensor[float32]) *Tensor[float32] {
+			return t.Prod(keep_dims, axes...)
+		})
+	}
 	if tensor.Err != nil {
 		return tensor
 	}
-	sum := []T{0}
-	device.Sum(AUTO_IMPL, tensor.data(), sum)
+	if len(axes) > 0 || tensor.Size() == 0 {
+		return tensor.reduceAxes(keep_dims, axes, device.ProdAxis[T], 1)
+	}
+	tensor = tensor.AsContiguous()
+	prod := []T{1}
+	device.Prod(AUTO_IMPL, tensor.data(), prod)
+	return reduce_shape(len(tensor.Shape()), prod[0], keep_dims)
+}
 
-	size := len(tensor.data())
-	_mean := T(float64(sum[0]) / float64(size))
-	return reduce_shape(len(tensor.Shape()), _mean, keep_dims)
+// Mean of all elements or mean along given axes.
+// Values are accumulated in float64 using pairwise summation, so float32 sums don't lose precision
+func (tensor *Tensor[T]) Mean(keep_dims bool, axes ...int) *Tensor[T] {
+	if is_half[T]() {
+		return half_apply(tensor, func(t *Tensor[float32]) *Tensor[float32] {
+			return t.Mean(keep_dims, axes...)
+		})
+	}
+	if tensor.Err != nil {
+		return tensor
+	}
+	r, err := tensor.reduction(axes)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	if r.dim == 0 && !internal.IsFloat[T]() {
+		// mean of the empty lane is NaN, integers can't hold it
+		tensor.Err = fmt.Errorf("mean of zero-size axes of shape %v is not defined for integers", tensor.shape)
+		return tensor
+	}
+	sum := make([]float64, r.lanes())
+	internal.SumAxisFloat64(r.data, sum, r.outer, r.dim, r.inner, func(_ int, v T) float64 { return float64(v) })
+	values := make([]T, len(sum))
+	for i, v := range sum {
+		values[i] = T(v / float64(r.dim))
+	}
+	return r.result(values, keep_dims)
 }
 
-func (tensor *Tensor[T]) Max(keep_dims bool) *Tensor[T] {
+// Max of all elements or max along given axes
+func (tensor *Tensor[T]) Max(keep_dims bool, axes ...int) *Tensor[T] {
+	if is_half[T]() {
+		return half_apply(tensor, func(t *Tensor[float32]) *Tensor[float32] {
+			return t.Max(keep_dims, axes...)
+		})
+	}
 	if tensor.Err != nil {
 		return tensor
 	}
+	if len(axes) > 0 || tensor.Size() == 0 {
+		return tensor.reduceAxes(keep_dims, axes, device.MaxAxis[T])
+	}
+	tensor = tensor.AsContiguous()
 	max := []T{tensor.data()[0]}
 	device.Max(AUTO_IMPL, tensor.data(), max)
 	return reduce_shape(len(tensor.Shape()), max[0], keep_dims)
 }
 
-func (tensor *Tensor[T]) Min(keep_dims bool) *Tensor[T] {
+// Min of all elements or min along given axes
+func (tensor *Tensor[T]) Min(keep_dims bool, axes ...int) *Tensor[T] {
+	if is_half[T]() {
+		return half_apply(tensor, func(t *Tensor[float32]) *Tensor[float32] {
+			return t.Min(keep_dims, axes...)
+		})
+	}
 	if tensor.Err != nil {
 		return tensor
 	}
+	if len(axes) > 0 || tensor.Size() == 0 {
+		return tensor.reduceAxes(keep_dims, axes, device.MinAxis[T])
+	}
+	tensor = tensor.AsContiguous()
 	min := []T{tensor.data()[0]}
 	device.Min(AUTO_IMPL, tensor.data(), min)
 	return reduce_shape(len(tensor.Shape()), min[0], keep_dims)
 }
+
+func (tensor *Tensor[T]) argReduce(
+	axis int,
+	keep_dims bool,
+	kernel func(device.Implementation, []T, []int, int, int, int),
+) *Tensor[int] {
+	if tensor.Err != nil {
+		return &Tensor[int]{Err: tensor.Err}
+	}
+	axes, err := normalize_axes(tensor.shape, []int{axis})
+	if err != nil {
+		return &Tensor[int]{Err: err}
+	}
+	axis = axes[0]
+	tensor = tensor.AsContiguous()
+	outer, dim, inner := split_shape(tensor.shape, axis, axis+1)
+	if dim == 0 {
+		return &Tensor[int]{Err: fmt.Errorf("zero-size axis %v of shape %v cannot be reduced", axis, tensor.shape)}
+	}
+	out_shape := slices.Clone(tensor.shape)
+	out_shape[axis] = 1
+	out := CreateEmptyTensor[int](out_shape...)
+	if outer*inner > 0 {
+		kernel(AUTO_IMPL, tensor.data(), out.data(), outer, dim, inner)
+	}
+	if !keep_dims {
+		return out.Reshape(drop_axes(out_shape, axes)...)
+	}
+	return out
+}
+
+// Returns indices of the max elements along the axis. The first occurrence is taken on ties.
+//
+// Example:
+// a = [
+// [1,5,2],
+// [7,3,7]]
+// a.ArgMax(-1, false) => [1, 0]
+// a.ArgMax(0, true) => [[1, 0, 1]]
+func (tensor *Tensor[T]) ArgMax(axis int, keep_dims bool) *Tensor[int] {
+	if is_half[T]() && tensor.Err == nil {
+		return half_to_float32(tensor).ArgMax(axis, keep_dims)
+	}
+	return tensor.argReduce(axis, keep_dims, device.ArgMaxAxis[T])
+}
+
+// Returns indices of the min elements along the axis. The first occurrence is taken on ties.
+func (tensor *Tensor[T]) ArgMin(axis int, keep_dims bool) *Tensor[int] {
+	if is_half[T]() && tensor.Err == nil {
+		return half_to_float32(tensor).ArgMin(axis, keep_dims)
+	}
+	return tensor.argReduce(axis, keep_dims, device.ArgMinAxis[T])
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"gograd/tensor/internal"
 	types "gograd/tensor/types"
+	"slices"
 	"sync"
 )
 
@@ -22,10 +23,11 @@ func (tensor *Tensor[T]) Flatten(out *Tensor[T]) *Tensor[T] {
 		tensor.Err = err
 		return tensor
 	}
-	if tensor != outTensor {
-		outTensor.SetData(tensor.data())
+	src := tensor.AsContiguous()
+	if tensor != outTensor || src != tensor {
+		outTensor.SetData(src.data())
 	}
-	outTensor.shape = types.Shape{types.Dim(len(tensor.data()))}
+	outTensor.shape = types.Shape{types.Dim(tensor.Size())}
 	outTensor.strides = outTensor.shape.GetStrides()
 	outTensor.dim_order = outTensor.shape.InitDimOrder()
 	return outTensor
@@ -41,9 +43,18 @@ func (tensor *Tensor[T]) Squeeze() *Tensor[T] {
 	if tensor.shape.IsScalarLike() {
 		return tensor
 	}
-	tensor.shape = tensor.shape.Squeeze()
-	tensor.strides = tensor.shape.GetStrides()
-	tensor.dim_order = tensor.shape.InitDimOrder()
+	// keep strides of remaining dims so the views are not broken
+	shape := make(types.Shape, 0, len(tensor.shape))
+	strides := make([]int, 0, len(tensor.shape))
+	for i, dim := range tensor.shape {
+		if dim != 1 {
+			shape = append(shape, dim)
+			strides = append(strides, tensor.strides[i])
+		}
+	}
+	tensor.shape = shape
+	tensor.strides = strides
+	tensor.dim_order = orderFromStrides(strides)
 	return tensor
 }
 
@@ -55,41 +66,84 @@ func (tensor *Tensor[T]) Unsqueeze(axis int) *Tensor[T] {
 		return tensor
 	}
 	if axis < 0 {
-		axis = len(tensor.shape) - axis
+		axis = len(tensor.shape) + axis + 1
 		if axis < 0 {
 			tensor.Err = fmt.Errorf("axis %v is not valid", axis)
 			return tensor
 		}
 	}
-	tensor.shape = tensor.shape.AddDim(uint(axis))
-	tensor.strides = tensor.shape.GetStrides()
-	tensor.dim_order = tensor.shape.InitDimOrder()
+	if axis > len(tensor.shape) {
+		tensor.Err = fmt.Errorf("axis %v is out of bounds for %v dims", axis, len(tensor.shape))
+		return tensor
+	}
+	// new dim is one-sized, so its stride can be any. Take the stride of the next outer dim
+	stride := 1
+	if axis < len(tensor.shape) {
+		stride = tensor.strides[axis] * int(tensor.shape[axis])
+	}
+	strides := append(append(append([]int(nil), tensor.strides[:axis]...), stride), tensor.strides[axis:]...)
+	tensor.shape = append(types.Shape(nil), tensor.shape...).AddDim(uint(axis))
+	tensor.strides = strides
+	tensor.dim_order = orderFromStrides(strides)
 	return tensor
 }
 
+// Reshapes the tensor. One of the dims can be -1, it is inferred from the size and the other dims.
+// Contiguous tensors (and views) are reshaped inplace and keep sharing the memory,
+// non-contiguous ones are materialized first.
+//
+// Example:
+// (2,3,4).Reshape(6,-1) => (6,4)
+// (2,3,4).Reshape(-1) => (24)
 func (tensor *Tensor[T]) Reshape(newShape ...types.Dim) *Tensor[T] {
 	if tensor.Err != nil {
 		return tensor
 	}
-	var new_shape_prod types.Dim = 1
-	for _, dim := range newShape {
-		new_shape_prod *= dim
-	}
-	if new_shape_prod == 0 {
-		tensor.Err = errors.New("shape cannot have 0 dim size")
+	sh, err := infer_shape(int(tensor.Size()), newShape)
+	if err != nil {
+		tensor.Err = err
 		return tensor
 	}
-	if len(tensor.data()) != int(new_shape_prod) {
-		tensor.Err = fmt.Errorf("cannot reshape tensor with size %v to shape %v", len(tensor.data()), newShape)
-		return tensor
+	if !tensor.IsContiguous() {
+		tensor.data_buff = tensor.AsContiguous().data()
+		tensor.offset = 0
 	}
-	sh := types.Shape(newShape)
-	tensor.shape = newShape
+	tensor.shape = sh
 	tensor.strides = sh.GetStrides()
 	tensor.dim_order = sh.InitDimOrder()
 	return tensor
 }
 
+// resolves -1 dim of the shape, so it has the given size
+func infer_shape(size int, shape types.Shape) (types.Shape, error) {
+	shape = append(types.Shape(nil), shape...)
+	inferred := -1
+	known := 1
+	for i, dim := range shape {
+		switch {
+		case dim == -1 && inferred >= 0:
+			return nil, fmt.Errorf("only one dim can be inferred, got shape %v", shape)
+		case dim == -1:
+			inferred = i
+		case dim < 0:
+			return nil, fmt.Errorf("shape %v cannot have negative dims", shape)
+		default:
+			known *= int(dim)
+		}
+	}
+	if inferred >= 0 {
+		if known == 0 || size%known != 0 {
+			return nil, fmt.Errorf("cannot reshape tensor with size %v to shape %v", size, shape)
+		}
+		shape[inferred] = types.Dim(size / known)
+		known = size
+	}
+	if known != size {
+		return nil, fmt.Errorf("cannot reshape tensor with size %v to shape %v", size, shape)
+	}
+	return shape, nil
+}
+
 // Transposes tensor to given axes. If no axes are set, default transposing applied
 func (tensor *Tensor[T]) T(axes ...uint) *Tensor[T] {
 	if tensor.Err != nil {
@@ -126,7 +180,8 @@ func (tensor *Tensor[T]) T(axes ...uint) *Tensor[T] {
 		}
 	}
 
-	outTensor := CreateTensor(tensor.data(), tensor.shape)
+	// transposed tensor is a view with permuted shape & strides
+	outTensor := tensor.View()
 
 	for i, axis := range axes {
 		outTensor.shape[i] = tensor.shape[axis]
@@ -183,36 +238,133 @@ func (tensor *Tensor[T]) TrC2D() *Tensor[T] {
 	return CreateTensorNoCopy[T](transposed, types.Shape{sh[1], sh[0]})
 }
 
-// stacks tensors together. All tensors should have the same shape
-func Stack[T types.TensorType](tensors ...*Tensor[T]) (*Tensor[T], error) {
+// joins tensors along an existing axis. All dims except the axis must be the same.
+// Negative axis is counted from the end.
+//
+// Example:
+// Concat(0, (2,3), (1,3)) => (3,3)
+// Concat(-1, (2,3), (2,1)) => (2,4)
+func Concat[T types.TensorType](axis int, tensors ...*Tensor[T]) (*Tensor[T], error) {
 	if len(tensors) < 1 {
 		return nil, errors.New("at least 1 tensor is required")
 	}
-	if len(tensors) == 1 {
-		return tensors[0], nil
-	}
-
-	// validate shapes
-	other_shapes := make([]types.Shape, len(tensors))
+	shapes := make([]types.Shape, len(tensors))
 	for i, tensor := range tensors {
-		other_shapes[i] = tensor.Shape()
+		if tensor.Err != nil {
+			return nil, tensor.Err
+		}
+		shapes[i] = tensor.Shape()
 	}
-	united_shape, err := types.StackShapes(0, other_shapes...)
+	united_shape, err := types.StackShapes(axis, shapes...)
 	if err != nil {
 		return nil, err
 	}
+	if axis < 0 {
+		axis += len(united_shape)
+	}
 
 	united := CreateEmptyTensor[T](united_shape...)
-	prev_position := 0
-	for i := 0; i < len(tensors); i++ {
-		tensor := tensors[i]
-		size := len(tensor.data())
-		copy(united.data()[prev_position:prev_position+size], tensor.data())
-		prev_position += size
+	start := 0
+	for _, tensor := range tensors {
+		end := start + int(tensor.shape[axis])
+		// writes the tensor to the region of the united tensor
+		region := united.sliceAxis(axis, start, end)
+		region.copyFromContiguous(tensor.AsContiguous())
+		start = end
 	}
 	return united, nil
 }
 
+// joins tensors along a new axis. All tensors should have the same shape.
+//
+// Example:
+// Stack(0, (2,3), (2,3)) => (2,2,3)
+// Stack(-1, (2,3), (2,3)) => (2,3,2)
+func Stack[T types.TensorType](axis int, tensors ...*Tensor[T]) (*Tensor[T], error) {
+	if len(tensors) < 1 {
+		return nil, errors.New("at least 1 tensor is required")
+	}
+	expanded := make([]*Tensor[T], len(tensors))
+	for i, tensor := range tensors {
+		if tensor.Err != nil {
+			return nil, tensor.Err
+		}
+		if !tensor.shape.Equals(tensors[0].shape) {
+			return nil, fmt.Errorf("all tensors must have the same shape, got %v and %v", tensors[0].shape, tensor.shape)
+		}
+		expanded[i] = tensor.View().Unsqueeze(axis)
+		if expanded[i].Err != nil {
+			return nil, expanded[i].Err
+		}
+	}
+	return Concat(axis, expanded...)
+}
+
+// view of the tensor with the [start, end) range along the axis
+func (tensor *Tensor[T]) sliceAxis(axis, start, end int) *Tensor[T] {
+	shape := slices.Clone(tensor.shape)
+	shape[axis] = types.Dim(end - start)
+	first := tensor.offset + start*tensor.strides[axis]
+	return tensor.makeView(first, shape, slices.Clone(tensor.strides))
+}
+
+// Splits the tensor along the axis into parts with given sizes. Sizes must sum up to the axis dim,
+// zero sizes give empty parts. Parts are views, so they share the memory with the tensor.
+//
+// Example:
+// (5,3).Split([]int{2,3}, 0) => (2,3), (3,3)
+func (tensor *Tensor[T]) Split(sizes []int, axis int) ([]*Tensor[T], error) {
+	if tensor.Err != nil {
+		return nil, tensor.Err
+	}
+	axes, err := normalize_axes(tensor.shape, []int{axis})
+	if err != nil {
+		return nil, err
+	}
+	axis = axes[0]
+	total := 0
+	for _, size := range sizes {
+		if size < 0 {
+			return nil, fmt.Errorf("split sizes must not be negative, got %v", sizes)
+		}
+		total += size
+	}
+	if total != int(tensor.shape[axis]) {
+		return nil, fmt.Errorf("split sizes %v do not sum up to the dim %v of axis %v", sizes, tensor.shape[axis], axis)
+	}
+	parts := make([]*Tensor[T], len(sizes))
+	start := 0
+	for i, size := range sizes {
+		parts[i] = tensor.sliceAxis(axis, start, start+size)
+		start += size
+	}
+	return parts, nil
+}
+
+// Splits the tensor along the axis into n parts of equal size.
+// If the dim is not divisible by n, the last part is smaller. Fewer than n parts can be returned
+// if the dim is too small, e.g. dim 5 is chunked into 3 parts as (2,2,1), dim 2 into 3 parts as (1,1).
+// Parts are views, so they share the memory with the tensor.
+func (tensor *Tensor[T]) Chunk(n int, axis int) ([]*Tensor[T], error) {
+	if tensor.Err != nil {
+		return nil, tensor.Err
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("number of chunks must be positive, got %v", n)
+	}
+	axes, err := normalize_axes(tensor.shape, []int{axis})
+	if err != nil {
+		return nil, err
+	}
+	dim := int(tensor.shape[axes[0]])
+	chunk_size := (dim + n - 1) / n
+	sizes := make([]int, 0, n)
+	for start := 0; start < dim; start += chunk_size {
+		sizes = append(sizes, min(chunk_size, dim-start))
+	}
+	return tensor.Split(sizes, axis)
+}
+
 // tensor list logic
 type TensorList[T types.TensorType] struct {
 	StackedTensors *Tensor[T]
@@ -224,7 +376,7 @@ func (tlist *TensorList[T]) Append(new_tensor *Tensor[T]) {
 		tlist.StackedTensors = new_tensor.Copy()
 		return
 	}
-	upd, err := Stack[T](tlist.StackedTensors, new_tensor)
+	upd, err := Concat[T](0, tlist.StackedTensors, new_tensor)
 	if err != nil {
 		new_tensor.Err = err
 		return
@@ -13,6 +13,8 @@ func (tensor *Tensor[T]) EncodeToBytes() []byte {
 
 	m := make(map[string][]T)
 
+	// views are stored as contiguous tensors
+	tensor = tensor.AsContiguous()
 	ndims := len(tensor.Shape())
 	shape := make([]T, ndims)
 	strides := make([]T, ndims)
@@ -26,7 +28,7 @@ func (tensor *Tensor[T]) EncodeToBytes() []byte {
 	m["shape"] = shape
 	m["strides"] = strides
 	m["dimord"] = dimord
-	m["data"] = tensor.Data()
+	m["data"] = tensor.data()
 
 	if err := enc.Encode(m); err != nil {
 		log.Fatal(err)
@@ -1,55 +1,291 @@
 package tensor
 
-import types "gograd/tensor/types"
-
-func bubbleSort[T types.TensorType](slice []T) {
-	n := len(slice)
-	for i := 0; i < n-1; i++ {
-		for j := 0; j < n-i-1; j++ {
-			if slice[j] > slice[j+1] {
-				slice[j+1], slice[j] = slice[j], slice[j+1]
+import (
+	"fmt"
+	"gograd/tensor/internal"
+	types "gograd/tensor/types"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// NaN is considered greater than any other value, so it is placed last in the ascending order
+func compare_values[T types.TensorType](a, b T) int {
+	a_nan, b_nan := a != a, b != b
+	switch {
+	case a_nan || b_nan:
+		if a_nan == b_nan {
+			return 0
+		}
+		if a_nan {
+			return 1
+		}
+		return -1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// short prefixes of long lanes are selected with a heap instead of sorting the whole lane
+const partial_sort_ratio = 8
+
+// positions of the first k elements of a lane in the order given by cmp.
+// cmp must be a total order, order is a buffer of the lane size
+func select_first(order []int, k int, cmp func(p, q int) int) []int {
+	dim := len(order)
+	if k*partial_sort_ratio >= dim {
+		for p := range order {
+			order[p] = p
+		}
+		slices.SortFunc(order, cmp)
+		return order[:k]
+	}
+	// the root of the heap is the last of the selected positions
+	heap := order[:k]
+	for p := range heap {
+		heap[p] = p
+	}
+	sift := func(root int) {
+		for {
+			child := 2*root + 1
+			if child >= k {
+				return
+			}
+			if child+1 < k && cmp(heap[child+1], heap[child]) > 0 {
+				child++
 			}
+			if cmp(heap[child], heap[root]) <= 0 {
+				return
+			}
+			heap[root], heap[child] = heap[child], heap[root]
+			root = child
+		}
+	}
+	for root := k/2 - 1; root >= 0; root-- {
+		sift(root)
+	}
+	for p := k; p < dim; p++ {
+		if cmp(p, heap[0]) < 0 {
+			heap[0] = p
+			sift(0)
 		}
 	}
+	slices.SortFunc(heap, cmp)
+	return heap
+}
+
+// sorts every lane along the axis and keeps the first k positions of it.
+// Equal values keep their order. Returns the sorted values if with_values is set and their positions along the axis
+func (tensor *Tensor[T]) sort_lanes(axis int, descending bool, k int, with_values bool) (*Tensor[T], *Tensor[int], error) {
+	if tensor.Err != nil {
+		return nil, nil, tensor.Err
+	}
+	if is_half[T]() {
+		// bits of 16-bit floats aren't ordered like the values, so the positions are found in float32
+		_, indices, err := half_to_float32(tensor).sort_lanes(axis, descending, k, false)
+		if err != nil || !with_values {
+			return nil, indices, err
+		}
+		values := tensor.TakeAlongAxis(indices, axis)
+		return values, indices, values.Err
+	}
+	axes, err := normalize_axes(tensor.shape, []int{axis})
+	if err != nil {
+		return nil, nil, err
+	}
+	axis = axes[0]
+	dim := int(tensor.shape[axis])
+	if k < 0 {
+		k = dim
+	}
+	// an empty axis can only yield an empty selection
+	if (k < 1 && dim > 0) || k > dim {
+		return nil, nil, fmt.Errorf("k must be in range [1, %v], got %v", dim, k)
+	}
+	data := tensor.AsContiguous().data()
+	outer, _, inner := split_shape(tensor.shape, axis, axis+1)
+
+	out_shape := slices.Clone(tensor.shape)
+	out_shape[axis] = types.Dim(k)
+	indices := CreateEmptyTensor[int](out_shape...)
+	var values *Tensor[T]
+	if with_values {
+		values = CreateEmptyTensor[T](out_shape...)
+	}
+	if k == 0 {
+		return values, indices, nil
+	}
+	internal.Parallel(outer*inner,
+		func(start, end int, _, _, _ []int, mu *sync.Mutex) {
+			order := make([]int, dim)
+			for j := start; j < end; j++ {
+				o, i := j/inner, j%inner
+				lane := data[o*dim*inner+i:]
+				cmp := func(p, q int) int {
+					c := compare_values(lane[p*inner], lane[q*inner])
+					if descending {
+						c = -c
+					}
+					if c == 0 {
+						// ties are ordered by position, which makes the sort stable
+						return p - q
+					}
+					return c
+				}
+				out_base := o*k*inner + i
+				for p, pos := range select_first(order, k, cmp) {
+					indices.data()[out_base+p*inner] = pos
+					if with_values {
+						values.data()[out_base+p*inner] = lane[pos*inner]
+					}
+				}
+			}
+		}, nil, nil, nil)
+	return values, indices, nil
 }
 
-func quickSort[T types.TensorType](arr []T, low int, high int) {
-	if low < high {
-		/* pi is partitioning index, arr[pi] is now at right place */
-		pi := qsPartition(arr, low, high)
-		quickSort(arr, low, pi-1)
-		quickSort(arr, pi+1, high)
+// Sorts the tensor along the axis. Negative axis is counted from the end.
+// The sort is stable, NaNs are placed last (first if descending).
+//
+// Example:
+// a = [
+// [3,1,2],
+// [0,5,4]]
+// a.Sort(1, false) => [[1,2,3],[0,4,5]]
+// a.Sort(0, true) => [[3,5,4],[0,1,2]]
+func (tensor *Tensor[T]) Sort(axis int, descending bool) *Tensor[T] {
+	values, _, err := tensor.sort_lanes(axis, descending, -1, true)
+	if err != nil {
+		tensor.Err = err
+		return tensor
 	}
+	return values
 }
 
-/*
-This function takes last element as pivot, places the pivot element
-at its correct position in sorted array, and places all smaller
-(smaller than pivot) to left of pivot and all greater elements to right of pivot
-*/
-func qsPartition[T types.TensorType](arr []T, low int, high int) int {
-	// pivot (Element to be placed at right position)
-	pivot := arr[high]
-	i := (low - 1) // Index of smaller element and indicates the
-	// right position of pivot found so far
+// Positions which sort the tensor along the axis. Stable, so equal values keep their order.
+//
+// Example:
+// a = [3,1,2,1]
+// a.ArgSort(0, false) => [1,3,2,0]
+// a.ArgSort(0, true) => [0,2,1,3]
+func (tensor *Tensor[T]) ArgSort(axis int, descending bool) *Tensor[int] {
+	_, indices, err := tensor.sort_lanes(axis, descending, -1, false)
+	if err != nil {
+		return &Tensor[int]{Err: err}
+	}
+	return indices
+}
+
+// k largest values along the axis in the descending order and their positions.
+// The first position is taken on ties, NaN is the largest value.
+//
+// Example:
+// a = [
+// [1,5,3,5],
+// [4,0,2,6]]
+// a.TopK(2, 1) => values [[5,5],[6,4]], indices [[1,3],[3,0]]
+func (tensor *Tensor[T]) TopK(k, axis int) (*Tensor[T], *Tensor[int]) {
+	if k < 0 {
+		err := fmt.Errorf("k must be positive, got %v", k)
+		return &Tensor[T]{Err: err}, &Tensor[int]{Err: err}
+	}
+	values, indices, err := tensor.sort_lanes(axis, true, k, true)
+	if err != nil {
+		return &Tensor[T]{Err: err}, &Tensor[int]{Err: err}
+	}
+	return values, indices
+}
 
-	for j := low; j <= high-1; j++ {
-		// If current element is smaller than the pivot
-		if arr[j] < pivot {
-			i++ // increment index of smaller element
-			arr[i], arr[j] = arr[j], arr[i]
+// Sorted unique values of the flattened tensor.
+// inverse has the shape of the tensor and holds the position of every value in values,
+// counts holds the number of occurrences of every unique value. All NaNs are counted as one value.
+//
+// Example:
+// a = [[2,1],[2,3]]
+// values, inverse, counts := a.Unique()
+// values => [1,2,3], inverse => [[1,0],[1,2]], counts => [1,2,1]
+func (tensor *Tensor[T]) Unique() (*Tensor[T], *Tensor[int], *Tensor[int]) {
+	if tensor.Err != nil {
+		return tensor, &Tensor[int]{Err: tensor.Err}, &Tensor[int]{Err: tensor.Err}
+	}
+	if is_half[T]() {
+		values, inverse, counts := half_to_float32(tensor).Unique()
+		if values.Err != nil {
+			return &Tensor[T]{Err: values.Err}, inverse, counts
+		}
+		return float32_to_half[T](values), inverse, counts
+	}
+	flat := tensor.AsContiguous().View().Reshape(types.Dim(tensor.Size()))
+	_, order, err := flat.sort_lanes(0, false, -1, false)
+	if err != nil {
+		return &Tensor[T]{Err: err}, &Tensor[int]{Err: err}, &Tensor[int]{Err: err}
+	}
+	data := flat.data()
+	inverse := CreateEmptyTensor[int](tensor.shape...)
+	unique := make([]T, 0)
+	counts := make([]int, 0)
+	for _, pos := range order.data() {
+		v := data[pos]
+		if len(unique) == 0 || compare_values(unique[len(unique)-1], v) != 0 {
+			unique = append(unique, v)
+			counts = append(counts, 0)
 		}
+		counts[len(counts)-1]++
+		inverse.data()[pos] = len(unique) - 1
 	}
-	arr[i+1], arr[high] = arr[high], arr[i+1]
-	return i + 1
+	return CreateTensor(unique, types.Shape{types.Dim(len(unique))}),
+		inverse,
+		CreateTensor(counts, types.Shape{types.Dim(len(counts))})
 }
 
-func (tensor *Tensor[T]) Sort() *Tensor[T] {
-	data := tensor.data()
-	if len(data) <= 1 {
-		return tensor
+// Positions where values must be inserted into the tensor sorted along the last axis to keep it sorted.
+// If right is false the first suitable position is returned, otherwise the last one.
+// 1D tensor accepts values of any shape, otherwise leading dims of values must match the tensor's.
+// The result has the shape of values.
+//
+// Example:
+// a = [1,3,3,5]
+// a.SearchSorted([[3],[6]], false) => [[1],[4]]
+// a.SearchSorted([[3],[6]], true) => [[3],[4]]
+func (tensor *Tensor[T]) SearchSorted(values *Tensor[T], right bool) *Tensor[int] {
+	if tensor.Err != nil {
+		return &Tensor[int]{Err: tensor.Err}
 	}
-	outTensor := tensor.Copy()
-	quickSort(outTensor.data(), 0, len(tensor.data())-1)
-	return outTensor
+	if values.Err != nil {
+		return &Tensor[int]{Err: values.Err}
+	}
+	if is_half[T]() {
+		return half_to_float32(tensor).SearchSorted(half_to_float32(values), right)
+	}
+	ndim := len(tensor.shape)
+	// number of values searched in every lane of the tensor
+	lane_values := int(values.Size())
+	if ndim > 1 {
+		if len(values.shape) != ndim || !values.shape[:ndim-1].Equals(tensor.shape[:ndim-1]) {
+			return &Tensor[int]{Err: fmt.Errorf(
+				"leading dims of values %v must match the sorted tensor %v", values.shape, tensor.shape)}
+		}
+		lane_values = int(values.shape[ndim-1])
+	}
+	sorted := tensor.AsContiguous().data()
+	data := values.AsContiguous().data()
+	dim := int(tensor.shape[ndim-1])
+	out := CreateEmptyTensor[int](values.shape...)
+	internal.Parallel(len(data),
+		func(start, end int, _, _, _ []int, mu *sync.Mutex) {
+			for j := start; j < end; j++ {
+				lane := sorted[j/lane_values*dim : (j/lane_values+1)*dim]
+				v := data[j]
+				out.data()[j] = sort.Search(dim, func(p int) bool {
+					if right {
+						return compare_values(lane[p], v) > 0
+					}
+					return compare_values(lane[p], v) >= 0
+				})
+			}
+		}, nil, nil, nil)
+	return out
 }
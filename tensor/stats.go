@@ -0,0 +1,414 @@
+package tensor
+
+import (
+	"errors"
+	"fmt"
+	"gograd/tensor/internal"
+	types "gograd/tensor/types"
+	"math"
+	"slices"
+)
+
+// tensor data viewed as (outer, dim, inner), where dim is the product of the reduced dims
+type reduction[T types.TensorType] struct {
+	data              []T
+	outer, dim, inner int
+	shape             types.Shape
+	axes              []int
+}
+
+// prepares the reduction along the axes, all axes are reduced if none is given.
+// Not adjacent axes are moved to the end, so they become one dim
+func (tensor *Tensor[T]) reduction(axes []int) (*reduction[T], error) {
+	if len(axes) == 0 {
+		axes = make([]int, len(tensor.shape))
+		for i := range axes {
+			axes[i] = i
+		}
+	}
+	axes, err := normalize_axes(tensor.shape, axes)
+	if err != nil {
+		return nil, err
+	}
+	r := &reduction[T]{shape: tensor.shape, axes: axes}
+	if len(axes) == 0 {
+		// 0-d tensor is a single lane with one value
+		r.data = tensor.AsContiguous().data()
+		r.outer, r.dim, r.inner = 1, 1, 1
+		return r, nil
+	}
+	if axes[len(axes)-1]-axes[0] == len(axes)-1 {
+		r.data = tensor.AsContiguous().data()
+		r.outer, r.dim, r.inner = split_shape(tensor.shape, axes[0], axes[len(axes)-1]+1)
+		return r, nil
+	}
+	perm := make([]uint, 0, len(tensor.shape))
+	for i := range tensor.shape {
+		if !slices.Contains(axes, i) {
+			perm = append(perm, uint(i))
+		}
+	}
+	kept := len(perm)
+	for _, axis := range axes {
+		perm = append(perm, uint(axis))
+	}
+	moved := tensor.View().T(perm...).AsContiguous()
+	r.data = moved.data()
+	r.outer, r.dim, r.inner = split_shape(moved.shape, kept, len(moved.shape))
+	return r, nil
+}
+
+// number of the reduced lanes, it is the size of the result
+func (r *reduction[T]) lanes() int {
+	return r.outer * r.inner
+}
+
+// wraps the reduced values into a tensor of the result shape
+func (r *reduction[T]) result(values []T, keep_dims bool) *Tensor[T] {
+	if !keep_dims {
+		return CreateTensorNoCopy(values, drop_axes(r.shape, r.axes))
+	}
+	shape := slices.Clone(r.shape)
+	for _, axis := range r.axes {
+		shape[axis] = 1
+	}
+	return CreateTensorNoCopy(values, shape)
+}
+
+// Variance of all elements or along given axes: sum((x - mean)^2) / (N - ddof).
+// Use ddof 0 for the population variance and 1 for the sample variance.
+// Values are accumulated in float64 with two passes.
+//
+// Example:
+// a = [
+// [1,2],
+// [3,6]]
+// a.Var(0, false) => [3.5]
+// a.Var(1, false, 0) => [2, 8]
+func (tensor *Tensor[T]) Var(ddof int, keep_dims bool, axes ...int) *Tensor[T] {
+	if is_half[T]() {
+		return half_apply(tensor, func(t *Tensor[float32]) *Tensor[float32] {
+			return t.Var(ddof, keep_dims, axes...)
+		})
+	}
+	if tensor.Err != nil {
+		return tensor
+	}
+	r, m2, err := tensor.moments(ddof, axes)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	values := make([]T, len(m2))
+	for i, v := range m2 {
+		values[i] = T(v / float64(r.dim-ddof))
+	}
+	return r.result(values, keep_dims)
+}
+
+// Standard deviation of all elements or along given axes, the square root of Var
+func (tensor *Tensor[T]) Std(ddof int, keep_dims bool, axes ...int) *Tensor[T] {
+	if is_half[T]() {
+		return half_apply(tensor, func(t *Tensor[float32]) *Tensor[float32] {
+			return t.Std(ddof, keep_dims, axes...)
+		})
+	}
+	if tensor.Err != nil {
+		return tensor
+	}
+	r, m2, err := tensor.moments(ddof, axes)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	values := make([]T, len(m2))
+	for i, v := range m2 {
+		values[i] = T(math.Sqrt(v / float64(r.dim-ddof)))
+	}
+	return r.result(values, keep_dims)
+}
+
+// sums of squared deviations from the mean along the axes
+func (tensor *Tensor[T]) moments(ddof int, axes []int) (*reduction[T], []float64, error) {
+	r, err := tensor.reduction(axes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ddof < 0 || ddof >= r.dim {
+		return nil, nil, fmt.Errorf("ddof must be in range [0, %v), got %v", r.dim, ddof)
+	}
+	mean, m2 := make([]float64, r.lanes()), make([]float64, r.lanes())
+	internal.MomentsAxisMatx(r.data, mean, m2, r.outer, r.dim, r.inner)
+	return r, m2, nil
+}
+
+// Interpolation used by Quantile when the quantile lies between two values
+type QuantileMethod int
+
+const (
+	// i + (j - i) * fraction
+	QuantileLinear QuantileMethod = iota
+	// i
+	QuantileLower
+	// j
+	QuantileHigher
+	// i or j whichever is nearest, the even one on ties
+	QuantileNearest
+	// (i + j) / 2
+	QuantileMidpoint
+)
+
+// q-th quantile of sorted values, NaN if there are none
+func quantile(sorted []float64, q float64, method QuantileMethod) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	pos := q * float64(len(sorted)-1)
+	lo, hi := int(math.Floor(pos)), int(math.Ceil(pos))
+	switch method {
+	case QuantileLower:
+		return sorted[lo]
+	case QuantileHigher:
+		return sorted[hi]
+	case QuantileNearest:
+		return sorted[int(math.RoundToEven(pos))]
+	case QuantileMidpoint:
+		return (sorted[lo] + sorted[hi]) / 2
+	}
+	if lo == hi {
+		return sorted[lo]
+	}
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// q-th quantile of all elements or along given axes, q is in range [0, 1].
+// The method sets the interpolation between two values, see QuantileMethod.
+// Result is NaN for lanes with NaN values and for empty lanes.
+//
+// Example:
+// a = [1,2,3,4]
+// a.Quantile(0.5, QuantileLinear, false) => [2.5]
+// a.Quantile(0.5, QuantileLower, false) => [2]
+func (tensor *Tensor[T]) Quantile(q float64, method QuantileMethod, keep_dims bool, axes ...int) *Tensor[T] {
+	if is_half[T]() {
+		return half_apply(tensor, func(t *Tensor[float32]) *Tensor[float32] {
+			return t.Quantile(q, method, keep_dims, axes...)
+		})
+	}
+	if tensor.Err != nil {
+		return tensor
+	}
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		tensor.Err = fmt.Errorf("quantile must be in range [0, 1], got %v", q)
+		return tensor
+	}
+	if method < QuantileLinear || method > QuantileMidpoint {
+		tensor.Err = fmt.Errorf("unknown quantile method %v", method)
+		return tensor
+	}
+	r, err := tensor.reduction(axes)
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	if r.dim == 0 && !internal.IsFloat[T]() {
+		// quantile of the empty lane is NaN, integers can't hold it
+		tensor.Err = fmt.Errorf("quantile of zero-size axes of shape %v is not defined for integers", tensor.shape)
+		return tensor
+	}
+	values := make([]T, r.lanes())
+	internal.ForEachLaneFloat64(r.data, r.outer, r.dim, r.inner, func(lane int, lane_values []float64) {
+		if slices.ContainsFunc(lane_values, math.IsNaN) {
+			values[lane] = T(math.NaN())
+			return
+		}
+		slices.Sort(lane_values)
+		values[lane] = T(quantile(lane_values, q, method))
+	})
+	return r.result(values, keep_dims)
+}
+
+// Median of all elements or along given axes. The mean of two middle values is taken for even sizes
+//
+// Example:
+// a = [
+// [3,1,2],
+// [4,6,5]]
+// a.Median(false, 1) => [2, 5]
+func (tensor *Tensor[T]) Median(keep_dims bool, axes ...int) *Tensor[T] {
+	return tensor.Quantile(0.5, QuantileLinear, keep_dims, axes...)
+}
+
+// Counts all elements in equal-width bins. Returns the counts and bins+1 bin edges.
+// The range of bins is (min, max) of the data or the limits (low, high) if they are given.
+// Every bin includes the left edge, the last bin also includes the right edge.
+// Values outside the range and NaN values are not counted.
+//
+// Example:
+// a = [1,2,2,3,5]
+// a.Histogram(2) => counts [3,2], edges [1,3,5]
+// a.Histogram(2, 0, 4) => counts [1,3], edges [0,2,4]
+func (tensor *Tensor[T]) Histogram(bins int, limits ...float64) (*Tensor[int], *Tensor[float64]) {
+	fail := func(err error) (*Tensor[int], *Tensor[float64]) {
+		return &Tensor[int]{Err: err}, &Tensor[float64]{Err: err}
+	}
+	if tensor.Err != nil {
+		return fail(tensor.Err)
+	}
+	if is_half[T]() {
+		return half_to_float32(tensor).Histogram(bins, limits...)
+	}
+	if bins < 1 {
+		return fail(fmt.Errorf("number of bins must be positive, got %v", bins))
+	}
+	data := tensor.AsContiguous().data()
+	var low, high float64
+	switch len(limits) {
+	case 0:
+		low, high = math.Inf(1), math.Inf(-1)
+		for _, v := range data {
+			low, high = math.Min(low, float64(v)), math.Max(high, float64(v))
+		}
+		if low == high {
+			low, high = low-0.5, high+0.5
+		}
+	case 2:
+		low, high = limits[0], limits[1]
+	default:
+		return fail(fmt.Errorf("limits must be (low, high), got %v", limits))
+	}
+	if !(low < high) || math.IsInf(low, 0) || math.IsInf(high, 0) {
+		return fail(fmt.Errorf("histogram range must be finite with low < high, got (%v, %v)", low, high))
+	}
+
+	counts := make([]int, bins)
+	width := (high - low) / float64(bins)
+	for _, v := range data {
+		x := float64(v)
+		if !(x >= low && x <= high) {
+			continue
+		}
+		bin := min(int((x-low)/width), bins-1)
+		counts[bin]++
+	}
+	edges := make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = low + float64(i)*width
+	}
+	edges[bins] = high
+	dims := types.Shape{types.Dim(bins)}
+	return CreateTensorNoCopy(counts, dims), CreateTensorNoCopy(edges, types.Shape{types.Dim(bins + 1)})
+}
+
+// Counts occurrences of every value of the 1D tensor of non negative integers.
+// Result has max(values)+1 elements, at least minlength.
+//
+// Example:
+// a = [0,1,1,3]
+// a.Bincount(0) => [1,2,0,1]
+// a.Bincount(6) => [1,2,0,1,0,0]
+func (tensor *Tensor[T]) Bincount(minlength int) *Tensor[int] {
+	if tensor.Err != nil {
+		return &Tensor[int]{Err: tensor.Err}
+	}
+	if err := tensor.check_integer("Bincount"); err != nil {
+		return &Tensor[int]{Err: err}
+	}
+	if len(tensor.shape) != 1 {
+		return &Tensor[int]{Err: fmt.Errorf("Bincount expects 1D tensor, got shape %v", tensor.shape)}
+	}
+	if minlength < 0 {
+		return &Tensor[int]{Err: fmt.Errorf("minlength must not be negative, got %v", minlength)}
+	}
+	data := tensor.AsContiguous().data()
+	length := minlength
+	for _, v := range data {
+		if v < 0 {
+			return &Tensor[int]{Err: fmt.Errorf("Bincount expects non negative values, got %v", v)}
+		}
+		length = max(length, int(v)+1)
+	}
+	if length == 0 {
+		return &Tensor[int]{Err: errors.New("Bincount result is empty, set minlength")}
+	}
+	counts := make([]int, length)
+	for _, v := range data {
+		counts[int(v)]++
+	}
+	return CreateTensorNoCopy(counts, types.Shape{types.Dim(length)})
+}
+
+// centered copy of the data in float64 with variables in rows and observations in columns
+func (tensor *Tensor[T]) centered_observations() (*Tensor[float64], error) {
+	switch len(tensor.shape) {
+	case 1:
+		tensor = tensor.View().Unsqueeze(0)
+	case 2:
+	default:
+		return nil, fmt.Errorf("expected 1D or 2D tensor, got shape %v", tensor.shape)
+	}
+	x := AsType[T, float64](tensor)
+	centered := x.Sub(x.Mean(true, 1))
+	return centered, centered.Err
+}
+
+// Covariance matrix of variables in rows and observations in columns, like numpy cov.
+// 1D tensor is one variable, the result has shape (1,1) then.
+// ddof 1 gives the unbiased estimate, ddof 0 the population covariance.
+//
+// Example:
+// a = [
+// [0,1,2],
+// [2,1,0]]
+// a.Cov(1) => [[1,-1],[-1,1]]
+func (tensor *Tensor[T]) Cov(ddof int) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	centered, err := tensor.centered_observations()
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	n := int(centered.shape[1])
+	if ddof < 0 || ddof >= n {
+		tensor.Err = fmt.Errorf("ddof must be in range [0, %v), got %v", n, ddof)
+		return tensor
+	}
+	cov := centered.MatMul(centered.View().T())
+	cov.Div(Scalar(float64(n-ddof)), cov)
+	return AsType[float64, T](cov)
+}
+
+// Pearson correlation coefficients of variables in rows and observations in columns, like numpy corrcoef.
+// Variables with zero variance give NaN.
+//
+// Example:
+// a = [
+// [0,1,2],
+// [0,2,4]]
+// a.Corrcoef() => [[1,1],[1,1]]
+func (tensor *Tensor[T]) Corrcoef() *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	centered, err := tensor.centered_observations()
+	if err != nil {
+		tensor.Err = err
+		return tensor
+	}
+	cov := centered.MatMul(centered.View().T())
+	vars := int(cov.shape[0])
+	data := cov.data()
+	std := make([]float64, vars)
+	for i := range std {
+		std[i] = math.Sqrt(data[i*vars+i])
+	}
+	for i := 0; i < vars; i++ {
+		for j := 0; j < vars; j++ {
+			// rounding can push the values out of [-1, 1]
+			data[i*vars+j] = math.Max(-1, math.Min(1, data[i*vars+j]/(std[i]*std[j])))
+		}
+	}
+	return AsType[float64, T](cov)
+}
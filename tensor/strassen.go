@@ -0,0 +1,95 @@
+package tensor
+
+import (
+	"errors"
+	"fmt"
+	"gograd/tensor/internal"
+	types "gograd/tensor/types"
+)
+
+// Matrices (and Strassen blocks) with the size not greater than the cutoff are multiplied by the regular MatMul kernel.
+// Below ~512 the blocked GEMM is faster than the extra additions and allocations of Strassen recursion.
+var STRASSEN_CUTOFF = 512
+
+// Matrix multiplication of 2D tensors using Strassen algorithm.
+// Matrices are padded with zeros to the square shape, odd sizes are padded on each recursion level.
+// Blocks not larger than STRASSEN_CUTOFF are multiplied with MatMul.
+//
+// Strassen performs ~n^2.81 multiplications instead of n^3,
+// but it is less accurate for floats than MatMul, since the products are combined via sums and differences.
+func (tensor *Tensor[T]) MatMulStrassen(other *Tensor[T]) *Tensor[T] {
+	if tensor.Err != nil {
+		return tensor
+	}
+	if other.Err != nil {
+		return other
+	}
+	if len(tensor.shape) != 2 || len(other.shape) != 2 {
+		tensor.Err = fmt.Errorf("MatMulStrassen supports only 2D matrices, got %v and %v", tensor.shape, other.shape)
+		return tensor
+	}
+	if tensor.shape[1] != other.shape[0] {
+		tensor.Err = errors.New("tensors are not aligned for matmul")
+		return tensor
+	}
+	m, k, n := tensor.shape[0], tensor.shape[1], other.shape[1]
+	size := max(m, k, n)
+	if int(size) <= STRASSEN_CUTOFF {
+		return tensor.MatMul(other)
+	}
+	a := pad_square(tensor, size)
+	b := pad_square(other, size)
+	out := strassen(a, b)
+	if m == size && n == size {
+		return out
+	}
+	return out.IndexAdv_(ITo(int(m)), ITo(int(n))).Clone()
+}
+
+// copies the matrix into the top left corner of the zero (size, size) matrix
+func pad_square[T types.TensorType](tensor *Tensor[T], size types.Dim) *Tensor[T] {
+	if tensor.shape[0] == size && tensor.shape[1] == size {
+		return tensor.AsContiguous()
+	}
+	padded := CreateEmptyTensor[T](size, size)
+	region := padded.sliceAxis(0, 0, int(tensor.shape[0])).sliceAxis(1, 0, int(tensor.shape[1]))
+	region.copyFromContiguous(tensor.AsContiguous())
+	return padded
+}
+
+// recursive step for the contiguous square matrices of the same size
+func strassen[T types.TensorType](a, b *Tensor[T]) *Tensor[T] {
+	size := int(a.shape[0])
+	if size <= STRASSEN_CUTOFF {
+		return a.MatMul(b)
+	}
+	if size%2 != 0 {
+		// add one zero row and col, so the matrix can be split into the equal quarters
+		pad_a, pad_shape := internal.PaddingMat(a.data(), a.shape, 0, 1)
+		pad_b, _ := internal.PaddingMat(b.data(), b.shape, 0, 1)
+		out := strassen(CreateTensorNoCopy(pad_a, pad_shape), CreateTensorNoCopy(pad_b, pad_shape))
+		return CreateTensorNoCopy(internal.RemovePaddingMat(out.data(), out.shape, 0, 1), a.shape)
+	}
+	a11, a12, a21, a22 := SplitTensor(a, nil, nil, nil, nil)
+	b11, b12, b21, b22 := SplitTensor(b, nil, nil, nil, nil)
+
+	m1 := strassen(a11.Add(a22), b11.Add(b22))
+	m2 := strassen(a21.Add(a22), b11)
+	m3 := strassen(a11, b12.Sub(b22))
+	m4 := strassen(a22, b21.Sub(b11))
+	m5 := strassen(a11.Add(a12), b22)
+	m6 := strassen(a21.Sub(a11), b11.Add(b12))
+	m7 := strassen(a12.Sub(a22), b21.Add(b22))
+
+	// c11 = m1 + m4 - m5 + m7
+	c11 := m1.Add(m4)
+	c11.Sub(m5, c11).Add(m7, c11)
+	// c12 = m3 + m5
+	c12 := m3.Add(m5, m5)
+	// c21 = m2 + m4
+	c21 := m2.Add(m4, m4)
+	// c22 = m1 - m2 + m3 + m6
+	c22 := m1.Sub(m2, m1)
+	c22.Add(m3, c22).Add(m6, c22)
+	return UniteTensors(c11, c12, c21, c22, nil)
+}
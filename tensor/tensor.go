@@ -16,16 +16,16 @@ var numCPU int = runtime.NumCPU()
 //tensor initialization-----------------------------------------------------
 
 func makeTensor[T types.TensorType](dataPtr *[]T, shape types.Shape, copy bool) *Tensor[T] {
-	var shapeProd types.Dim = 1
-	for _, dim := range shape {
-		shapeProd *= dim
-	}
-
 	var tensor Tensor[T]
 
-	if shapeProd == 0 {
-		tensor.Err = errors.New("shape cannot have zero dim")
-		return &tensor
+	// 0-d shape has one element, zero dims give an empty tensor
+	shapeProd := 1
+	for _, dim := range shape {
+		if dim < 0 {
+			tensor.Err = fmt.Errorf("shape %v cannot have negative dims", shape)
+			return &tensor
+		}
+		shapeProd *= int(dim)
 	}
 
 	var data []T
@@ -41,7 +41,7 @@ func makeTensor[T types.TensorType](dataPtr *[]T, shape types.Shape, copy bool)
 			data = *dataPtr
 		}
 	}
-	if len(shape) == 0 || int(shapeProd) != len(data) {
+	if shapeProd != len(data) {
 		tensor.Err = fmt.Errorf("makeTensor: Value length %v cannot have shape %v", len(data), shape)
 		return &tensor
 	}
@@ -68,20 +68,42 @@ func CreateEmptyTensor[T types.TensorType](shape ...types.Dim) *Tensor[T] {
 }
 
 func Ones[T types.TensorType](shape ...types.Dim) *Tensor[T] {
-	return CreateEmptyTensor[T](shape...).Fill(1)
+	return CreateEmptyTensor[T](shape...).Fill(one[T]())
 }
 
 func Zeros[T types.TensorType](shape ...types.Dim) *Tensor[T] {
 	return CreateEmptyTensor[T](shape...)
 }
 
-// A tensor with Shape 1
+// 0-d tensor with a single value.
+// Float16 and BFloat16 values can't be told apart from their bits here, so Scalar[types.Float16](2)
+// would hold the bits 2. Use ScalarOf for them, Scalar sets an error.
 func Scalar[T types.TensorType](value T) *Tensor[T] {
+	if is_half[T]() {
+		return &Tensor[T]{Err: fmt.Errorf("Scalar stores the bits of %T, use ScalarOf", value)}
+	}
+	return scalar(value)
+}
+
+// 0-d tensor with the value converted to T, 16-bit floats are rounded from float32.
+//
+// Example:
+// ScalarOf[types.Float16](2.5) => 2.5
+// ScalarOf[int32](2.5) => 2
+func ScalarOf[T types.TensorType](value float64) *Tensor[T] {
+	if is_half[T]() {
+		return float32_to_half[T](scalar(float32(value)))
+	}
+	return scalar(T(value))
+}
+
+// 0-d tensor holding the element as is
+func scalar[T types.TensorType](value T) *Tensor[T] {
 	return &Tensor[T]{
-		shape:     types.Shape{1},
-		strides:   []int{1},
+		shape:     types.Shape{},
+		strides:   []int{},
 		data_buff: []T{value},
-		dim_order: []uint16{0},
+		dim_order: []uint16{},
 	}
 }
 
@@ -98,6 +120,10 @@ func AsType[OLD_T, NEW_T types.TensorType](tensor *Tensor[OLD_T]) *Tensor[NEW_T]
 		ret.Err = tensor.Err
 		return ret
 	}
+	if is_half[OLD_T]() || is_half[NEW_T]() {
+		return convert_half[OLD_T, NEW_T](tensor)
+	}
+	tensor = tensor.AsContiguous()
 
 	out_data := make([]NEW_T, len(tensor.data()))
 	out_tensor := CreateTensorNoCopy(out_data, tensor.shape)
@@ -136,16 +162,22 @@ func AsType[OLD_T, NEW_T types.TensorType](tensor *Tensor[OLD_T]) *Tensor[NEW_T]
 	return out_tensor
 }
 
-func (tensor *Tensor[T]) Copy() *Tensor[T] {
+// creates a contiguous copy of the tensor which doesn't share memory with the original one.
+// Use View() to get a tensor that shares the memory
+func (tensor *Tensor[T]) Clone() *Tensor[T] {
 	if tensor.Err != nil {
 		return tensor
 	}
-	newData := make([]T, len(tensor.data()))
-	copy(newData, tensor.data())
-	newTensor := CreateTensorNoCopy(newData, tensor.shape)
-	newTensor.strides = tensor.strides
-	newTensor.dim_order = tensor.dim_order
-	return newTensor
+	if !tensor.IsContiguous() {
+		// materialization creates a new buffer anyway
+		return tensor.AsContiguous()
+	}
+	return CreateTensor(tensor.data(), tensor.shape)
+}
+
+// alias for Clone()
+func (tensor *Tensor[T]) Copy() *Tensor[T] {
+	return tensor.Clone()
 }
 
 // Creates a tensor with data ranged from 'start' to 'end'
@@ -154,6 +186,9 @@ func Range[T types.TensorType](limits ...int) *Tensor[T] {
 	if len(limits) == 0 {
 		panic("range requires at least one argument")
 	}
+	if is_half[T]() {
+		return float32_to_half[T](Range[float32](limits...))
+	}
 	start, end, step := 0, 0, 1
 	if len(limits) == 1 {
 		end = limits[0]
@@ -179,6 +214,15 @@ func (tensor *Tensor[T]) Fill(value T) *Tensor[T] {
 	if tensor.Err != nil {
 		return tensor
 	}
+	if !tensor.IsContiguous() {
+		// fill the view in-place, so changes are visible in the original tensor
+		it := tensor.CreateIterator()
+		for it.Iterate() {
+			idx := it.Next()
+			tensor.data()[tensor.offset+get_flat_idx_fast(tensor.strides, idx...)] = value
+		}
+		return tensor
+	}
 	var wg sync.WaitGroup
 	wg.Add(numCPU)
 
@@ -218,7 +262,7 @@ func Eye[T types.TensorType](x, y types.Dim) *Tensor[T] {
 		for j := 0; j < int(y); j++ {
 			if i == j {
 				fidx := get_flat_idx_fast(eye.strides, i, j)
-				eye.data()[fidx] = 1
+				eye.data()[fidx] = one[T]()
 			}
 		}
 	}
@@ -231,11 +275,12 @@ func (tensor *Tensor[T]) DiagFlat() *Tensor[T] {
 	}
 	n := types.Dim(tensor.Size())
 	diag := CreateEmptyTensor[T](types.Shape{n, n}...)
+	data := tensor.AsContiguous().data()
 	for i := 0; i < int(n); i++ {
 		for j := 0; j < int(n); j++ {
 			if i == j {
 				fidx := get_flat_idx_fast(diag.strides, i, j)
-				diag.data()[fidx] = tensor.data()[i]
+				diag.data()[fidx] = data[i]
 			}
 		}
 	}
@@ -260,6 +305,9 @@ func (tensor *Tensor[T]) SetData(value []T) *Tensor[T] {
 	}
 	// TODO avoid data_buff
 	tensor.data_buff = value
+	tensor.offset = 0
+	tensor.strides = tensor.shape.GetStrides()
+	tensor.dim_order = tensor.shape.InitDimOrder()
 	return tensor
 }
 
@@ -277,7 +325,7 @@ func (tensor *Tensor[T]) Set(indexes []int, value T) {
 }
 
 func (tensor *Tensor[T]) CreateIterator() *TensorIterator {
-	return CreateIterator(len(tensor.data()), tensor.shape)
+	return CreateIterator(int(tensor.Size()), tensor.shape)
 }
 
 // Compares shapes and data:
@@ -313,9 +361,12 @@ func (tensor *Tensor[T]) IsAllClose(tensor_or_scalar *Tensor[T], tol float64) (b
 	if tensor_or_scalar.Err != nil {
 		return false, tensor_or_scalar.Err
 	}
+	if is_half[T]() {
+		return half_to_float32(tensor).IsAllClose(half_to_float32(tensor_or_scalar), tol)
+	}
 	if tensor_or_scalar.Shape().IsScalarLike() {
 		other_val := tensor_or_scalar.Item()
-		for _, val := range tensor.data() {
+		for _, val := range tensor.AsContiguous().data() {
 			if math.Abs(float64(val-other_val)) > tol {
 				return false, nil
 			}
@@ -340,7 +391,10 @@ func (tensor *Tensor[T]) HasNaN() (bool, error) {
 	if tensor.Err != nil {
 		return false, tensor.Err
 	}
-	data := tensor.data()
+	if is_half[T]() {
+		return half_to_float32(tensor).HasNaN()
+	}
+	data := tensor.AsContiguous().data()
 	for i := 0; i < len(data); i++ {
 		if math.IsNaN(float64(data[i])) {
 			return true, nil
@@ -21,6 +21,13 @@ type printSettings struct {
 
 func joinData[T types.TensorType](sb *strings.Builder, data []T) {
 	stringData := make([]string, len(data))
+	if is_half[T]() {
+		for i, val := range half_to_float32(CreateTensorNoCopy(data, types.Shape{types.Dim(len(data))})).data() {
+			stringData[i] = strconv.FormatFloat(float64(val), 'f', 8, 32)
+		}
+		sb.WriteString(strings.Join(stringData, ", "))
+		return
+	}
 	dtype := getTypeArray(data)
 	switch dtype.Kind() {
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
@@ -117,26 +124,34 @@ func stringRepr[T types.TensorType](sb *strings.Builder, tensor *Tensor[T], ps *
 
 func (tensor *Tensor[T]) ToString() string {
 	tensor.MustAssert()
-	var shape_prod types.Dim = 1
-	for _, dim := range tensor.shape {
-		shape_prod *= dim
-	}
-	total_lines := int(shape_prod / tensor.shape[len(tensor.shape)-1])
 	var sb strings.Builder
-	ps := printSettings{
-		total_lines: total_lines,
+	switch {
+	case tensor.Size() == 0:
+		sb.WriteString("[]")
+	case len(tensor.shape) == 0:
+		// 0-d scalar is printed without brackets
+		joinData(&sb, tensor.AsContiguous().data())
+	default:
+		ps := printSettings{
+			total_lines: int(tensor.Size()) / int(tensor.shape[len(tensor.shape)-1]),
+		}
+		stringRepr(&sb, tensor.AsContiguous(), &ps)
 	}
-	stringRepr(&sb, tensor, &ps)
 	strData := sb.String()
 
-	if len(tensor.shape) > 1 {
+	if len(tensor.shape) > 1 && tensor.Size() > 0 {
 		strData = "\n" + strData
 	}
+	dtype := getTypeArray(tensor.data()).Kind().String()
+	if is_half[T]() {
+		// the kind of 16-bit floats is uint16
+		dtype = DTypeOf[T]().String()
+	}
 	return fmt.Sprintf(
 		"Tensor(%v, shape=%v, dtype=%v, order=%v, strides=%v)",
 		strData,
 		tensor.shape,
-		getTypeArray(tensor.data()).Kind().String(),
+		dtype,
 		tensor.dim_order,
 		tensor.strides,
 	)
@@ -0,0 +1,122 @@
+package types
+
+import (
+	"math"
+	"strconv"
+)
+
+// 16-bit floats are storage types: tensors keep the raw bits and
+// the ops convert them to float32 to compute the result.
+
+// IEEE 754 half precision: 1 sign, 5 exponent and 10 mantissa bits
+type Float16 uint16
+
+// brain float: 1 sign, 8 exponent and 7 mantissa bits, the upper half of a float32
+type BFloat16 uint16
+
+type Half interface {
+	Float16 | BFloat16
+}
+
+// Rounds to the nearest even, values out of range become +-Inf
+func Float16FromFloat32(f float32) Float16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int(b>>23) & 0xff
+	mant := b & 0x7fffff
+	if exp == 0xff {
+		if mant != 0 {
+			// quiet NaN
+			return Float16(sign | 0x7e00)
+		}
+		return Float16(sign | 0x7c00)
+	}
+	e := exp - 127 + 15
+	if e >= 0x1f {
+		return Float16(sign | 0x7c00)
+	}
+	var half, rem, halfway uint32
+	if e <= 0 {
+		// subnormal half
+		if e < -10 {
+			return Float16(sign)
+		}
+		mant |= 0x800000
+		shift := uint32(14 - e)
+		half = mant >> shift
+		rem = mant & (1<<shift - 1)
+		halfway = 1 << (shift - 1)
+	} else {
+		half = uint32(e)<<10 | mant>>13
+		rem = mant & 0x1fff
+		halfway = 0x1000
+	}
+	// the carry can overflow into the exponent, which gives the correct next value or Inf
+	if rem > halfway || (rem == halfway && half&1 == 1) {
+		half++
+	}
+	return Float16(sign | uint16(half))
+}
+
+func (h Float16) Float32() float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+	switch {
+	case exp == 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case exp == 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		// normalize the subnormal value
+		e := uint32(127 - 14)
+		for mant&0x400 == 0 {
+			mant <<= 1
+			e--
+		}
+		return math.Float32frombits(sign | e<<23 | (mant&0x3ff)<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
+
+func (h Float16) String() string {
+	return strconv.FormatFloat(float64(h.Float32()), 'g', -1, 32)
+}
+
+// Rounds to the nearest even
+func BFloat16FromFloat32(f float32) BFloat16 {
+	b := math.Float32bits(f)
+	if f != f {
+		// keep NaN quiet, the rounding could turn it into Inf
+		return BFloat16(b>>16 | 0x40)
+	}
+	b += 0x7fff + (b>>16)&1
+	return BFloat16(b >> 16)
+}
+
+func (h BFloat16) Float32() float32 {
+	return math.Float32frombits(uint32(h) << 16)
+}
+
+func (h BFloat16) String() string {
+	return strconv.FormatFloat(float64(h.Float32()), 'g', -1, 32)
+}
+
+func HalfToFloat32[H Half](h H) float32 {
+	switch v := any(h).(type) {
+	case Float16:
+		return v.Float32()
+	case BFloat16:
+		return v.Float32()
+	}
+	return 0
+}
+
+func HalfFromFloat32[H Half](f float32) H {
+	switch any(*new(H)).(type) {
+	case Float16:
+		return H(Float16FromFloat32(f))
+	}
+	return H(BFloat16FromFloat32(f))
+}
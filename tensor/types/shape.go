@@ -27,7 +27,7 @@ func addLeftPadding[T TensorType](slice []T, padding_size int, padding_val T) []
 func (shape Shape) Squeeze() Shape {
 	result := make(Shape, 0, len(shape))
 	for _, v := range shape {
-		if v > 1 {
+		if v != 1 {
 			result = append(result, v)
 		}
 	}
@@ -41,7 +41,7 @@ func (shape Shape) Squeeze() Shape {
 func (shape Shape) SqueezeInner() Shape {
 	result := make(Shape, 0, len(shape))
 	for i, v := range shape {
-		if v > 1 || i == 0 || i == len(shape)-1 {
+		if v != 1 || i == 0 || i == len(shape)-1 {
 			result = append(result, v)
 		}
 	}
@@ -100,8 +100,14 @@ func (shape Shape) AreBroadcastable(other Shape) bool {
 }
 
 func (shape_a Shape) BroadcastShapes(shape_b Shape) Shape {
-	if shape_a.IsScalarLike() && shape_b.IsScalarLike() ||
-		shape_a.Equals(shape_b) {
+	if shape_a.Equals(shape_b) {
+		return shape_a
+	}
+	if shape_a.IsScalarLike() && shape_b.IsScalarLike() {
+		// one element shapes differ only by the number of dims
+		if len(shape_b) > len(shape_a) {
+			return shape_b
+		}
 		return shape_a
 	}
 	if len(shape_a) < len(shape_b) {
@@ -136,12 +142,10 @@ func (shape_a Shape) BroadcastShapes(shape_b Shape) Shape {
 	return result_shape
 }
 
+// reports whether the shape has exactly one element: 0-d scalar or all dims are one-sized
 func (shape Shape) IsScalarLike() bool {
-	if len(shape) == 1 && shape[0] == 1 {
-		return true
-	}
 	for _, dim := range shape {
-		if dim > 1 {
+		if dim != 1 {
 			return false
 		}
 	}
@@ -185,26 +189,32 @@ func (shape Shape) ReduceDim(axes ...int) Shape {
 
 // stacks shapes together by given axis. rest of dims must be the same:
 // Stacked by axis 0 (1,2,3), (2,2,3), (4,2,3) => (7,2,3)
+// Stacked by axis -1 (2,1), (2,3) => (2,4)
 func StackShapes(axis int, other_shapes ...Shape) (Shape, error) {
 	if len(other_shapes) == 0 {
 		return nil, errors.New("at least 1 shape must be set")
 	}
-	result := make(Shape, len(other_shapes[0]))
-	copy(result, other_shapes[0])
-	prev_shape := other_shapes[0]
+	first := other_shapes[0]
+	if axis < -len(first) || axis >= len(first) {
+		return nil, fmt.Errorf("axis %v is out of bounds for shape %v", axis, first)
+	}
+	if axis < 0 {
+		axis += len(first)
+	}
+	result := make(Shape, len(first))
+	copy(result, first)
 
 	for i := 1; i < len(other_shapes); i++ {
 		sh := other_shapes[i]
+		if len(sh) != len(first) {
+			return nil, fmt.Errorf("shapes %v and %v cannot be stacked together", sh, first)
+		}
 		result[axis] += sh[axis]
 		for j := 0; j < len(sh); j++ {
-			if j == axis {
-				continue
-			}
-			if sh[j] != prev_shape[j] {
-				return nil, fmt.Errorf("shapes %v and %v cannot be stacked together", sh, prev_shape)
+			if j != axis && sh[j] != first[j] {
+				return nil, fmt.Errorf("shapes %v and %v cannot be stacked together", sh, first)
 			}
 		}
-		prev_shape = sh
 	}
 	return result, nil
 }
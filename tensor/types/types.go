@@ -9,10 +9,16 @@ type Float interface {
 }
 
 type TensorType interface {
-	constraints.Float | constraints.Integer | ~byte
+	constraints.Float | constraints.Integer | ~byte | Half
 }
 
-type Dim uint32
+// complex values have no ordering, so they are stored in ComplexTensor instead of Tensor
+type Complex interface {
+	complex64 | complex128
+}
+
+// Dim is signed, so -1 can be passed to Reshape to infer the dim
+type Dim int32
 type Shape []Dim
 
 type ITensor[T TensorType] interface {
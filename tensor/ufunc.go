@@ -11,6 +11,7 @@ func (tensor *Tensor[T]) ApplyFunc(expression_fn func(T) T, out ...*Tensor[T]) *
 		tensor.Err = err
 		return tensor
 	}
+	tensor = tensor.AsContiguous()
 	device.ApplyFunc(AUTO_IMPL, tensor.data(), expression_fn, outTensor.data())
 	return outTensor
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"strings"
+	"testing"
+)
+
+func TestBitwise(t *testing.T) {
+	a := tensor.CreateTensor([]int32{12, 10, -1, 0}, types.Shape{4})
+	b := tensor.CreateTensor([]int32{6, 3, 5, 7}, types.Shape{4})
+	assertEqualSlices(t, a.BitAnd(b).Data(), []int32{4, 2, 5, 0})
+	assertEqualSlices(t, a.BitOr(b).Data(), []int32{14, 11, -1, 7})
+	assertEqualSlices(t, a.BitXor(b).Data(), []int32{10, 9, -6, 7})
+	assertEqualSlices(t, a.BitNot().Data(), []int32{-13, -11, 0, -1})
+
+	u := tensor.CreateTensor([]uint8{0x0f, 0xf0, 0xff}, types.Shape{3})
+	assertEqualSlices(t, u.BitNot().Data(), []uint8{0xf0, 0x0f, 0x00})
+	assertEqualSlices(t, u.BitXor(tensor.Scalar[uint8](0xff)).Data(), []uint8{0xf0, 0x0f, 0x00})
+
+	// broadcasting
+	m := tensor.Range[int](6).Reshape(2, 3)
+	mask := tensor.CreateTensor([]int{1, 2, 4}, types.Shape{3})
+	assertEqualSlices(t, m.BitAnd(mask).Data(), []int{0, 0, 0, 1, 0, 4})
+	assertEqualSlices(t, tensor.Scalar(8).BitOr(m).Data(), []int{8, 9, 10, 11, 12, 13})
+
+	// out and in-place
+	out := tensor.CreateEmptyTensor[int](2, 3)
+	m.BitXor(tensor.Scalar(1), out)
+	assertEqualSlices(t, out.Data(), []int{1, 0, 3, 2, 5, 4})
+	m.BitNot(m)
+	assertEqualSlices(t, m.Data(), []int{-1, -2, -3, -4, -5, -6})
+}
+
+func TestShifts(t *testing.T) {
+	a := tensor.CreateTensor([]int{1, 3, -16, 16}, types.Shape{4})
+	assertEqualSlices(t, a.Shl(tensor.Scalar(4)).Data(), []int{16, 48, -256, 256})
+	assertEqualSlices(t, a.Shr(tensor.Scalar(2)).Data(), []int{0, 0, -4, 4})
+	assertEqualSlices(t, a.Shl(tensor.CreateTensor([]int{0, 1, 2, 3}, types.Shape{4})).Data(), []int{1, 6, -64, 128})
+
+	// bits out of the type's width are dropped, unsigned shift is logical
+	i8 := tensor.CreateTensor([]int8{64, -128, 1}, types.Shape{3})
+	assertEqualSlices(t, i8.Shl(tensor.Scalar[int8](1)).Data(), []int8{-128, 0, 2})
+	assertEqualSlices(t, i8.Shr(tensor.Scalar[int8](7)).Data(), []int8{0, -1, 0})
+	assertEqualSlices(t, i8.Shr(tensor.Scalar[int8](9)).Data(), []int8{0, -1, 0})
+	u8 := tensor.CreateTensor([]uint8{0x80, 0xff}, types.Shape{2})
+	assertEqualSlices(t, u8.Shr(tensor.Scalar[uint8](7)).Data(), []uint8{1, 1})
+	assertEqualSlices(t, u8.Shl(tensor.Scalar[uint8](8)).Data(), []uint8{0, 0})
+
+	// negative shift counts
+	assertEqualSlices(t, a.Shl(tensor.Scalar(-1)).Data(), []int{0, 0, 0, 0})
+	assertEqualSlices(t, a.Shr(tensor.Scalar(-1)).Data(), []int{0, 0, -1, 0})
+}
+
+func TestPopCount(t *testing.T) {
+	a := tensor.CreateTensor([]int8{0, 1, 7, -1, -128}, types.Shape{5})
+	assertEqualSlices(t, a.PopCount().Data(), []int8{0, 1, 3, 8, 1})
+	b := tensor.CreateTensor([]int64{-1, 1 << 40, 255}, types.Shape{3})
+	assertEqualSlices(t, b.PopCount().Data(), []int64{64, 1, 8})
+	c := tensor.CreateTensor([]uint16{0xffff, 0x0101}, types.Shape{2})
+	assertEqualSlices(t, c.PopCount().Data(), []uint16{16, 2})
+}
+
+func TestIntegerMod(t *testing.T) {
+	a := tensor.CreateTensor([]uint8{7, 200, 5}, types.Shape{3})
+	assertEqualSlices(t, a.Mod(tensor.Scalar[uint8](3)).Data(), []uint8{1, 2, 2})
+	assertEqualSlices(t, a.FloorDiv(tensor.Scalar[uint8](3)).Data(), []uint8{2, 66, 1})
+	h := tensor.CreateTensor([]int64{-17, 1 << 40, 99}, types.Shape{3, 1})
+	buckets := h.Mod(tensor.CreateTensor([]int64{8, 10}, types.Shape{2}))
+	assertEqualSlices(t, buckets.Shape(), types.Shape{3, 2})
+	assertEqualSlices(t, buckets.Data(), []int64{7, 3, 0, 6, 3, 9})
+}
+
+func TestBitwiseFloatErrors(t *testing.T) {
+	ops := map[string]func(*tensor.Tensor[float32]) *tensor.Tensor[float32]{
+		"BitAnd":   func(f *tensor.Tensor[float32]) *tensor.Tensor[float32] { return f.BitAnd(f) },
+		"BitOr":    func(f *tensor.Tensor[float32]) *tensor.Tensor[float32] { return f.BitOr(f) },
+		"BitXor":   func(f *tensor.Tensor[float32]) *tensor.Tensor[float32] { return f.BitXor(f) },
+		"BitNot":   func(f *tensor.Tensor[float32]) *tensor.Tensor[float32] { return f.BitNot() },
+		"Shl":      func(f *tensor.Tensor[float32]) *tensor.Tensor[float32] { return f.Shl(tensor.Scalar[float32](1)) },
+		"Shr":      func(f *tensor.Tensor[float32]) *tensor.Tensor[float32] { return f.Shr(tensor.Scalar[float32](1)) },
+		"PopCount": func(f *tensor.Tensor[float32]) *tensor.Tensor[float32] { return f.PopCount() },
+	}
+	for name, op := range ops {
+		res := op(tensor.Range[float32](4))
+		if res.Err == nil || !strings.Contains(res.Err.Error(), name) {
+			t.Errorf("%v must fail for float tensors, got error: %v", name, res.Err)
+		}
+	}
+	assert(t, tensor.Range[float64](4).PopCount().Err != nil)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"math"
+	"slices"
+	"testing"
+)
+
+func assertEqualMasks(t *testing.T, mask *tensor.BoolTensor, expected []bool) {
+	t.Helper()
+	mask.MustAssert()
+	if !slices.Equal(mask.Data(), expected) {
+		t.Errorf("Masks must be equal. Got %v and %v", mask.Data(), expected)
+	}
+}
+
+func TestBoolTensor(t *testing.T) {
+	mask := tensor.CreateBoolTensor([]bool{true, false, true, true}, types.Shape{2, 2})
+	other := tensor.CreateBoolTensor([]bool{false, true}, types.Shape{2})
+	assertEqualMasks(t, mask.And(other), []bool{false, false, false, true})
+	assertEqualMasks(t, mask.Or(other), []bool{true, true, true, true})
+	assertEqualMasks(t, mask.Xor(other), []bool{true, true, true, false})
+	assertEqualMasks(t, mask.Not(), []bool{false, true, false, false})
+	assert(t, mask.Clone().And(tensor.CreateBoolTensor([]bool{true, true, true}, types.Shape{3})).Err != nil)
+
+	assertEqualSlices(t, tensor.FromBool[int32](mask).Data(), []int32{1, 0, 1, 1})
+	assertEqualMasks(t, tensor.AsBool(tensor.CreateTensor([]float32{0, -2, 0.5}, types.Shape{3})), []bool{false, true, true})
+	// negative zero of a 16-bit float is false
+	neg_zero := tensor.AsType[float32, types.BFloat16](tensor.CreateTensor([]float32{float32(math.Copysign(0, -1)), 1}, types.Shape{2}))
+	assertEqualMasks(t, tensor.AsBool(neg_zero), []bool{false, true})
+
+	r := mask.Reshape(-1)
+	assertEqualSlices(t, r.Shape(), types.Shape{4})
+	assert(t, tensor.CreateBoolTensor([]bool{true}, types.Shape{2}).Err != nil)
+
+	assertStatement(t, mask.ToString(), Equals,
+		"BoolTensor([[true, false], [true, true]], shape=[2 2], dtype=bool)")
+	assertStatement(t, tensor.CreateBoolTensor([]bool{true}, types.Shape{}).ToString(), Equals,
+		"BoolTensor(true, shape=[], dtype=bool)")
+	assertStatement(t, tensor.CreateBoolTensor([]bool{}, types.Shape{0}).ToString(), Equals,
+		"BoolTensor([], shape=[0], dtype=bool)")
+}
+
+func TestAnyAll(t *testing.T) {
+	mask := tensor.CreateBoolTensor([]bool{true, false, false, false, true, true}, types.Shape{2, 3})
+	assertEqualMasks(t, mask.Any(false, 1), []bool{true, true})
+	assertEqualMasks(t, mask.All(false, 1), []bool{false, false})
+	assertEqualMasks(t, mask.All(false, 0), []bool{false, false, false})
+	assertEqualMasks(t, mask.Any(false, -2), []bool{true, true, true})
+	assertEqualSlices(t, mask.Any(true, 0).Shape(), types.Shape{1, 3})
+	assert(t, mask.Any(false).Item())
+	assert(t, !mask.All(false).Item())
+	assertEqualSlices(t, mask.Any(false).Shape(), types.Shape{})
+	assertEqualSlices(t, mask.Count(false, 1).Data(), []int{1, 2})
+	assertStatement(t, mask.Count(false).Item(), Equals, 3)
+
+	// identities of the empty reductions
+	empty := tensor.CreateBoolTensor([]bool{}, types.Shape{2, 0})
+	assertEqualMasks(t, empty.Any(false, 1), []bool{false, false})
+	assertEqualMasks(t, empty.All(false, 1), []bool{true, true})
+
+	assert(t, mask.Clone().Any(false, 2).Err != nil)
+}
@@ -68,3 +68,55 @@ func TestBroadcast5(t *testing.T) {
 	assertEqualSlices(t, br_d.Data(), data)
 	assertEqualSlices(t, br_d.Shape(), types.Shape{3, 4, 3, 2, 3})
 }
+
+func TestBroadcastOpFewerDims(t *testing.T) {
+	// the tensor with fewer dims is broadcasted even if its leading dims are not smaller
+	a := tensor.Range[int32](8).Reshape(2, 2, 2)
+	b := tensor.Range[int32](4).Reshape(2, 2)
+	assertEqualSlices(t, a.Add(b).MustAssert().Data(), []int32{0, 2, 4, 6, 4, 6, 8, 10})
+	assertEqualSlices(t, b.Sub(a).MustAssert().Data(), []int32{0, 0, 0, 0, -4, -4, -4, -4})
+
+	c := tensor.Range[int32](6).Reshape(2, 3)
+	d := tensor.Range[int32](3)
+	assertEqualSlices(t, c.Mul(d).MustAssert().Data(), []int32{0, 1, 4, 0, 4, 10})
+}
+
+func TestBroadcastOpEqualSizes(t *testing.T) {
+	// operands of the same size but different shapes are broadcasted
+	a := tensor.Range[int32](3).Reshape(3, 1)
+	b := tensor.Range[int32](3).Reshape(1, 3)
+	out := a.Mul(b).MustAssert()
+	assertEqualSlices(t, out.Shape(), types.Shape{3, 3})
+	assertEqualSlices(t, out.Data(), []int32{0, 0, 0, 0, 1, 2, 0, 2, 4})
+}
+
+func TestBroadcastInnerDims(t *testing.T) {
+	// broadcasted dim is between non broadcasted ones
+	a := tensor.Range[int32](4).Reshape(2, 1, 2)
+	assertEqualSlices(t, a.Broadcast(2, 3, 2).MustAssert().Data(), []int32{0, 1, 0, 1, 0, 1, 2, 3, 2, 3, 2, 3})
+	b := tensor.Range[int32](3).Reshape(3, 1)
+	assertEqualSlices(t, b.Broadcast(2, 3, 2).MustAssert().Data(), []int32{0, 0, 1, 1, 2, 2, 0, 0, 1, 1, 2, 2})
+	// non-contiguous tensor
+	c := tensor.Range[int32](4).Reshape(2, 2).T().Unsqueeze(1)
+	assertEqualSlices(t, c.Broadcast(2, 2, 2).MustAssert().Data(), []int32{0, 2, 0, 2, 1, 3, 1, 3})
+
+	ones := tensor.Ones[int32](2, 2, 2)
+	assertEqualSlices(t, ones.Mul(a).MustAssert().Data(), []int32{0, 1, 0, 1, 2, 3, 2, 3})
+}
+
+func TestBroadcastLeadingOnes(t *testing.T) {
+	// (2,3) & (1,2,3) => (1,2,3)
+	a := tensor.Range[int32](6).Reshape(2, 3)
+	b := tensor.Range[int32](6).Reshape(1, 2, 3)
+	c := a.Add(b).MustAssert()
+	assertEqualSlices(t, c.Shape(), types.Shape{1, 2, 3})
+	assertEqualSlices(t, c.Data(), []int32{0, 2, 4, 6, 8, 10})
+	assertEqualSlices(t, b.Add(a).MustAssert().Shape(), types.Shape{1, 2, 3})
+
+	// non-contiguous operand of a different rank
+	d := tensor.Range[int32](6).Reshape(1, 3, 2).T(0, 2, 1)
+	e := a.Add(d).MustAssert()
+	assertEqualSlices(t, e.Shape(), types.Shape{1, 2, 3})
+	assertEqualSlices(t, e.Data(), []int32{0, 3, 6, 4, 7, 10})
+	assertEqualSlices(t, d.Sub(a.T().T()).MustAssert().Data(), []int32{0, 1, 2, -2, -1, 0})
+}
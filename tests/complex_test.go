@@ -0,0 +1,153 @@
+package main
+
+import (
+	"gograd/tensor"
+	"gograd/tensor/fft"
+	types "gograd/tensor/types"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func assertComplexClose[C types.Complex](t *testing.T, a, b *tensor.ComplexTensor[C], tol float64) {
+	t.Helper()
+	a.MustAssert()
+	b.MustAssert()
+	if !a.Shape().Equals(b.Shape()) {
+		t.Fatalf("Shapes must be equal. Got %v and %v", a.Shape(), b.Shape())
+	}
+	for i, v := range a.Data() {
+		if cmplx.Abs(complex128(v)-complex128(b.Data()[i])) > tol {
+			t.Fatalf("Tensors must be close. Got %v and %v", a.Data(), b.Data())
+		}
+	}
+}
+
+// reference O(n^2) transform of a 1D signal
+func naiveDFT(x []complex128) []complex128 {
+	n := len(x)
+	out := make([]complex128, n)
+	for k := range out {
+		for j, v := range x {
+			out[k] += v * cmplx.Rect(1, -2*math.Pi*float64(j*k)/float64(n))
+		}
+	}
+	return out
+}
+
+func randomComplex(rng *tensor.RNG, shape ...types.Dim) *tensor.ComplexTensor[complex128] {
+	return tensor.Complex[complex128](rng.RandomFloat64(shape...), rng.RandomFloat64(shape...))
+}
+
+func TestComplexTensor(t *testing.T) {
+	z := tensor.Complex[complex64](tensor.Range[float32](3), tensor.Scalar[float32](1))
+	assertEqualSlices(t, z.Shape(), types.Shape{3})
+	assert(t, z.Data()[2] == 2+1i)
+	assertEqualSlices(t, tensor.Real[float32](z).Data(), []float32{0, 1, 2})
+	assertEqualSlices(t, tensor.Imag[float32](z.Conj()).Data(), []float32{-1, -1, -1})
+	assertEqualSlices(t, tensor.Abs[float64](tensor.CreateComplexTensor([]complex128{3 + 4i}, types.Shape{1})).Data(), []float64{5})
+	angle := tensor.Angle[float64](tensor.CreateComplexTensor([]complex128{1i, -1}, types.Shape{2}))
+	assertEqualSlices(t, angle.Data(), []float64{math.Pi / 2, math.Pi})
+
+	// broadcasting ops
+	a := tensor.CreateComplexTensor([]complex128{1, 1i, 2, 2i}, types.Shape{2, 2})
+	b := tensor.CreateComplexTensor([]complex128{1i, 1}, types.Shape{2})
+	assert(t, tensor.EqualSlices(tensor.Real[float64](a.Mul(b)).Data(), []float64{0, 0, 0, 0}))
+	assert(t, tensor.EqualSlices(tensor.Imag[float64](a.Mul(b)).Data(), []float64{1, 1, 2, 2}))
+	col := tensor.CreateComplexTensor([]complex128{1, 2}, types.Shape{2, 1})
+	assertComplexClose(t, a.Sub(col),
+		tensor.CreateComplexTensor([]complex128{0, -1 + 1i, 0, -2 + 2i}, types.Shape{2, 2}), 0)
+	assertComplexClose(t, a.Div(a), tensor.CreateComplexTensor([]complex128{1, 1, 1, 1}, types.Shape{2, 2}), 1e-12)
+	assert(t, a.Clone().Add(tensor.CreateComplexTensor([]complex128{1, 2, 3}, types.Shape{3})).Err != nil)
+
+	sum := a.Sum(false, 0)
+	assertComplexClose(t, sum, tensor.CreateComplexTensor([]complex128{3, 3i}, types.Shape{2}), 0)
+	assert(t, a.Sum(false).Item() == 3+3i)
+	assertEqualSlices(t, a.Sum(true, 1).Shape(), types.Shape{2, 1})
+
+	e := tensor.CreateComplexTensor([]complex128{complex(0, math.Pi)}, types.Shape{1}).Exp()
+	assert(t, cmplx.Abs(e.Item()+1) < 1e-12)
+
+	r := tensor.AsComplex[complex64](tensor.Range[int32](6)).Reshape(-1, 3)
+	assertEqualSlices(t, r.Shape(), types.Shape{2, 3})
+	assert(t, r.Data()[5] == 5)
+	assert(t, tensor.CreateComplexTensor([]complex64{1, 2}, types.Shape{3}).Err != nil)
+}
+
+func TestFFT(t *testing.T) {
+	rng := tensor.NewRNG(5)
+	// powers of two use radix-2, other lengths use Bluestein
+	for _, n := range []types.Dim{1, 2, 8, 64, 3, 7, 12, 100} {
+		x := randomComplex(rng, n)
+		expected := tensor.CreateComplexTensor(naiveDFT(x.Data()), types.Shape{n})
+		assertComplexClose(t, fft.FFT(x, 0, -1), expected, 1e-9)
+		assertComplexClose(t, fft.IFFT(fft.FFT(x, 0, 0), 0, 0), x, 1e-12)
+	}
+
+	impulse := tensor.CreateComplexTensor([]complex64{1, 0, 0, 0}, types.Shape{4})
+	assertComplexClose(t, fft.FFT(impulse, 0, -1), tensor.CreateComplexTensor([]complex64{1, 1, 1, 1}, types.Shape{4}), 1e-6)
+
+	// transforms every lane along the axis
+	x := randomComplex(rng, 3, 5, 2)
+	y := fft.FFT(x, 0, 1)
+	for i := 0; i < 3; i++ {
+		for k := 0; k < 2; k++ {
+			lane := make([]complex128, 5)
+			for d := range lane {
+				lane[d] = x.Data()[(i*5+d)*2+k]
+			}
+			for d, v := range naiveDFT(lane) {
+				assert(t, cmplx.Abs(y.Data()[(i*5+d)*2+k]-v) < 1e-9)
+			}
+		}
+	}
+
+	// cropping and zero-padding
+	padded := fft.FFT(tensor.CreateComplexTensor([]complex128{1, 2}, types.Shape{2}), 4, 0)
+	assertComplexClose(t, padded, tensor.CreateComplexTensor(naiveDFT([]complex128{1, 2, 0, 0}), types.Shape{4}), 1e-12)
+	cropped := fft.FFT(tensor.CreateComplexTensor([]complex128{1, 2, 3}, types.Shape{3}), 2, 0)
+	assertComplexClose(t, cropped, tensor.CreateComplexTensor([]complex128{3, -1}, types.Shape{2}), 1e-12)
+
+	assert(t, fft.FFT(x, 0, 3).Err != nil)
+	assert(t, fft.FFT(tensor.CreateComplexTensor([]complex128{}, types.Shape{0}), 0, 0).Err != nil)
+}
+
+func TestRFFT(t *testing.T) {
+	rng := tensor.NewRNG(6)
+	for _, n := range []types.Dim{8, 9} {
+		x := rng.RandomFloat64(2, n)
+		spectrum := fft.RFFT[complex128](x, 0, -1)
+		assertEqualSlices(t, spectrum.Shape(), types.Shape{2, n/2 + 1})
+		full := fft.FFT(tensor.AsComplex[complex128](x), 0, -1)
+		for i := 0; i < 2; i++ {
+			for k := 0; k < int(n/2+1); k++ {
+				assert(t, cmplx.Abs(spectrum.Data()[i*int(n/2+1)+k]-full.Data()[i*int(n)+k]) < 1e-9)
+			}
+		}
+		assertAllClose64(t, fft.IRFFT[float64](spectrum, int(n), -1), x)
+	}
+	// the default length is even
+	x := tensor.CreateTensor([]float32{1, 2, 3, 4}, types.Shape{4})
+	assertAllClose(t, fft.IRFFT[float32](fft.RFFT[complex64](x, 0, 0), 0, 0), x)
+}
+
+func TestFFT2(t *testing.T) {
+	rng := tensor.NewRNG(7)
+	x := randomComplex(rng, 2, 4, 6)
+	y := fft.FFT2(x)
+	assertComplexClose(t, y, fft.FFT(fft.FFT(x, 0, -1), 0, -2), 1e-9)
+	assertComplexClose(t, fft.IFFT2(y), x, 1e-12)
+	assertComplexClose(t, fft.FFT2(x, 0, 2), fft.FFT(fft.FFT(x, 0, 2), 0, 0), 1e-9)
+	assert(t, fft.FFT2(x, 0).Err != nil)
+
+	r := rng.RandomFloat64(4, 5)
+	spectrum := fft.RFFT2[complex128](r)
+	assertEqualSlices(t, spectrum.Shape(), types.Shape{4, 3})
+	full := fft.FFT2(tensor.AsComplex[complex128](r))
+	for i := 0; i < 4; i++ {
+		for k := 0; k < 3; k++ {
+			assert(t, cmplx.Abs(spectrum.Data()[i*3+k]-full.Data()[i*5+k]) < 1e-9)
+		}
+	}
+	assertAllClose64(t, fft.IRFFT2[float64](spectrum, 5), r)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"gograd/grad"
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"math"
+	"testing"
+)
+
+func TestCumSumProd(t *testing.T) {
+	a := tensor.Range[float32](1, 7).Reshape(2, 3)
+	assertEqualSlices(t, a.CumSum(1).Data(), []float32{1, 3, 6, 4, 9, 15})
+	assertEqualSlices(t, a.CumSum(0).Data(), []float32{1, 2, 3, 5, 7, 9})
+	assertEqualSlices(t, a.CumSum(-1).Shape(), types.Shape{2, 3})
+	assertEqualSlices(t, a.CumProd(1).Data(), []float32{1, 2, 6, 4, 20, 120})
+	assertEqualSlices(t, a.CumProd(0).Data(), []float32{1, 2, 3, 4, 10, 18})
+
+	// middle axis
+	b := tensor.Range[int](12).Reshape(2, 3, 2)
+	assertEqualSlices(t, b.CumSum(1).Data(), []int{0, 1, 2, 4, 6, 9, 6, 7, 14, 16, 24, 27})
+
+	// non-contiguous input, out and in-place
+	c := tensor.Range[float32](6).Reshape(2, 3).T()
+	assertEqualSlices(t, c.CumSum(1).Data(), []float32{0, 3, 1, 5, 2, 7})
+	out := tensor.CreateEmptyTensor[float32](2, 3)
+	a.CumSum(1, out)
+	assertEqualSlices(t, out.Data(), []float32{1, 3, 6, 4, 9, 15})
+	a.CumSum(0, a)
+	assertEqualSlices(t, a.Data(), []float32{1, 2, 3, 5, 7, 9})
+
+	assert(t, a.CumSum(2).Err != nil)
+}
+
+func TestCumMaxMin(t *testing.T) {
+	a := tensor.CreateTensor([]float32{1, 3, 2, 3, 0, 5}, types.Shape{6})
+	assertEqualSlices(t, a.CumMax(0).Data(), []float32{1, 3, 3, 3, 3, 5})
+	assertEqualSlices(t, a.CumMin(0).Data(), []float32{1, 1, 1, 1, 0, 0})
+	// the last position is taken on ties
+	assertEqualSlices(t, a.CumArgMax(0).Data(), []int{0, 1, 1, 3, 3, 5})
+	assertEqualSlices(t, a.CumArgMin(0).Data(), []int{0, 0, 0, 0, 4, 4})
+
+	m := tensor.CreateTensor([]int32{3, 1, 2, 5, 0, 4}, types.Shape{3, 2})
+	assertEqualSlices(t, m.CumMax(0).Data(), []int32{3, 1, 3, 5, 3, 5})
+	assertEqualSlices(t, m.CumArgMax(0).Data(), []int{0, 0, 0, 1, 0, 1})
+}
+
+func TestLogCumSumExp(t *testing.T) {
+	a := tensor.CreateTensor([]float64{0, 0, 0, 1000, 1000, -1000}, types.Shape{2, 3})
+	res := a.LogCumSumExp(1).Data()
+	expected := []float64{0, math.Ln2, math.Log(3), 1000, 1000 + math.Ln2, 1000 + math.Ln2}
+	for i := range expected {
+		if math.Abs(res[i]-expected[i]) > 1e-9 {
+			t.Errorf("LogCumSumExp must be %v, got %v", expected, res)
+			break
+		}
+	}
+	inf := tensor.CreateTensor([]float32{float32(math.Inf(-1)), 0}, types.Shape{2})
+	assertEqualSlices(t, inf.LogCumSumExp(0).Data(), []float32{float32(math.Inf(-1)), 0})
+}
+
+func TestDiff(t *testing.T) {
+	a := tensor.CreateTensor([]float32{1, 2, 4, 7, 0, 10, 20, 30}, types.Shape{2, 4})
+	assertEqualSlices(t, a.Diff(1, 1).Data(), []float32{1, 2, 3, 10, 10, 10})
+	assertEqualSlices(t, a.Diff(1, 1).Shape(), types.Shape{2, 3})
+	assertEqualSlices(t, a.Diff(2, -1).Data(), []float32{1, 1, 0, 0})
+	assertEqualSlices(t, a.Diff(1, 0).Data(), []float32{-1, 8, 16, 23})
+	assertEqualSlices(t, a.Diff(0, 0).Data(), a.Data())
+	// Diff is the inverse of CumSum
+	assertEqualSlices(t, a.CumSum(1).Diff(1, 1).Data(), []float32{2, 4, 7, 10, 20, 30})
+	assert(t, a.Diff(4, 1).Err != nil)
+	assert(t, tensor.Range[int](3).Diff(-1, 0).Err != nil)
+}
+
+func TestCumulativeLarge(t *testing.T) {
+	// long lanes are scanned in parallel blocks
+	n := 100003
+	a := tensor.Range[int64](n)
+	sum := a.CumSum(0).Data()
+	for _, i := range []int{0, 1, 16383, 16384, 50000, n - 1} {
+		if sum[i] != int64(i)*int64(i+1)/2 {
+			t.Fatalf("CumSum[%v] must be %v, got %v", i, int64(i)*int64(i+1)/2, sum[i])
+		}
+	}
+	b := tensor.Range[int64](2*n).Reshape(types.Dim(n), 2)
+	cols := b.CumSum(0).Data()
+	assertStatement(t, cols[2*(n-1)], Equals, int64(n)*int64(n-1))
+	assertStatement(t, cols[2*(n-1)+1], Equals, int64(n)*int64(n-1)+int64(n))
+
+	// running max of a sawtooth
+	saw := tensor.Range[int32](n).Mod(tensor.Scalar[int32](1000)).Add(tensor.Range[int32](n).FloorDiv(tensor.Scalar[int32](1000)))
+	mx := saw.CumMax(0).Data()
+	assertStatement(t, mx[n-1], Equals, int32(999+99))
+	assertStatement(t, mx[1500], Equals, int32(999))
+
+	z := tensor.Zeros[float64](types.Dim(n)).LogCumSumExp(0).Data()
+	if math.Abs(z[n-1]-math.Log(float64(n))) > 1e-6 {
+		t.Errorf("LogCumSumExp of zeros must be log(n), got %v", z[n-1])
+	}
+}
+
+func TestGradCumulative(t *testing.T) {
+	x := grad.Variable(tensor.Range[float32](1, 7).Reshape(2, 3))
+	x.CumSum(1).Backward(tensor.Ones[float32](2, 3))
+	assertEqualSlices(t, x.Grad.Data(), []float32{3, 2, 1, 3, 2, 1})
+
+	// d(cumprod) with a zero: y = [2, 0, 0], x = [2,0,3]
+	p := grad.Variable(tensor.CreateTensor([]float32{2, 0, 3}, types.Shape{3}))
+	p.CumProd(0).Backward(tensor.Ones[float32](3))
+	// dy0/dx = [1,0,0], dy1/dx = [0,2,0], dy2/dx = [0,6,0]
+	assertEqualSlices(t, p.Grad.Data(), []float32{1, 8, 0})
+
+	q := grad.Variable(tensor.CreateTensor([]float32{1, 2, 3, 4}, types.Shape{2, 2}))
+	q.CumProd(0).Backward(tensor.Ones[float32](2, 2))
+	assertEqualSlices(t, q.Grad.Data(), []float32{4, 5, 1, 2})
+
+	m := grad.Variable(tensor.CreateTensor([]float32{1, 3, 2, 5}, types.Shape{4}))
+	m.CumMax(0).Backward(tensor.CreateTensor([]float32{1, 10, 100, 1000}, types.Shape{4}))
+	assertEqualSlices(t, m.Grad.Data(), []float32{1, 110, 0, 1000})
+	n := grad.Variable(tensor.CreateTensor([]float32{3, 1, 2, 0}, types.Shape{4}))
+	n.CumMin(0).Backward(tensor.CreateTensor([]float32{1, 10, 100, 1000}, types.Shape{4}))
+	assertEqualSlices(t, n.Grad.Data(), []float32{1, 110, 0, 1000})
+
+	d := grad.Variable(tensor.Range[float32](5))
+	d.Diff(2, 0).Backward(tensor.CreateTensor([]float32{1, 2, 3}, types.Shape{3}))
+	// diff2[k] = x[k] - 2x[k+1] + x[k+2]
+	assertEqualSlices(t, d.Grad.Data(), []float32{1, 0, 0, -4, 3})
+}
+
+func TestGradLogCumSumExp(t *testing.T) {
+	values := []float64{0.5, -1, 2, 0.1, 3, -2}
+	g := []float64{1, -2, 0.5, 3, 1, 2}
+	x := grad.Variable(tensor.CreateTensor(values, types.Shape{2, 3}))
+	x.LogCumSumExp(1).Backward(tensor.CreateTensor(g, types.Shape{2, 3}))
+
+	// finite differences of sum(g * LogCumSumExp(x))
+	f := func(v []float64) float64 {
+		y := tensor.CreateTensor(v, types.Shape{2, 3}).LogCumSumExp(1).Data()
+		s := 0.
+		for i := range y {
+			s += g[i] * y[i]
+		}
+		return s
+	}
+	eps := 1e-6
+	for i := range values {
+		plus := append([]float64(nil), values...)
+		minus := append([]float64(nil), values...)
+		plus[i] += eps
+		minus[i] -= eps
+		numeric := (f(plus) - f(minus)) / (2 * eps)
+		if math.Abs(numeric-x.Grad.Data()[i]) > 1e-5 {
+			t.Errorf("grad[%v] must be %v, got %v", i, numeric, x.Grad.Data()[i])
+		}
+	}
+}
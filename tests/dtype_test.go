@@ -0,0 +1,134 @@
+package main
+
+import (
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"testing"
+)
+
+func TestPromoteTypes(t *testing.T) {
+	cases := []struct {
+		a, b, want tensor.DType
+	}{
+		{tensor.Int32, tensor.Int32, tensor.Int32},
+		{tensor.Int32, tensor.Float32, tensor.Float32},
+		{tensor.Int16, tensor.Float16, tensor.Float16},
+		// 16-bit floats are widened if the integer overflows them
+		{tensor.Uint16, tensor.Float16, tensor.Float32},
+		{tensor.Int32, tensor.Float16, tensor.Float32},
+		{tensor.Uint32, tensor.BFloat16, tensor.BFloat16},
+		{tensor.Float32, tensor.Float64, tensor.Float64},
+		{tensor.Int8, tensor.Int32, tensor.Int32},
+		{tensor.Int64, tensor.Int, tensor.Int},
+		{tensor.Uint8, tensor.Uint16, tensor.Uint16},
+		{tensor.Uint8, tensor.Int8, tensor.Int16},
+		{tensor.Uint16, tensor.Int32, tensor.Int32},
+		{tensor.Uint32, tensor.Int32, tensor.Int64},
+	}
+	for _, c := range cases {
+		got, err := tensor.PromoteTypes(c.a, c.b)
+		assert(t, err == nil)
+		assertStatement(t, got, Equals, c.want)
+		// promotion is symmetric
+		got, _ = tensor.PromoteTypes(c.b, c.a)
+		assertStatement(t, got, Equals, c.want)
+	}
+	_, err := tensor.PromoteTypes(tensor.Uint64, tensor.Int8)
+	assert(t, err != nil)
+	// no float holds every 64-bit integer
+	for _, i := range []tensor.DType{tensor.Int64, tensor.Uint64, tensor.Int, tensor.Uint} {
+		_, err = tensor.PromoteTypes(i, tensor.Float32)
+		assert(t, err != nil)
+		_, err = tensor.PromoteTypes(tensor.Float64, i)
+		assert(t, err != nil)
+	}
+	_, err = tensor.PromoteTypes(tensor.InvalidDType, tensor.Int8)
+	assert(t, err != nil)
+
+	assertStatement(t, tensor.DTypeOf[float32](), Equals, tensor.Float32)
+	assertStatement(t, tensor.DTypeOf[byte](), Equals, tensor.Uint8)
+	assertStatement(t, tensor.Int16.String(), Equals, "int16")
+
+	assert(t, tensor.CanCast(tensor.Int16, tensor.Float32))
+	assert(t, tensor.CanCast(tensor.Int32, tensor.Float64))
+	assert(t, tensor.CanCast(tensor.Uint8, tensor.Int16))
+	assert(t, !tensor.CanCast(tensor.Int32, tensor.Float32))
+	assert(t, !tensor.CanCast(tensor.Float32, tensor.Int64))
+	assert(t, !tensor.CanCast(tensor.Int8, tensor.Uint64))
+	assert(t, !tensor.CanCast(tensor.Uint8, tensor.Int8))
+}
+
+func TestAnyTensorOps(t *testing.T) {
+	labels := tensor.Wrap(tensor.CreateTensor([]int32{0, 1, 1}, types.Shape{3}))
+	preds := tensor.Wrap(tensor.CreateTensor([]float32{0, 0.5, 1}, types.Shape{3}))
+
+	diff := labels.Sub(preds)
+	assert(t, diff.Err == nil)
+	assertStatement(t, diff.DType(), Equals, tensor.Float32)
+	assertEqualSlices(t, tensor.Cast[float32](diff).Data(), []float32{0, 0.5, 0})
+
+	hits := preds.Eq(labels)
+	assertEqualMasks(t, hits, []bool{true, false, true})
+	assertEqualMasks(t, labels.Lt(tensor.Wrap(tensor.Scalar[uint8](1))), []bool{true, false, false})
+	assert(t, tensor.Wrap(tensor.Ones[uint64](2)).Eq(tensor.Wrap(tensor.Ones[int8](2))).Err != nil)
+
+	// broadcasting and integer promotion
+	a := tensor.Wrap(tensor.Range[uint8](6).Reshape(2, 3))
+	b := tensor.Wrap(tensor.CreateTensor([]int8{-1, 0, 1}, types.Shape{3}))
+	sum := a.Add(b)
+	assertStatement(t, sum.DType(), Equals, tensor.Int16)
+	assertEqualSlices(t, sum.Shape(), types.Shape{2, 3})
+	assertEqualSlices(t, tensor.Cast[int16](sum).Data(), []int16{-1, 1, 3, 2, 4, 6})
+
+	// integer division stays integer
+	q := tensor.Wrap(tensor.Range[int32](5)).Div(tensor.Wrap(tensor.Scalar[int64](2)))
+	assertStatement(t, q.DType(), Equals, tensor.Int64)
+	assertEqualSlices(t, tensor.Cast[int64](q).Data(), []int64{0, 0, 1, 1, 2})
+
+	m := tensor.Wrap(tensor.Ones[int32](2, 3)).MatMul(tensor.Wrap(tensor.Ones[float64](3, 1)))
+	assertStatement(t, m.DType(), Equals, tensor.Float64)
+	assertEqualSlices(t, tensor.Cast[float64](m).Data(), []float64{3, 3})
+
+	// errors are propagated
+	u := tensor.Wrap(tensor.Ones[uint64](2))
+	assert(t, u.Add(tensor.Wrap(tensor.Ones[int32](2))).Err != nil)
+	assert(t, u.Mul(tensor.Wrap(tensor.Ones[float64](2))).Err != nil)
+	// int32 overflows float16
+	h := tensor.Wrap(tensor.CreateTensor([]int32{70000}, types.Shape{1}))
+	scaled := h.Mul(tensor.Wrap(tensor.Ones[types.Float16](1)))
+	assertStatement(t, scaled.DType(), Equals, tensor.Float32)
+	assertEqualSlices(t, tensor.Cast[float32](scaled).Data(), []float32{70000})
+	bad := tensor.Wrap(tensor.Ones[float32](2)).Add(tensor.Wrap(tensor.Ones[int32](3)))
+	assert(t, bad.Err != nil)
+	assert(t, tensor.Cast[float32](bad).Err != nil)
+}
+
+func TestAnyTensorConversion(t *testing.T) {
+	ints := tensor.CreateTensor([]int16{-3, 0, 7}, types.Shape{3})
+	wrapped := tensor.Wrap(ints)
+	assertStatement(t, wrapped.DType(), Equals, tensor.Int16)
+	assertStatement(t, wrapped.Size(), Equals, uint32(3))
+
+	// same dtype returns the wrapped tensor
+	same, err := tensor.Unwrap[int16](wrapped)
+	assert(t, err == nil)
+	assert(t, same == ints)
+
+	// safe conversions are implicit
+	f, err := tensor.Unwrap[float32](wrapped)
+	assert(t, err == nil)
+	assertEqualSlices(t, f.Data(), []float32{-3, 0, 7})
+
+	// lossy conversions must be explicit
+	floats := tensor.Wrap(tensor.CreateTensor([]float64{1.5, -2.7}, types.Shape{2}))
+	_, err = tensor.Unwrap[int32](floats)
+	assert(t, err != nil)
+	_, err = tensor.Unwrap[uint16](wrapped)
+	assert(t, err != nil)
+	assertEqualSlices(t, tensor.Cast[int32](floats).Data(), []int32{1, -2})
+
+	as := floats.AsDType(tensor.Float32)
+	assertStatement(t, as.DType(), Equals, tensor.Float32)
+	assertEqualSlices(t, tensor.Cast[float32](as).Data(), []float32{1.5, -2.7})
+	assert(t, floats.AsDType(tensor.InvalidDType).Err != nil)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"gograd/grad"
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"testing"
+)
+
+func mustEinsum(t *testing.T, subscripts string, operands ...*tensor.Tensor[float32]) *tensor.Tensor[float32] {
+	t.Helper()
+	out, err := tensor.Einsum(subscripts, operands...)
+	if err != nil {
+		t.Fatalf("Einsum(%v) failed: %v", subscripts, err)
+	}
+	return out
+}
+
+func TestEinsumMatMul(t *testing.T) {
+	a := tensor.Range[float32](6).Reshape(2, 3)
+	b := tensor.Range[float32](12).Reshape(3, 4)
+	assertAllClose(t, mustEinsum(t, "ij,jk->ik", a, b), a.MatMul(b))
+	// implicit output
+	assertAllClose(t, mustEinsum(t, "ij,jk", a, b), a.MatMul(b))
+	// transposed output
+	assertAllClose(t, mustEinsum(t, "ij,jk->ki", a, b), a.MatMul(b).T().AsContiguous())
+	// matrix-vector
+	v := tensor.Range[float32](3)
+	assertAllClose(t, mustEinsum(t, "ij,j->i", a, v), a.MatMul(v))
+
+	x := tensor.Range[float32](24).Reshape(2, 3, 4)
+	y := tensor.Range[float32](40).Reshape(2, 4, 5)
+	out := mustEinsum(t, "bij,bjk->bik", x, y)
+	assertEqualSlices(t, out.Shape(), types.Shape{2, 3, 5})
+	assertAllClose(t, out, x.MatMul(y))
+
+	// batch dims in the middle: "ibj,bjk->bik"
+	xt := x.View().T(1, 0, 2).AsContiguous()
+	assertAllClose(t, mustEinsum(t, "ibj,bjk->bik", xt, y), x.MatMul(y))
+}
+
+func TestEinsumSingleOperand(t *testing.T) {
+	a := tensor.Range[float32](9).Reshape(3, 3)
+	out := mustEinsum(t, "ij->ji", a)
+	assertEqualSlices(t, out.Data(), []float32{0, 3, 6, 1, 4, 7, 2, 5, 8})
+	// result does not share the buffer of the operand
+	out.Data()[0] = 100
+	assertEqualSlices(t, a.Data()[:1], []float32{0})
+
+	assertEqualSlices(t, mustEinsum(t, "ii", a).Data(), []float32{12})
+	assertEqualSlices(t, mustEinsum(t, "ii->i", a).Data(), []float32{0, 4, 8})
+	assertEqualSlices(t, mustEinsum(t, "ij->", a).Data(), []float32{36})
+	assertEqualSlices(t, mustEinsum(t, "ij->j", a).Data(), []float32{9, 12, 15})
+
+	b := tensor.Range[float32](24).Reshape(2, 3, 4)
+	assertEqualSlices(t, mustEinsum(t, "ijk->kji", b).Shape(), types.Shape{4, 3, 2})
+	assertAllClose(t, mustEinsum(t, "ijk->kji", b), b.T().AsContiguous())
+	// batched trace
+	c := tensor.Range[float32](18).Reshape(2, 3, 3)
+	assertEqualSlices(t, mustEinsum(t, "bii->b", c).Data(), []float32{12, 39})
+}
+
+func TestEinsumOuterAndElementwise(t *testing.T) {
+	a := tensor.CreateTensor([]float32{1, 2}, types.Shape{2})
+	b := tensor.CreateTensor([]float32{3, 4, 5}, types.Shape{3})
+	outer := mustEinsum(t, "i,j->ij", a, b)
+	assertEqualSlices(t, outer.Shape(), types.Shape{2, 3})
+	assertEqualSlices(t, outer.Data(), []float32{3, 4, 5, 6, 8, 10})
+	assertEqualSlices(t, mustEinsum(t, "i,i->", a, a).Data(), []float32{5})
+	// full contractions give 0-d scalars like full reductions
+	assertEqualSlices(t, mustEinsum(t, "i,i->", a, a).Shape(), types.Shape{})
+	assertEqualSlices(t, mustEinsum(t, "i,i", a, a).Shape(), types.Shape{})
+	assertEqualSlices(t, mustEinsum(t, "ii", tensor.Eye[float32](2, 2)).Shape(), types.Shape{})
+	assertEqualSlices(t, mustEinsum(t, "i,i->i", a, a).Data(), []float32{1, 4})
+
+	// three operands: a^T @ m @ b
+	m := tensor.Range[float32](6).Reshape(2, 3)
+	assertEqualSlices(t, mustEinsum(t, "i,ij,j->", a, m, b).Data(), []float32{114})
+}
+
+func TestEinsumEllipsis(t *testing.T) {
+	a := tensor.Range[float32](24).Reshape(2, 3, 4)
+	b := tensor.Range[float32](20).Reshape(4, 5)
+	out := mustEinsum(t, "...ij,jk->...ik", a, b)
+	assertEqualSlices(t, out.Shape(), types.Shape{2, 3, 5})
+	assertAllClose(t, out, a.MatMul(b))
+	// implicit output keeps the ellipsis dims first
+	assertAllClose(t, mustEinsum(t, "...ij,jk", a, b), a.MatMul(b))
+
+	// broadcasted batch dims
+	x := tensor.Range[float32](12).Reshape(2, 1, 2, 3)
+	y := tensor.Range[float32](18).Reshape(3, 3, 2)
+	out = mustEinsum(t, "...ij,...jk->...ik", x, y)
+	assertEqualSlices(t, out.Shape(), types.Shape{2, 3, 2, 2})
+	assertAllClose(t, out, x.MatMul(y))
+	assertEqualSlices(t, mustEinsum(t, "i...->...", a).Shape(), types.Shape{3, 4})
+}
+
+func TestEinsumErrors(t *testing.T) {
+	a := tensor.Range[float32](6).Reshape(2, 3)
+	cases := []struct {
+		subscripts string
+		operands   []*tensor.Tensor[float32]
+	}{
+		{"ij,jk->ik", []*tensor.Tensor[float32]{a}},
+		{"ijk->i", []*tensor.Tensor[float32]{a}},
+		{"i->i", []*tensor.Tensor[float32]{a}},
+		{"ij,jk->ik", []*tensor.Tensor[float32]{a, a}},
+		{"ij->k", []*tensor.Tensor[float32]{a}},
+		{"ij->ii", []*tensor.Tensor[float32]{a}},
+		{"i1->i", []*tensor.Tensor[float32]{a}},
+		{"i..j->i", []*tensor.Tensor[float32]{a}},
+		{"ii", []*tensor.Tensor[float32]{a}},
+		{"ij", nil},
+	}
+	for _, c := range cases {
+		if _, err := tensor.Einsum(c.subscripts, c.operands...); err == nil {
+			t.Errorf("Einsum(%v) must fail", c.subscripts)
+		}
+	}
+}
+
+func TestGradEinsum(t *testing.T) {
+	// matches the MatMul gradients
+	a := grad.Variable(tensor.Range[float32](24).Reshape(2, 3, 4))
+	b := grad.Variable(tensor.Range[float32](40).Reshape(2, 4, 5))
+	out := grad.Einsum("bij,bjk->bik", a, b)
+	out.Backward(tensor.Ones[float32](2, 3, 5))
+	a2 := grad.Variable(tensor.Range[float32](24).Reshape(2, 3, 4))
+	b2 := grad.Variable(tensor.Range[float32](40).Reshape(2, 4, 5))
+	a2.MatMul(b2).Backward(tensor.Ones[float32](2, 3, 5))
+	assertAllClose(t, a.Grad, a2.Grad)
+	assertAllClose(t, b.Grad, b2.Grad)
+
+	// trace: d(tr(x)) = I
+	x := grad.Variable(tensor.Range[float32](9).Reshape(3, 3))
+	grad.Einsum("ii", x).Backward(nil)
+	assertAllClose(t, x.Grad, tensor.Eye[float32](3, 3))
+
+	// sum over the dim missing in the other operands
+	m := grad.Variable(tensor.Range[float32](6).Reshape(2, 3))
+	v := grad.Constant(tensor.CreateTensor([]float32{1, 2}, types.Shape{2}))
+	grad.Einsum("ij,i->", m, v).Backward(nil)
+	assertEqualSlices(t, m.Grad.Data(), []float32{1, 1, 1, 2, 2, 2})
+
+	// outer product
+	p := grad.Variable(tensor.CreateTensor([]float32{1, 2}, types.Shape{2}))
+	q := grad.Variable(tensor.CreateTensor([]float32{3, 4, 5}, types.Shape{3}))
+	grad.Einsum("i,j->ij", p, q).Backward(tensor.Ones[float32](2, 3))
+	assertEqualSlices(t, p.Grad.Data(), []float32{12, 12})
+	assertEqualSlices(t, q.Grad.Data(), []float32{3, 3, 3})
+
+	// broadcasted ellipsis dims are summed
+	e := grad.Variable(tensor.Range[float32](6).Reshape(1, 2, 3))
+	w := grad.Variable(tensor.Ones[float32](4, 3, 1))
+	res := grad.Einsum("...ij,...jk->...ik", e, w)
+	assertEqualSlices(t, res.Value.Shape(), types.Shape{4, 2, 1})
+	res.Backward(tensor.Ones[float32](4, 2, 1))
+	assertEqualSlices(t, e.Grad.Shape(), types.Shape{1, 2, 3})
+	assertEqualSlices(t, e.Grad.Data(), []float32{4, 4, 4, 4, 4, 4})
+	assertEqualSlices(t, w.Grad.Data(), []float32{3, 5, 7, 3, 5, 7, 3, 5, 7, 3, 5, 7})
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"gograd/grad"
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"math"
+	"testing"
+)
+
+func TestReshapeInfer(t *testing.T) {
+	a := tensor.Range[int](24)
+	assertEqualSlices(t, a.Reshape(6, -1).Shape(), types.Shape{6, 4})
+	assertEqualSlices(t, a.Reshape(-1, 2, 3).Shape(), types.Shape{4, 2, 3})
+	assertEqualSlices(t, a.Reshape(-1).Shape(), types.Shape{24})
+	// non-contiguous input
+	b := tensor.Range[int](6).Reshape(2, 3).T().Reshape(-1)
+	assertEqualSlices(t, b.Data(), []int{0, 3, 1, 4, 2, 5})
+
+	assert(t, tensor.Range[int](24).Reshape(-1, -1).Err != nil)
+	assert(t, tensor.Range[int](24).Reshape(5, -1).Err != nil)
+	assert(t, tensor.Range[int](24).Reshape(-2, 12).Err != nil)
+	assert(t, tensor.Range[int](24).Reshape(0, -1).Err != nil)
+	assert(t, tensor.CreateEmptyTensor[int](-1).Err != nil)
+}
+
+func TestScalar0D(t *testing.T) {
+	s := tensor.Scalar[float32](3)
+	assertEqualSlices(t, s.Shape(), types.Shape{})
+	assertStatement(t, s.Size(), Equals, uint32(1))
+	assertStatement(t, s.Item(), Equals, float32(3))
+	assertEqualSlices(t, s.Data(), []float32{3})
+	assertEqualSlices(t, tensor.CreateTensor([]float32{3}, types.Shape{}).Shape(), types.Shape{})
+
+	// broadcasting with 0-d
+	a := tensor.Range[float32](6).Reshape(2, 3)
+	assertEqualSlices(t, a.Add(s).Shape(), types.Shape{2, 3})
+	assertEqualSlices(t, s.Mul(a).Data(), []float32{0, 3, 6, 9, 12, 15})
+	assertEqualSlices(t, s.Add(tensor.Scalar[float32](1)).Shape(), types.Shape{})
+	assertEqualSlices(t, s.Add(tensor.Ones[float32](1, 1)).Shape(), types.Shape{1, 1})
+
+	// full reductions give 0-d
+	assertEqualSlices(t, a.Sum(false).Shape(), types.Shape{})
+	assertEqualSlices(t, a.Max(false, 0, 1).Shape(), types.Shape{})
+	assertEqualSlices(t, a.Mean(false).Shape(), types.Shape{})
+	assertEqualSlices(t, a.Var(0, false).Shape(), types.Shape{})
+	assertEqualSlices(t, a.Sum(true).Shape(), types.Shape{1, 1})
+	assertEqualSlices(t, a.ArgMax(0, false).Shape(), types.Shape{3})
+	assertEqualSlices(t, tensor.Range[float32](3).ArgMax(0, false).Shape(), types.Shape{})
+	// 0-d reductions and shaping
+	assertEqualSlices(t, s.Sum(false).Data(), []float32{3})
+	assertEqualSlices(t, s.Mean(false).Data(), []float32{3})
+	assertEqualSlices(t, s.Reshape(1, 1).Shape(), types.Shape{1, 1})
+	assertEqualSlices(t, tensor.Ones[int](1, 1).Reshape().Shape(), types.Shape{})
+	assertEqualSlices(t, tensor.Scalar(2).Unsqueeze(0).Shape(), types.Shape{1})
+	assertEqualSlices(t, tensor.Scalar(2).T().Shape(), types.Shape{})
+	assert(t, tensor.Scalar(2).Sum(false, 0).Err != nil)
+
+	assertStatement(t, tensor.Scalar[int32](5).ToString(), Equals,
+		"Tensor(5, shape=[], dtype=int32, order=[], strides=[])")
+	decoded := tensor.DecodeBytes[float64](tensor.Scalar[float64](2.5).EncodeToBytes())
+	assertEqualSlices(t, decoded.Shape(), types.Shape{})
+	assertEqualSlices(t, decoded.Data(), []float64{2.5})
+}
+
+func TestEmptyTensors(t *testing.T) {
+	e := tensor.CreateEmptyTensor[float32](0, 3)
+	assertEqualSlices(t, e.Shape(), types.Shape{0, 3})
+	assertStatement(t, e.Size(), Equals, uint32(0))
+	assertEqualSlices(t, e.Data(), []float32{})
+	assertEqualSlices(t, tensor.Ones[float32](0, 3).Shape(), types.Shape{0, 3})
+	assertEqualSlices(t, tensor.Range[int](0).Shape(), types.Shape{0})
+
+	// elementwise ops and broadcasting
+	row := tensor.Range[float32](3)
+	assertEqualSlices(t, e.Add(row).Shape(), types.Shape{0, 3})
+	assertEqualSlices(t, e.Mul(tensor.Scalar[float32](2)).Shape(), types.Shape{0, 3})
+	assertEqualSlices(t, e.Add(tensor.Ones[float32](0, 1)).Shape(), types.Shape{0, 3})
+	assertEqualSlices(t, e.Exp().Shape(), types.Shape{0, 3})
+	assertEqualSlices(t, e.Gt(row).Shape(), types.Shape{0, 3})
+	assert(t, tensor.CreateEmptyTensor[float32](0, 3).Add(tensor.Ones[float32](2, 3)).Err != nil)
+
+	// reductions
+	assertEqualSlices(t, e.Sum(false).Data(), []float32{0})
+	assertEqualSlices(t, e.Prod(false).Data(), []float32{1})
+	assertEqualSlices(t, e.Sum(false, 0).Data(), []float32{0, 0, 0})
+	assertEqualSlices(t, e.Prod(true, 0).Data(), []float32{1, 1, 1})
+	assertEqualSlices(t, e.Sum(false, 1).Shape(), types.Shape{0})
+	assertEqualSlices(t, e.Max(false, 1).Shape(), types.Shape{0})
+	assert(t, math.IsNaN(float64(e.Mean(false).Item())))
+	assert(t, tensor.CreateEmptyTensor[float32](0, 3).Max(false).Err != nil)
+	assert(t, tensor.CreateEmptyTensor[int](0, 3).Mean(false).Err != nil)
+	assert(t, tensor.CreateEmptyTensor[float32](0, 3).Min(false, 0).Err != nil)
+	assert(t, tensor.CreateEmptyTensor[float32](0, 3).ArgMax(0, false).Err != nil)
+	assertEqualSlices(t, e.ArgMax(1, false).Shape(), types.Shape{0})
+	assert(t, math.IsNaN(float64(tensor.CreateEmptyTensor[float32](0).Median(false).Item())))
+	medians := e.Median(false, 0).Data()
+	assert(t, len(medians) == 3 && math.IsNaN(float64(medians[0])))
+	assert(t, tensor.CreateEmptyTensor[int](0).Median(false).Err != nil)
+	assertEqualSlices(t, e.CumSum(0).Shape(), types.Shape{0, 3})
+	assertEqualSlices(t, e.CumSum(1).Data(), []float32{})
+	assertEqualSlices(t, tensor.CreateEmptyTensor[float32](2, 0).CumMax(1).Shape(), types.Shape{2, 0})
+	assertEqualSlices(t, e.CumArgMax(0).Shape(), types.Shape{0, 3})
+
+	// shaping
+	assertEqualSlices(t, e.View().Reshape(3, 0).Shape(), types.Shape{3, 0})
+	// -1 is ambiguous for empty tensors
+	assert(t, e.View().Reshape(0, -1, 3).Err != nil)
+	assertEqualSlices(t, e.T().Shape(), types.Shape{3, 0})
+	assertEqualSlices(t, tensor.CreateEmptyTensor[float32](2, 0).T().Shape(), types.Shape{0, 2})
+	assertEqualSlices(t, tensor.CreateEmptyTensor[float32](2, 0).T().Data(), []float32{})
+	joined, err := tensor.Concat(0, e, tensor.Ones[float32](2, 3))
+	assert(t, err == nil)
+	assertEqualSlices(t, joined.Data(), []float32{1, 1, 1, 1, 1, 1})
+	assertEqualSlices(t, tensor.Range[float32](6).Reshape(2, 3).IndexAdv("1:1").Shape(), types.Shape{0, 3})
+	assertEqualSlices(t, tensor.Range[float32](6).Reshape(2, 3).IndexAdv(":, 2:1").Shape(), types.Shape{2, 0})
+	assertEqualSlices(t, e.Clone().Shape(), types.Shape{0, 3})
+	// zero-sized dims are kept
+	assertEqualSlices(t, tensor.CreateEmptyTensor[float32](0, 1, 3).Squeeze().Shape(), types.Shape{0, 3})
+	assertEqualSlices(t, e.Roll(1, 0).Shape(), types.Shape{0, 3})
+	assertEqualSlices(t, e.Roll(-2, 1).Shape(), types.Shape{0, 3})
+
+	// matmul with empty dims
+	assertEqualSlices(t, e.MatMul(tensor.Ones[float32](3, 4)).Shape(), types.Shape{0, 4})
+	zeros := tensor.CreateEmptyTensor[float32](2, 0).MatMul(tensor.CreateEmptyTensor[float32](0, 3))
+	assertEqualSlices(t, zeros.Data(), []float32{0, 0, 0, 0, 0, 0})
+
+	assertStatement(t, e.ToString(), Equals,
+		"Tensor([], shape=[0 3], dtype=float32, order=[0 1], strides=[3 1])")
+	decoded := tensor.DecodeBytes[float32](e.EncodeToBytes())
+	assertEqualSlices(t, decoded.Shape(), types.Shape{0, 3})
+	assertStatement(t, decoded.Size(), Equals, uint32(0))
+}
+
+func TestFilterToEmpty(t *testing.T) {
+	// filtering a batch down to nothing must not break the following ops
+	batch := tensor.Range[float32](6).Reshape(2, 3)
+	mask := batch.Gt(tensor.Scalar[float32](10))
+	selected := batch.MaskedSelect(mask)
+	assertEqualSlices(t, selected.Shape(), types.Shape{0})
+	assertEqualSlices(t, selected.Sum(false).Data(), []float32{0})
+	assertEqualSlices(t, selected.View().Reshape(-1, 3).Shape(), types.Shape{0, 3})
+	assertEqualSlices(t, selected.Mul(tensor.Scalar[float32](2)).Shape(), types.Shape{0})
+	values, _ := selected.TopK(0, 0)
+	assertEqualSlices(t, values.Shape(), types.Shape{0})
+	assertEqualSlices(t, selected.Sort(0, false).Shape(), types.Shape{0})
+
+	x := grad.Variable(tensor.CreateEmptyTensor[float32](0, 3))
+	w := grad.Variable(tensor.Ones[float32](3, 2))
+	loss := x.MatMul(w).Mean()
+	loss.Backward(nil)
+	assertEqualSlices(t, w.Grad.Data(), []float32{0, 0, 0, 0, 0, 0})
+	assertEqualSlices(t, x.Grad.Shape(), types.Shape{0, 3})
+}
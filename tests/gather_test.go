@@ -0,0 +1,129 @@
+package main
+
+import (
+	"gograd/grad"
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"testing"
+)
+
+func TestGather(t *testing.T) {
+	a := tensor.Range[float32](1, 7).Reshape(2, 3)
+	index := tensor.CreateTensor([]int{2, 0}, types.Shape{2, 1})
+	assertEqualSlices(t, a.Gather(1, index).MustAssert().Data(), []float32{3, 4})
+
+	// index can be smaller than the tensor along other dims, negative values are counted from the end
+	index = tensor.CreateTensor([]int{1, -1}, types.Shape{1, 2})
+	out := a.Gather(0, index).MustAssert()
+	assertEqualSlices(t, out.Shape(), types.Shape{1, 2})
+	assertEqualSlices(t, out.Data(), []float32{4, 5})
+
+	// views are gathered with their strides
+	out = a.T().Gather(1, tensor.CreateTensor([]int{1, 0, 1}, types.Shape{3, 1})).MustAssert()
+	assertEqualSlices(t, out.Data(), []float32{4, 2, 6})
+
+	assert(t, a.Clone().Gather(1, tensor.CreateTensor([]int{3}, types.Shape{1, 1})).Err != nil)
+	assert(t, a.Clone().Gather(1, tensor.CreateTensor([]int{0}, types.Shape{1})).Err != nil)
+	assert(t, a.Clone().Gather(1, tensor.Zeros[int](3, 1)).Err != nil)
+}
+
+func TestScatter(t *testing.T) {
+	a := tensor.Zeros[float32](2, 3)
+	index := tensor.CreateTensor([]int{2, 0}, types.Shape{2, 1})
+	src := tensor.CreateTensor([]float32{7, 8}, types.Shape{2, 1})
+	out := a.Scatter(1, index, src).MustAssert()
+	assertEqualSlices(t, out.Data(), []float32{0, 0, 7, 8, 0, 0})
+	// a is not changed unless it is passed as out
+	assertEqualSlices(t, a.Data(), []float32{0, 0, 0, 0, 0, 0})
+	a.Scatter(1, index, tensor.Scalar[float32](1), a)
+	assertEqualSlices(t, a.Data(), []float32{0, 0, 1, 1, 0, 0})
+
+	b := tensor.Zeros[int32](3)
+	sum := b.ScatterAdd(0, tensor.CreateTensor([]int{0, 2, 0}, types.Shape{3}), tensor.Range[int32](1, 4))
+	assertEqualSlices(t, sum.MustAssert().Data(), []int32{4, 0, 2})
+
+	// writes to the view change the original tensor
+	c := tensor.Zeros[float32](2, 2)
+	c.T().Scatter(0, tensor.CreateTensor([]int{1}, types.Shape{1, 1}), tensor.Scalar[float32](5), c.T())
+	assertEqualSlices(t, c.Data(), []float32{0, 5, 0, 0})
+
+	assert(t, a.Clone().Scatter(1, index, tensor.Range[float32](3)).Err != nil)
+}
+
+func TestTakeAlongAxis(t *testing.T) {
+	a := tensor.CreateTensor([]float32{10, 30, 20, 60, 40, 50}, types.Shape{2, 3})
+	out := a.TakeAlongAxis(a.ArgMax(1, true), 1).MustAssert()
+	assertEqualSlices(t, out.Shape(), types.Shape{2, 1})
+	assertEqualSlices(t, out.Data(), []float32{30, 60})
+
+	// index is broadcasted along the rows
+	out = a.TakeAlongAxis(tensor.CreateTensor([]int{2, 0}, types.Shape{1, 2}), 1).MustAssert()
+	assertEqualSlices(t, out.Shape(), types.Shape{2, 2})
+	assertEqualSlices(t, out.Data(), []float32{20, 10, 50, 60})
+
+	// tensor is broadcasted along the rows
+	row := tensor.Range[float32](3).Reshape(1, 3)
+	out = row.TakeAlongAxis(tensor.CreateTensor([]int{0, 2}, types.Shape{2, 1}), 1).MustAssert()
+	assertEqualSlices(t, out.Data(), []float32{0, 2})
+
+	assert(t, a.Clone().TakeAlongAxis(tensor.Zeros[int](3, 1), 1).Err != nil)
+}
+
+func TestIndexSelectAdd(t *testing.T) {
+	a := tensor.Range[float32](1, 7).Reshape(3, 2)
+	index := tensor.CreateTensor([]int{2, 0, 2}, types.Shape{3})
+	out := a.IndexSelect(0, index).MustAssert()
+	assertEqualSlices(t, out.Data(), []float32{5, 6, 1, 2, 5, 6})
+	out = a.IndexSelect(-1, tensor.CreateTensor([]int{1}, types.Shape{1})).MustAssert()
+	assertEqualSlices(t, out.Shape(), types.Shape{3, 1})
+	assertEqualSlices(t, out.Data(), []float32{2, 4, 6})
+
+	src := tensor.CreateTensor([]float32{1, 1, 2, 2, 3, 3}, types.Shape{3, 2})
+	added := tensor.Zeros[float32](3, 2).IndexAdd(0, index, src).MustAssert()
+	assertEqualSlices(t, added.Data(), []float32{2, 2, 0, 0, 4, 4})
+
+	assert(t, a.Clone().IndexSelect(0, tensor.Zeros[int](1, 1)).Err != nil)
+}
+
+func TestGradGatherScatter(t *testing.T) {
+	// embedding lookup, repeated rows accumulate the gradient
+	emb := grad.Variable(tensor.Range[float32](6).Reshape(3, 2))
+	rows := emb.IndexSelect(0, tensor.CreateTensor([]int{2, 0, 2}, types.Shape{3}))
+	rows.Mean().Backward(nil)
+	assertAllClose(t, emb.Grad, tensor.CreateTensor([]float32{1. / 6, 1. / 6, 0, 0, 2. / 6, 2. / 6}, types.Shape{3, 2}))
+
+	// label picking
+	logits := grad.Variable(tensor.Range[float32](6).Reshape(2, 3))
+	labels := tensor.CreateTensor([]int{1, 2}, types.Shape{2, 1})
+	logits.TakeAlongAxis(labels, 1).Mean().Backward(nil)
+	assertEqualSlices(t, logits.Grad.Data(), []float32{0, 0.5, 0, 0, 0, 0.5})
+
+	x := grad.Variable(tensor.Range[float32](4).Reshape(2, 2))
+	x.Gather(0, tensor.CreateTensor([]int{1, 1}, types.Shape{1, 2})).Backward(tensor.Ones[float32](1, 2))
+	assertEqualSlices(t, x.Grad.Data(), []float32{0, 0, 1, 1})
+
+	// overwritten positions don't get the gradient
+	base := grad.Variable(tensor.Zeros[float32](2, 2))
+	src := grad.Variable(tensor.CreateTensor([]float32{1, 2}, types.Shape{2, 1}))
+	index := tensor.CreateTensor([]int{1, 0}, types.Shape{2, 1})
+	w := grad.Constant(tensor.Range[float32](1, 5).Reshape(2, 2))
+	base.Scatter(1, index, src).Mul(w).Mean().Backward(nil)
+	assertAllClose(t, base.Grad, tensor.CreateTensor([]float32{0.25, 0, 0, 1}, types.Shape{2, 2}))
+	assertAllClose(t, src.Grad, tensor.CreateTensor([]float32{0.5, 0.75}, types.Shape{2, 1}))
+
+	base = grad.Variable(tensor.Zeros[float32](3))
+	src = grad.Variable(tensor.Ones[float32](3))
+	w = grad.Constant(tensor.Range[float32](1, 4))
+	base.ScatterAdd(0, tensor.CreateTensor([]int{2, 2, 0}, types.Shape{3}), src).Mul(w).Mean().Backward(nil)
+	assertAllClose(t, base.Grad, tensor.CreateTensor([]float32{1. / 3, 2. / 3, 1}, types.Shape{3}))
+	assertAllClose(t, src.Grad, tensor.CreateTensor([]float32{1, 1, 1. / 3}, types.Shape{3}))
+}
+
+func TestSoftmaxCrossEntropy(t *testing.T) {
+	logits := grad.Variable(tensor.CreateTensor([]float32{0, 0, 1, 1}, types.Shape{2, 2}))
+	y := grad.Constant(tensor.CreateTensor([]float32{1, 0}, types.Shape{2, 1}))
+	loss := logits.SoftmaxCrossEntropy(y).MustAssert()
+	assertEqualSlices(t, loss.Value.Shape(), types.Shape{2, 1})
+	// -log(0.5) for both rows
+	assertAllClose(t, loss.Value, tensor.CreateTensor([]float32{0.6931472, 0.6931472}, types.Shape{2, 1}))
+}
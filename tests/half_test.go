@@ -0,0 +1,248 @@
+package main
+
+import (
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"math"
+	"testing"
+)
+
+func TestHalfScalarConversion(t *testing.T) {
+	f16 := []struct {
+		value float32
+		bits  types.Float16
+	}{
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{65504, 0x7bff},
+		{65520, 0x7c00}, // overflows to Inf
+		{6e-8, 0x0001},  // smallest subnormal
+		{1e-8, 0x0000},  // underflows to zero
+		{2049, 0x6800},  // ties to even: 2048
+		{2051, 0x6802},  // ties to even: 2052
+		{-0.0, 0x0000},
+	}
+	for _, c := range f16 {
+		assertStatement(t, types.Float16FromFloat32(c.value), Equals, c.bits)
+	}
+	assertStatement(t, types.Float16(0x3555).Float32(), Equals, float32(0.33325195))
+	assertStatement(t, types.Float16(0x0001).Float32(), Equals, float32(math.Ldexp(1, -24)))
+	assertStatement(t, types.Float16(0xfc00).Float32(), Equals, float32(math.Inf(-1)))
+	assert(t, math.IsNaN(float64(types.Float16FromFloat32(float32(math.NaN())).Float32())))
+
+	assertStatement(t, types.BFloat16FromFloat32(1), Equals, types.BFloat16(0x3f80))
+	assertStatement(t, types.BFloat16FromFloat32(math.Pi), Equals, types.BFloat16(0x4049))
+	assertStatement(t, types.BFloat16(0x4049).Float32(), Equals, float32(3.140625))
+	assert(t, math.IsNaN(float64(types.BFloat16FromFloat32(float32(math.NaN())).Float32())))
+	assertStatement(t, types.BFloat16(0x3f80).String(), Equals, "1")
+}
+
+func TestHalfAsType(t *testing.T) {
+	// odd size covers both the vector kernels and the remainder
+	rng := tensor.NewRNG(3)
+	a := rng.RandomFloat32(37).Mul(tensor.Scalar[float32](200)).Sub(tensor.Scalar[float32](100))
+	saved := tensor.AUTO_IMPL
+	defer func() { tensor.AUTO_IMPL = saved }()
+	for _, impl := range availableImpls() {
+		tensor.AUTO_IMPL.SetImpl(impl)
+		h := tensor.AsType[float32, types.Float16](a)
+		b := tensor.AsType[float32, types.BFloat16](a)
+		for i, v := range a.Data() {
+			assertStatement(t, h.Data()[i], Equals, types.Float16FromFloat32(v))
+			assertStatement(t, b.Data()[i], Equals, types.BFloat16FromFloat32(v))
+		}
+		back := tensor.AsType[types.Float16, float32](h)
+		for i, v := range h.Data() {
+			assertStatement(t, back.Data()[i], Equals, v.Float32())
+		}
+		assertEqualSlices(t, tensor.AsType[types.BFloat16, float64](b).Shape(), types.Shape{37})
+	}
+	ints := tensor.AsType[types.Float16, int32](tensor.Range[types.Float16](4))
+	assertEqualSlices(t, ints.Data(), []int32{0, 1, 2, 3})
+	halves := tensor.AsType[types.Float16, types.BFloat16](tensor.Ones[types.Float16](2))
+	assertEqualSlices(t, halves.Data(), []types.BFloat16{0x3f80, 0x3f80})
+}
+
+func TestHalfOps(t *testing.T) {
+	a := tensor.AsType[float32, types.Float16](tensor.CreateTensor([]float32{-1.5, 0, 2, 4}, types.Shape{2, 2}))
+	b := tensor.AsType[float32, types.Float16](tensor.CreateTensor([]float32{1, 2}, types.Shape{2}))
+	toF32 := func(x *tensor.Tensor[types.Float16]) []float32 {
+		return tensor.AsType[types.Float16, float32](x).Data()
+	}
+	assertEqualSlices(t, toF32(a.Add(b)), []float32{-0.5, 2, 3, 6})
+	assertEqualSlices(t, toF32(a.Mul(b)), []float32{-1.5, 0, 2, 8})
+	assertEqualSlices(t, toF32(a.Abs()), []float32{1.5, 0, 2, 4})
+	assertEqualMasks(t, a.Lt(b), []bool{true, true, false, false})
+	assertEqualSlices(t, toF32(a.Maximum(b)), []float32{1, 2, 2, 4})
+	assertEqualSlices(t, toF32(a.MatMul(tensor.Ones[types.Float16](2, 1))), []float32{-1.5, 6})
+	assertEqualSlices(t, toF32(a.Sum(false, 1)), []float32{-1.5, 6})
+	assertEqualSlices(t, toF32(a.Max(false)), []float32{4})
+	assertEqualSlices(t, a.ArgMin(1, false).Data(), []int{0, 0})
+	assertEqualSlices(t, a.Sum(false).Shape(), types.Shape{})
+
+	// writes into out
+	out := tensor.Zeros[types.Float16](2, 2)
+	a.Neg(out)
+	assertEqualSlices(t, toF32(out), []float32{1.5, 0, -2, -4})
+
+	// the sum is accumulated in float32, a float16 accumulator would get stuck at 2048
+	ones := tensor.Ones[types.BFloat16](4096)
+	assertStatement(t, ones.Sum(false).Item().Float32(), Equals, float32(4096))
+
+	// shaping ops don't depend on the dtype
+	assertEqualSlices(t, toF32(a.T()), []float32{-1.5, 2, 0, 4})
+
+	// bitwise ops are for integers, 16-bit floats report an error instead of mixing the bits
+	c := tensor.Ones[types.Float16](2)
+	assert(t, c.BitAnd(c).Err != nil)
+
+	assertStatement(t, b.ToString(), Equals,
+		"Tensor([1.00000000, 2.00000000], shape=[2], dtype=float16, order=[0], strides=[1])")
+}
+
+func TestHalfSerialization(t *testing.T) {
+	a := tensor.AsType[float32, types.BFloat16](tensor.CreateTensor([]float32{0.5, -3, 1e10}, types.Shape{3, 1}))
+	decoded := tensor.DecodeBytes[types.BFloat16](a.EncodeToBytes())
+	assertEqualSlices(t, decoded.Shape(), types.Shape{3, 1})
+	assertEqualSlices(t, decoded.Data(), a.Data())
+
+	h := tensor.AsType[float32, types.Float16](tensor.CreateTensor([]float32{0.1, 7}, types.Shape{2}))
+	decoded_h := tensor.DecodeBytes[types.Float16](h.EncodeToBytes())
+	assertEqualSlices(t, decoded_h.Data(), h.Data())
+}
+
+func TestHalfDType(t *testing.T) {
+	assertStatement(t, tensor.DTypeOf[types.Float16](), Equals, tensor.Float16)
+	promoted, err := tensor.PromoteTypes(tensor.Float16, tensor.BFloat16)
+	assert(t, err == nil)
+	assertStatement(t, promoted, Equals, tensor.Float32)
+	promoted, _ = tensor.PromoteTypes(tensor.Int8, tensor.BFloat16)
+	assertStatement(t, promoted, Equals, tensor.BFloat16)
+	assert(t, tensor.CanCast(tensor.Float16, tensor.Float32))
+	assert(t, !tensor.CanCast(tensor.Float32, tensor.Float16))
+	assert(t, !tensor.CanCast(tensor.Float16, tensor.BFloat16))
+
+	h := tensor.Wrap(tensor.Ones[types.Float16](2))
+	sum := h.Add(tensor.Wrap(tensor.Ones[float32](2)))
+	assertStatement(t, sum.DType(), Equals, tensor.Float32)
+	assertEqualSlices(t, tensor.Cast[float32](sum).Data(), []float32{2, 2})
+	f, err := tensor.Unwrap[float64](h)
+	assert(t, err == nil)
+	assertEqualSlices(t, f.Data(), []float64{1, 1})
+}
+
+func halfTensor(values []float32, shape ...types.Dim) *tensor.Tensor[types.Float16] {
+	return tensor.AsType[float32, types.Float16](tensor.CreateTensor(values, shape))
+}
+
+func halfValues[T types.Half](x *tensor.Tensor[T]) []float32 {
+	x.MustAssert()
+	return tensor.AsType[T, float32](x).Data()
+}
+
+func TestHalfScalar(t *testing.T) {
+	// Scalar would keep the bits 2, which is a subnormal
+	assert(t, tensor.Scalar[types.Float16](2).Err != nil)
+	assertEqualSlices(t, halfValues(tensor.ScalarOf[types.Float16](2.5)), []float32{2.5})
+	assertEqualSlices(t, halfValues(tensor.ScalarOf[types.BFloat16](-3)), []float32{-3})
+	assertStatement(t, tensor.ScalarOf[int32](2.5).Item(), Equals, int32(2))
+
+	a := halfTensor([]float32{1, 2}, 2)
+	assertEqualSlices(t, a.Index(1).Shape(), types.Shape{})
+	assertEqualSlices(t, halfValues(a.Index(1)), []float32{2})
+	assertEqualSlices(t, halfValues(a.Mul(tensor.ScalarOf[types.Float16](3))), []float32{3, 6})
+}
+
+func TestHalfSorting(t *testing.T) {
+	// bits of negative values are greater than the bits of positive ones
+	a := halfTensor([]float32{2, -1, 0.5, -3, 4, 1}, 2, 3)
+	assertEqualSlices(t, halfValues(a.Sort(1, false)), []float32{-1, 0.5, 2, -3, 1, 4})
+	assertEqualSlices(t, a.ArgSort(1, true).Data(), []int{0, 2, 1, 1, 2, 0})
+	values, indices := a.TopK(1, 0)
+	assertEqualSlices(t, halfValues(values), []float32{2, 4, 1})
+	assertEqualSlices(t, indices.Data(), []int{0, 1, 1})
+
+	unique, inverse, counts := halfTensor([]float32{1, -2, 1}, 3).Unique()
+	assertEqualSlices(t, halfValues(unique), []float32{-2, 1})
+	assertEqualSlices(t, inverse.Data(), []int{1, 0, 1})
+	assertEqualSlices(t, counts.Data(), []int{1, 2})
+
+	sorted := halfTensor([]float32{-2, -1, 0, 3}, 4)
+	assertEqualSlices(t, sorted.SearchSorted(halfTensor([]float32{-1.5, 1}, 2), false).Data(), []int{1, 3})
+}
+
+func TestHalfCumulative(t *testing.T) {
+	a := tensor.AsType[float32, types.BFloat16](tensor.CreateTensor([]float32{1, -2, 3, -4}, types.Shape{4}))
+	assertEqualSlices(t, halfValues(a.CumSum(0)), []float32{1, -1, 2, -2})
+	assertEqualSlices(t, halfValues(a.CumProd(0)), []float32{1, -2, -6, 24})
+	assertEqualSlices(t, halfValues(a.CumMax(0)), []float32{1, 1, 3, 3})
+	assertEqualSlices(t, halfValues(a.CumMin(0)), []float32{1, -2, -2, -4})
+	assertEqualSlices(t, a.CumArgMin(0).Data(), []int{0, 1, 1, 3})
+	lse := halfValues(tensor.Zeros[types.BFloat16](3).LogCumSumExp(0))
+	assert(t, math.Abs(float64(lse[2])-math.Log(3)) < 1e-2)
+
+	// writes into out
+	out := tensor.Zeros[types.BFloat16](4)
+	a.CumSum(0, out)
+	assertEqualSlices(t, halfValues(out), []float32{1, -1, 2, -2})
+
+	grad, err := tensor.CumSumGrad(tensor.Ones[types.BFloat16](3), 0)
+	assert(t, err == nil)
+	assertEqualSlices(t, halfValues(grad), []float32{3, 2, 1})
+	grad, err = tensor.CumProdGrad(a, tensor.Ones[types.BFloat16](4), 0)
+	assert(t, err == nil)
+	assertEqualSlices(t, halfValues(grad), []float32{17, -8, 6, -6})
+}
+
+func TestHalfStats(t *testing.T) {
+	a := halfTensor([]float32{1, 2, 3, 6}, 2, 2)
+	assertEqualSlices(t, halfValues(a.Var(0, false)), []float32{3.5})
+	assertEqualSlices(t, halfValues(a.Var(1, false, 0)), []float32{2, 8})
+	assertEqualSlices(t, halfValues(a.Std(0, false, 1)), []float32{0.5, 1.5})
+	assertEqualSlices(t, halfValues(halfTensor([]float32{-3, 1, -2}, 3).Median(false)), []float32{-2})
+
+	counts, edges := halfTensor([]float32{-1, 0, 0.5, 1}, 4).Histogram(2)
+	assertEqualSlices(t, counts.Data(), []int{1, 3})
+	assertEqualSlices(t, edges.Data(), []float64{-1, 0, 1})
+	assert(t, tensor.Ones[types.Float16](2).Bincount(0).Err != nil)
+
+	assertEqualSlices(t, halfValues(a.Cov(1)), []float32{0.5, 1.5, 1.5, 4.5})
+
+	has_nan, err := halfTensor([]float32{1, float32(math.NaN())}, 2).HasNaN()
+	assert(t, err == nil && has_nan)
+	has_nan, _ = halfTensor([]float32{1, 2}, 2).HasNaN()
+	assert(t, !has_nan)
+	close, err := halfTensor([]float32{1, 2}, 2).IsAllClose(halfTensor([]float32{1.001, 2}, 2), 1e-2)
+	assert(t, err == nil && close)
+}
+
+func TestHalfEinsum(t *testing.T) {
+	a := halfTensor([]float32{1, -2, 3, 4}, 2, 2)
+	b := halfTensor([]float32{0.5, -1}, 2)
+	res, err := tensor.Einsum("ij,j->i", a, b)
+	assert(t, err == nil)
+	assertEqualSlices(t, halfValues(res), []float32{2.5, -2.5})
+	trace, err := tensor.Einsum("ii", a)
+	assert(t, err == nil)
+	assertEqualSlices(t, trace.Shape(), types.Shape{})
+	assertEqualSlices(t, halfValues(trace), []float32{5})
+
+	grad, err := tensor.EinsumGrad("ij,j->i", tensor.Ones[types.Float16](2), []*tensor.Tensor[types.Float16]{a, b}, 1)
+	assert(t, err == nil)
+	assertEqualSlices(t, halfValues(grad), []float32{4, 2})
+	_, err = tensor.Einsum("ij,j->i", a, halfTensor([]float32{1, 2, 3}, 3))
+	assert(t, err != nil)
+}
+
+func TestHalfScatterAdd(t *testing.T) {
+	index := tensor.CreateTensor([]int{0, 1, 0}, types.Shape{3})
+	src := halfTensor([]float32{1.5, -2, 0.25}, 3)
+	sum := tensor.Zeros[types.Float16](2).ScatterAdd(0, index, src)
+	assertEqualSlices(t, halfValues(sum), []float32{1.75, -2})
+
+	// in-place update
+	a := halfTensor([]float32{1, 1}, 2)
+	a.IndexAdd(0, tensor.CreateTensor([]int{1, 1}, types.Shape{2}), halfTensor([]float32{-0.5, -0.5}, 2), a)
+	assertEqualSlices(t, halfValues(a), []float32{1, 0})
+}
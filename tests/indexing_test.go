@@ -104,37 +104,258 @@ func TestIndexMask(t *testing.T) {
 	// 0 1 2
 	// 3 4 5
 	// 6 7 8
-	mask := tensor.CreateTensor([]int32{1, 0, 2}, types.Shape{3})
-	masked := a.IndexMask(mask, false).MustAssert()
+	rows := tensor.CreateBoolTensor([]bool{true, false, true}, types.Shape{3})
+	masked := a.IndexMask(rows).MustAssert()
 	assertEqualSlices(t, a.Shape(), types.Shape{3, 3})
-	assertEqualSlices(t, masked.Data(), []int32{3, 4, 5, 0, 1, 2, 6, 7, 8})
-	assertEqualSlices(t, masked.Shape(), types.Shape{3, 3})
+	assertEqualSlices(t, masked.Data(), []int32{0, 1, 2, 6, 7, 8})
+	assertEqualSlices(t, masked.Shape(), types.Shape{2, 3})
 
-	mask1 := tensor.CreateTensor([]int32{
-		0, 2,
-		1, 0,
-		2, 1}, types.Shape{3, 2})
-	masked1 := a.IndexMask(mask1, false).MustAssert()
-	assertEqualSlices(t, masked1.Data(), []int32{2, 3, 7})
+	// the mask of all dims selects single elements
+	elements := a.IndexMask(a.Mod(tensor.Scalar[int32](4)).Eq(tensor.Scalar[int32](0))).MustAssert()
+	assertEqualSlices(t, elements.Data(), []int32{0, 4, 8})
+	assertEqualSlices(t, elements.Shape(), types.Shape{3})
+
+	// views are selected in the logical order
+	assertEqualSlices(t, a.T().IndexMask(rows).Data(), []int32{0, 3, 6, 2, 5, 8})
+	none := a.IndexMask(tensor.CreateBoolTensor([]bool{false, false, false}, types.Shape{3}))
+	assertEqualSlices(t, none.Shape(), types.Shape{0, 3})
+
+	// the mask must match the leading dims
+	assert(t, a.Clone().IndexMask(tensor.CreateBoolTensor([]bool{true, false}, types.Shape{2})).Err != nil)
 }
 
 func TestIndexMask2(t *testing.T) {
 	a := tensor.Range[int32](8).Reshape(2, 2, 2)
-	mask := tensor.CreateTensor([]int32{1, 0}, types.Shape{2})
-	masked := a.IndexMask(mask, false).MustAssert()
-	assertEqualSlices(t, masked.Data(), []int32{4, 5, 6, 7, 0, 1, 2, 3})
-	assertEqualSlices(t, masked.Shape(), types.Shape{2, 2, 2})
-
-	b := tensor.Range[int32](8).Reshape(2, 2, 2)
-	maskb := tensor.CreateTensor([]int32{1, 0, 1, 0}, types.Shape{2, 2})
-	maskedb := b.IndexMask(maskb, false).MustAssert()
-	assertEqualSlices(t, maskedb.Data(), []int32{4, 5, 4, 5})
-	assertEqualSlices(t, maskedb.Shape(), types.Shape{2, 2})
+	mask := tensor.CreateBoolTensor([]bool{false, true, true, false}, types.Shape{2, 2})
+	masked := a.IndexMask(mask).MustAssert()
+	assertEqualSlices(t, masked.Data(), []int32{2, 3, 4, 5})
+	assertEqualSlices(t, masked.Shape(), types.Shape{2, 2})
 }
 
 func TestIndexMask3(t *testing.T) {
 	a := tensor.Range[int32](8).Reshape(2, 4)
-	mask := tensor.CreateTensor([]int32{2, 1}, types.Shape{2})
-	a.SetByIndexMask(mask, true, 77)
-	assertEqualSlices(t, a.Data(), []int32{0, 1, 77, 3, 4, 77, 6, 7})
+	a.SetByIndexMask(tensor.CreateBoolTensor([]bool{false, true}, types.Shape{2}), 77)
+	assertEqualSlices(t, a.Data(), []int32{0, 1, 2, 3, 77, 77, 77, 77})
+	a.SetByIndexMask(a.Lt(tensor.Scalar[int32](2)), -1)
+	assertEqualSlices(t, a.Data(), []int32{-1, -1, 2, 3, 77, 77, 77, 77})
+
+	// the update is visible through the view's parent
+	b := tensor.Range[int32](4).Reshape(2, 2)
+	b.T().SetByIndexMask(tensor.CreateBoolTensor([]bool{true, false}, types.Shape{2}), 9)
+	assertEqualSlices(t, b.Data(), []int32{9, 1, 9, 3})
+
+	c := tensor.Range[int32](4)
+	c.SetByIndexMask(tensor.CreateBoolTensor([]bool{true}, types.Shape{1}), 0)
+	assert(t, c.Err != nil)
+}
+
+func TestIndexView(t *testing.T) {
+	a := tensor.Range[int32](12).Reshape(3, 4)
+	row := a.Index(1).MustAssert()
+	assert(t, row.IsContiguous())
+	assertEqualSlices(t, row.Data(), []int32{4, 5, 6, 7})
+	// writes through the view are visible in the parent
+	row.Set([]int{2}, 77)
+	assertEqualSlices(t, a.Data(), []int32{0, 1, 2, 3, 4, 5, 77, 7, 8, 9, 10, 11})
+	row.Fill(-1)
+	assertEqualSlices(t, a.Data(), []int32{0, 1, 2, 3, -1, -1, -1, -1, 8, 9, 10, 11})
+	// and vice versa
+	a.Set([]int{1, 0}, 5)
+	assertEqualSlices(t, row.Data(), []int32{5, -1, -1, -1})
+}
+
+func TestIndexAdvView(t *testing.T) {
+	a := tensor.Range[int32](12).Reshape(3, 4)
+	col := a.IndexAdv(":,1").MustAssert()
+	assert(t, !col.IsContiguous())
+	assertEqualSlices(t, col.Shape(), types.Shape{3})
+	assertEqualSlices(t, col.Data(), []int32{1, 5, 9})
+	col.Fill(0)
+	assertEqualSlices(t, a.Data(), []int32{0, 0, 2, 3, 4, 0, 6, 7, 8, 0, 10, 11})
+
+	// view of the view
+	b := tensor.Range[int32](24).Reshape(2, 3, 4)
+	sub := b.IndexAdv(":,2").IndexAdv(":,3").MustAssert()
+	assertEqualSlices(t, sub.Data(), []int32{11, 23})
+	sub.Set([]int{1}, 100)
+	v, err := b.Get(1, 2, 3)
+	assert(t, err == nil)
+	assert(t, v == 100)
+}
+
+func TestTransposeView(t *testing.T) {
+	a := tensor.Range[int32](6).Reshape(2, 3)
+	at := a.T()
+	assertEqualSlices(t, at.Shape(), types.Shape{3, 2})
+	assertEqualSlices(t, at.Data(), []int32{0, 3, 1, 4, 2, 5})
+	at.Set([]int{2, 1}, 50)
+	assertEqualSlices(t, a.Data(), []int32{0, 1, 2, 3, 4, 50})
+}
+
+func TestViewAndClone(t *testing.T) {
+	a := tensor.Range[int32](6).Reshape(2, 3)
+	view := a.View()
+	clone := a.Clone()
+	a.Fill(1)
+	assertEqualSlices(t, view.Data(), []int32{1, 1, 1, 1, 1, 1})
+	assertEqualSlices(t, clone.Data(), []int32{0, 1, 2, 3, 4, 5})
+
+	// clone of a non-contiguous view is contiguous and independent
+	col := a.IndexAdv(":,2").Clone()
+	assert(t, col.IsContiguous())
+	col.Fill(9)
+	assertEqualSlices(t, a.Data(), []int32{1, 1, 1, 1, 1, 1})
+}
+
+func TestViewAsOutput(t *testing.T) {
+	a := tensor.Range[float32](6).Reshape(2, 3)
+	col := a.IndexAdv(":,0")
+	ones := tensor.Ones[float32](2)
+	col.Add(ones, col).MustAssert()
+	assertEqualSlices(t, a.Data(), []float32{1, 1, 2, 4, 4, 5})
+	col.Neg(col).MustAssert()
+	assertEqualSlices(t, a.Data(), []float32{-1, 1, 2, -4, 4, 5})
+}
+
+func TestViewOps(t *testing.T) {
+	a := tensor.Range[float32](12).Reshape(3, 4)
+	col := a.IndexAdv(":,1")
+	assertEqualSlices(t, col.Sum(false).Data(), []float32{15})
+	assertEqualSlices(t, col.Max(false).Data(), []float32{9})
+	assertEqualSlices(t, col.Mul(col).Data(), []float32{1, 25, 81})
+	assertEqualSlices(t, col.Exp().Shape(), types.Shape{3})
+	// reshape of a non-contiguous view materializes it
+	b := tensor.Range[int32](12).Reshape(3, 4).T().Reshape(2, 6).MustAssert()
+	assertEqualSlices(t, b.Data(), []int32{0, 4, 8, 1, 5, 9, 2, 6, 10, 3, 7, 11})
+	// reshape of a contiguous view keeps sharing memory
+	c := tensor.Range[int32](12).Reshape(3, 4)
+	c.Index(2).Reshape(2, 2).Fill(0)
+	assertEqualSlices(t, c.Data(), []int32{0, 1, 2, 3, 4, 5, 6, 7, 0, 0, 0, 0})
+}
+
+func TestIndexAdvSlices(t *testing.T) {
+	a := tensor.Range[int32](24).Reshape(4, 6)
+	sub := a.IndexAdv("1:3, ::2").MustAssert()
+	assertEqualSlices(t, sub.Shape(), types.Shape{2, 3})
+	assertEqualSlices(t, sub.Data(), []int32{6, 8, 10, 12, 14, 16})
+
+	sub = a.IndexAdv("-1, -3:").MustAssert()
+	assertEqualSlices(t, sub.Shape(), types.Shape{3})
+	assertEqualSlices(t, sub.Data(), []int32{21, 22, 23})
+
+	sub = a.IndexAdv("1:4:2, 0").MustAssert()
+	assertEqualSlices(t, sub.Data(), []int32{6, 18})
+
+	// the same with builders
+	sub = a.IndexAdv_(tensor.ISlc(1, 3), tensor.Axis().Step(2)).MustAssert()
+	assertEqualSlices(t, sub.Data(), []int32{6, 8, 10, 12, 14, 16})
+	sub = a.IndexAdv_(tensor.I(-1), tensor.IFrom(-3)).MustAssert()
+	assertEqualSlices(t, sub.Data(), []int32{21, 22, 23})
+	sub = a.IndexAdv_(tensor.ITo(2), tensor.I(1)).MustAssert()
+	assertEqualSlices(t, sub.Data(), []int32{1, 7})
+}
+
+func TestIndexAdvNegativeStep(t *testing.T) {
+	a := tensor.Range[int32](12).Reshape(3, 4)
+	rev := a.IndexAdv("::-1").MustAssert()
+	assertEqualSlices(t, rev.Data(), []int32{8, 9, 10, 11, 4, 5, 6, 7, 0, 1, 2, 3})
+
+	sub := a.IndexAdv("..., ::-2").MustAssert()
+	assertEqualSlices(t, sub.Shape(), types.Shape{3, 2})
+	assertEqualSlices(t, sub.Data(), []int32{3, 1, 7, 5, 11, 9})
+
+	sub = a.IndexAdv("0, 2:0:-1").MustAssert()
+	assertEqualSlices(t, sub.Data(), []int32{2, 1})
+	sub = a.IndexAdv_(tensor.I(0), tensor.ISlc(2, 0).Step(-1)).MustAssert()
+	assertEqualSlices(t, sub.Data(), []int32{2, 1})
+
+	// reversed view shares memory with the source
+	rev.Set([]int{0, 0}, 100)
+	v, _ := a.Get(2, 0)
+	assert(t, v == 100)
+	assertEqualSlices(t, rev.Clone().Data(), rev.Data())
+	assertEqualSlices(t, rev.Sum(false).Data(), []int32{158})
+}
+
+func TestIndexAdvEllipsisNewAxis(t *testing.T) {
+	a := tensor.Range[int32](24).Reshape(2, 3, 4)
+	sub := a.IndexAdv("..., 1").MustAssert()
+	assertEqualSlices(t, sub.Shape(), types.Shape{2, 3})
+	assertEqualSlices(t, sub.Data(), []int32{1, 5, 9, 13, 17, 21})
+
+	sub = a.IndexAdv("1, ..., 0").MustAssert()
+	assertEqualSlices(t, sub.Data(), []int32{12, 16, 20})
+
+	sub = a.IndexAdv("None, 0, ..., None").MustAssert()
+	assertEqualSlices(t, sub.Shape(), types.Shape{1, 3, 4, 1})
+	sub = a.IndexAdv_(tensor.I(0), tensor.NewAxis(), tensor.Ellipsis()).MustAssert()
+	assertEqualSlices(t, sub.Shape(), types.Shape{1, 3, 4})
+	assertEqualSlices(t, sub.Data(), tensor.Range[int32](12).Data())
+
+	sub = a.IndexAdv("1, 2, 3, None").MustAssert()
+	assertEqualSlices(t, sub.Shape(), types.Shape{1})
+	assertEqualSlices(t, sub.Data(), []int32{23})
+}
+
+func TestIndexAdvErrors(t *testing.T) {
+	exprs := []string{
+		"0:5",      // out of range
+		"-4:",      // out of range
+		"::0",      // zero step
+		"1:2:3:4",  // bad slice
+		"..., ...", // two ellipses
+		"0, 0, 0",  // too many indices
+		"a:1",
+	}
+	for _, expr := range exprs {
+		a := tensor.Range[int32](12).Reshape(3, 4)
+		if a.IndexAdv(expr).Err == nil {
+			t.Errorf("expected an error for '%v'", expr)
+		}
+	}
+}
+
+func TestSetIndexAdv(t *testing.T) {
+	a := tensor.Range[int32](6).Reshape(2, 3)
+	a.SetIndexAdv(":, 1:", tensor.Scalar[int32](0)).MustAssert()
+	assertEqualSlices(t, a.Data(), []int32{0, 0, 0, 3, 0, 0})
+
+	// row is broadcasted to every selected row
+	b := tensor.Zeros[int32](3, 4)
+	b.SetIndexAdv("::2", tensor.Range[int32](4)).MustAssert()
+	assertEqualSlices(t, b.Data(), []int32{0, 1, 2, 3, 0, 0, 0, 0, 0, 1, 2, 3})
+
+	// column is broadcasted along the last axis
+	c := tensor.Zeros[int32](3, 4)
+	col := tensor.CreateTensor([]int32{1, 2}, types.Shape{2, 1})
+	c.SetIndexAdv_(col, tensor.ISlc(1, 3), tensor.ITo(-1).Step(2)).MustAssert()
+	assertEqualSlices(t, c.Data(), []int32{0, 0, 0, 0, 1, 0, 1, 0, 2, 0, 2, 0})
+
+	// single element and leading ones in src
+	c.SetIndexAdv("0, -1", tensor.Scalar[int32](7)).MustAssert()
+	c.SetIndexAdv("0, :", tensor.CreateTensor([]int32{5, 5, 5, 5}, types.Shape{1, 1, 4})).MustAssert()
+	assertEqualSlices(t, c.Index(0).Data(), []int32{5, 5, 5, 5})
+}
+
+func TestSetIndexAdvNonContiguous(t *testing.T) {
+	a := tensor.Range[int32](12).Reshape(3, 4)
+	at := a.T() // (4,3) view
+	at.SetIndexAdv("1:3, ::-1", tensor.CreateTensor([]int32{10, 20, 30}, types.Shape{3})).MustAssert()
+	assertEqualSlices(t, a.Data(), []int32{0, 30, 30, 3, 4, 20, 20, 7, 8, 10, 10, 11})
+
+	// reversing a tensor in place through the overlapping view
+	b := tensor.Range[int32](5)
+	b.SetIndexAdv(":", b.IndexAdv("::-1")).MustAssert()
+	assertEqualSlices(t, b.Data(), []int32{4, 3, 2, 1, 0})
+	b.SetIndexAdv("1:", b.IndexAdv(":-1")).MustAssert()
+	assertEqualSlices(t, b.Data(), []int32{4, 4, 3, 2, 1})
+}
+
+func TestSetIndexAdvErrors(t *testing.T) {
+	a := tensor.Range[int32](6).Reshape(2, 3)
+	assert(t, a.SetIndexAdv("0", tensor.Range[int32](2)).Err != nil)
+	b := tensor.Range[int32](6).Reshape(2, 3)
+	assert(t, b.SetIndexAdv("0:1", tensor.Range[int32](6).Reshape(2, 3)).Err != nil)
+	c := tensor.Range[int32](6).Reshape(2, 3)
+	assert(t, c.SetIndexAdv("5", tensor.Scalar[int32](1)).Err != nil)
 }
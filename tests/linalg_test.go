@@ -0,0 +1,329 @@
+package main
+
+import (
+	"gograd/tensor"
+	"gograd/tensor/linalg"
+	types "gograd/tensor/types"
+	"math"
+	"testing"
+)
+
+func assertAllClose64(t *testing.T, a, b *tensor.Tensor[float64]) {
+	t.Helper()
+	a.MustAssert()
+	b.MustAssert()
+	is_close, err := a.IsAllClose(b, 1e-9)
+	if err != nil || !is_close {
+		t.Errorf("Tensors must be close. Got %v and %v", a.Data(), b.Data())
+	}
+}
+
+func TestLU(t *testing.T) {
+	rng := tensor.NewRNG(1)
+	for _, shape := range []types.Shape{{5, 5}, {3, 4, 4}} {
+		a := rng.RandomFloat64(shape...)
+		p, l, u := linalg.LU(a)
+		assertAllClose64(t, p.MatMul(l).MatMul(u), a)
+		n := int(shape[len(shape)-1])
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				l_ij := l.IndexAdv_(tensor.Ellipsis(), tensor.I(i), tensor.I(j)).Data()
+				u_ij := u.IndexAdv_(tensor.Ellipsis(), tensor.I(i), tensor.I(j)).Data()
+				for k := range l_ij {
+					if i == j && l_ij[k] != 1 || j > i && l_ij[k] != 0 || j < i && u_ij[k] != 0 {
+						t.Fatalf("L or U is not triangular at (%v,%v)", i, j)
+					}
+				}
+			}
+		}
+	}
+
+	a := tensor.CreateTensor([]float64{1, 2, 3, 4}, types.Shape{2, 2})
+	p, l, u := linalg.LU(a)
+	assertEqualSlices(t, p.Data(), []float64{0, 1, 1, 0})
+	assertAllClose64(t, l, tensor.CreateTensor([]float64{1, 0, 1. / 3, 1}, types.Shape{2, 2}))
+	assertAllClose64(t, u, tensor.CreateTensor([]float64{3, 4, 0, 2. / 3}, types.Shape{2, 2}))
+}
+
+func TestQR(t *testing.T) {
+	rng := tensor.NewRNG(2)
+	for _, shape := range []types.Shape{{6, 4}, {4, 6}, {2, 5, 3}} {
+		a := rng.RandomFloat64(shape...)
+		q, r := linalg.QR(a)
+		k := min(shape[len(shape)-2], shape[len(shape)-1])
+		assertEqualSlices(t, q.Shape()[len(shape)-2:], types.Shape{shape[len(shape)-2], k})
+		assertEqualSlices(t, r.Shape()[len(shape)-2:], types.Shape{k, shape[len(shape)-1]})
+		assertAllClose64(t, q.MatMul(r), a)
+
+		// orthonormal columns
+		qtq := q.T(batchAxes(len(shape))...).MatMul(q)
+		assertAllClose64(t, qtq, tensor.Eye[float64](k, k).Broadcast(qtq.Shape()...))
+		for i := 0; i < int(k); i++ {
+			for j := 0; j < i; j++ {
+				for _, v := range r.IndexAdv_(tensor.Ellipsis(), tensor.I(i), tensor.I(j)).Data() {
+					assert(t, v == 0)
+				}
+			}
+		}
+	}
+}
+
+// axes which swap the last two dims
+func batchAxes(n int) []uint {
+	axes := make([]uint, n)
+	for i := range axes {
+		axes[i] = uint(i)
+	}
+	axes[n-1], axes[n-2] = axes[n-2], axes[n-1]
+	return axes
+}
+
+func TestCholesky(t *testing.T) {
+	rng := tensor.NewRNG(3)
+	m := rng.RandomFloat64(2, 4, 4)
+	// M @ M.T + n*I is positive definite
+	a := m.MatMul(m.T(0, 2, 1)).Add(tensor.Eye[float64](4, 4).Mul(tensor.Scalar[float64](4)))
+	l := linalg.Cholesky(a)
+	assertAllClose64(t, l.MatMul(l.T(0, 2, 1)), a)
+	for _, v := range l.IndexAdv(":, 0, 1:").Data() {
+		assert(t, v == 0)
+	}
+
+	not_pd := tensor.CreateTensor([]float64{1, 2, 2, 1}, types.Shape{2, 2})
+	assert(t, linalg.Cholesky(not_pd).Err != nil)
+	assert(t, linalg.Cholesky(tensor.Ones[float64](2, 3)).Err != nil)
+}
+
+func TestSolve(t *testing.T) {
+	a := tensor.CreateTensor([]float64{3, 1, 1, 2}, types.Shape{2, 2})
+	x := linalg.Solve(a, tensor.CreateTensor([]float64{9, 8}, types.Shape{2}))
+	assertEqualSlices(t, x.Shape(), types.Shape{2})
+	assertAllClose64(t, x, tensor.CreateTensor([]float64{2, 3}, types.Shape{2}))
+
+	rng := tensor.NewRNG(4)
+	// batch of matrices with shared rhs matrix
+	batch := rng.RandomFloat64(3, 5, 5)
+	b := rng.RandomFloat64(5, 2)
+	x = linalg.Solve(batch, b)
+	assertEqualSlices(t, x.Shape(), types.Shape{3, 5, 2})
+	assertAllClose64(t, batch.MatMul(x), b.Broadcast(3, 5, 2))
+
+	// shared vector
+	vec := rng.RandomFloat64(5)
+	x = linalg.Solve(batch, vec)
+	assertEqualSlices(t, x.Shape(), types.Shape{3, 5})
+	assertAllClose64(t, batch.MatMul(x.View().Unsqueeze(-1)).Reshape(3, 5), vec.Broadcast(3, 5))
+
+	// batch of vectors as (B,N,1) matrices, single matrix is broadcasted
+	vecs := rng.RandomFloat64(3, 5, 1)
+	x = linalg.Solve(batch.Index(0), vecs)
+	assertEqualSlices(t, x.Shape(), types.Shape{3, 5, 1})
+	assertAllClose64(t, batch.Index(0).MatMul(x), vecs)
+
+	// float32
+	a32 := tensor.CreateTensor([]float32{4, 1, 2, 3}, types.Shape{2, 2})
+	x32 := linalg.Solve(a32, tensor.CreateTensor([]float32{1, 2}, types.Shape{2, 1}))
+	assertAllClose(t, a32.MatMul(x32), tensor.CreateTensor([]float32{1, 2}, types.Shape{2, 1}))
+
+	singular := tensor.CreateTensor([]float64{1, 2, 2, 4}, types.Shape{2, 2})
+	assert(t, linalg.Solve(singular, tensor.Ones[float64](2)).Err != nil)
+	assert(t, linalg.Solve(a, tensor.Ones[float64](3)).Err != nil)
+	assert(t, linalg.Solve(rng.RandomFloat64(2, 2, 2), tensor.Ones[float64](3, 2, 1)).Err != nil)
+}
+
+func TestSolveTriangular(t *testing.T) {
+	lower := tensor.CreateTensor([]float64{2, 0, 0, 1, 3, 0, 4, 5, 6}, types.Shape{3, 3})
+	b := tensor.CreateTensor([]float64{2, 7, 32}, types.Shape{3})
+	x := linalg.SolveTriangular(lower, b, true)
+	assertAllClose64(t, x, tensor.CreateTensor([]float64{1, 2, 3}, types.Shape{3}))
+
+	upper := lower.T()
+	x = linalg.SolveTriangular(upper, b, false)
+	assertAllClose64(t, upper.MatMul(x), b)
+
+	zero_diag := tensor.CreateTensor([]float64{1, 0, 1, 0}, types.Shape{2, 2})
+	assert(t, linalg.SolveTriangular(zero_diag, tensor.Ones[float64](2), true).Err != nil)
+}
+
+func TestInvDet(t *testing.T) {
+	a := tensor.CreateTensor([]float64{1, 2, 3, 4}, types.Shape{2, 2})
+	assertAllClose64(t, linalg.Inv(a), tensor.CreateTensor([]float64{-2, 1, 1.5, -0.5}, types.Shape{2, 2}))
+	det := linalg.Det(a)
+	assertEqualSlices(t, det.Shape(), types.Shape{})
+	assert(t, math.Abs(det.Item()+2) < 1e-12)
+
+	rng := tensor.NewRNG(5)
+	batch := rng.RandomFloat64(4, 6, 6)
+	inv := linalg.Inv(batch)
+	assertAllClose64(t, batch.MatMul(inv), tensor.Eye[float64](6, 6).Broadcast(4, 6, 6))
+
+	dets := linalg.Det(batch)
+	assertEqualSlices(t, dets.Shape(), types.Shape{4})
+	sign, logdet := linalg.SlogDet(batch)
+	for i, d := range dets.Data() {
+		assert(t, math.Abs(sign.Data()[i]*math.Exp(logdet.Data()[i])-d) < 1e-9)
+		// det(A^-1) = 1/det(A)
+		assert(t, math.Abs(linalg.Det(inv.Index(i)).Item()*d-1) < 1e-9)
+	}
+
+	singular := tensor.CreateTensor([]float64{1, 2, 2, 4}, types.Shape{2, 2})
+	assert(t, linalg.Inv(singular).Err != nil)
+	assert(t, linalg.Det(singular).Item() == 0)
+	sign, logdet = linalg.SlogDet(singular)
+	assert(t, sign.Item() == 0 && math.IsInf(logdet.Item(), -1))
+	assertEqualSlices(t, sign.Shape(), types.Shape{})
+	assertEqualSlices(t, logdet.Shape(), types.Shape{})
+
+	// large matrix, determinant overflows, but its log doesn't
+	big := tensor.Eye[float64](400, 400).Mul(tensor.Scalar[float64](10))
+	assert(t, math.IsInf(linalg.Det(big).Item(), 1))
+	_, logdet = linalg.SlogDet(big)
+	assert(t, math.Abs(logdet.Item()-400*math.Log(10)) < 1e-9)
+
+	assert(t, linalg.Inv(tensor.Ones[float64](2, 3)).Err != nil)
+	assert(t, linalg.Det(tensor.Ones[float64](3)).Err != nil)
+}
+
+func assertOrthonormalCols(t *testing.T, q *tensor.Tensor[float64]) {
+	t.Helper()
+	shape := q.Shape()
+	cols := shape[len(shape)-1]
+	qtq := q.T(batchAxes(len(shape))...).MatMul(q)
+	assertAllClose64(t, qtq, tensor.Eye[float64](cols, cols).Broadcast(qtq.Shape()...))
+}
+
+func TestEighSymmetric(t *testing.T) {
+	a := tensor.CreateTensor([]float64{2, 1, 1, 2}, types.Shape{2, 2})
+	w, v := linalg.EighSymmetric(a)
+	assertAllClose64(t, w, tensor.CreateTensor([]float64{1, 3}, types.Shape{2}))
+	assertAllClose64(t, a.MatMul(v), v.Mul(w))
+
+	rng := tensor.NewRNG(6)
+	m := rng.RandomFloat64(3, 6, 6)
+	sym := m.Add(m.T(0, 2, 1))
+	w, v = linalg.EighSymmetric(sym)
+	assertEqualSlices(t, w.Shape(), types.Shape{3, 6})
+	assertOrthonormalCols(t, v)
+	// A @ v = v @ diag(w)
+	assertAllClose64(t, sym.MatMul(v), v.Mul(w.View().Unsqueeze(1)))
+	for i := 0; i < 3; i++ {
+		vals := w.Index(i).Data()
+		for j := 1; j < len(vals); j++ {
+			assert(t, vals[j-1] <= vals[j])
+		}
+	}
+
+	// only the lower triangle is used
+	lower := tensor.CreateTensor([]float64{2, 100, 1, 2}, types.Shape{2, 2})
+	w, _ = linalg.EighSymmetric(lower)
+	assertAllClose64(t, w, tensor.CreateTensor([]float64{1, 3}, types.Shape{2}))
+
+	w32, _ := linalg.EighSymmetric(tensor.CreateTensor([]float32{4, 0, 0, 1}, types.Shape{2, 2}))
+	assertAllClose(t, w32, tensor.CreateTensor([]float32{1, 4}, types.Shape{2}))
+
+	assert(t, func() bool { w, _ := linalg.EighSymmetric(tensor.Ones[float64](2, 3)); return w.Err != nil }())
+}
+
+func TestSVD(t *testing.T) {
+	rng := tensor.NewRNG(7)
+	// outer product has rank 1, so it checks the basis completion
+	outer := rng.RandomFloat64(5, 1).MatMul(rng.RandomFloat64(1, 3))
+	for _, a := range []*tensor.Tensor[float64]{
+		rng.RandomFloat64(6, 4), rng.RandomFloat64(4, 6), rng.RandomFloat64(2, 5, 3), outer, outer.T().Clone(),
+	} {
+		shape := a.Shape()
+		m, n := shape[len(shape)-2], shape[len(shape)-1]
+		k := min(m, n)
+		for _, full := range []bool{false, true} {
+			u, s, vt := linalg.SVD(a, full)
+			u_cols, vt_rows := k, k
+			if full {
+				u_cols, vt_rows = m, n
+			}
+			assertEqualSlices(t, u.Shape()[len(shape)-2:], types.Shape{m, u_cols})
+			assertEqualSlices(t, s.Shape()[len(shape)-2:], types.Shape{k})
+			assertEqualSlices(t, vt.Shape()[len(shape)-2:], types.Shape{vt_rows, n})
+			assertOrthonormalCols(t, u)
+			assertOrthonormalCols(t, vt.T(batchAxes(len(shape))...))
+
+			u_k := u.IndexAdv_(tensor.Ellipsis(), tensor.ITo(int(k)))
+			vt_k := vt.IndexAdv_(tensor.Ellipsis(), tensor.ITo(int(k)), tensor.Axis())
+			assertAllClose64(t, u_k.Mul(s.View().Unsqueeze(-2)).MatMul(vt_k), a)
+			vals := s.Data()
+			for j := 1; j < len(vals); j++ {
+				// descending within every matrix
+				assert(t, j%int(k) == 0 || vals[j-1] >= vals[j])
+			}
+		}
+	}
+	s := linalg.SingularValues(outer)
+	assert(t, s.Data()[0] > 0 && math.Abs(s.Data()[1]) < 1e-12 && math.Abs(s.Data()[2]) < 1e-12)
+}
+
+func TestPinvMatrixRank(t *testing.T) {
+	rng := tensor.NewRNG(8)
+	a := rng.RandomFloat64(4, 4)
+	assertAllClose64(t, linalg.Pinv(a, 0), linalg.Inv(a))
+
+	// A @ pinv(A) @ A = A for rank deficient and non-square matrices
+	outer := rng.RandomFloat64(5, 1).MatMul(rng.RandomFloat64(1, 3))
+	for _, m := range []*tensor.Tensor[float64]{outer, rng.RandomFloat64(2, 3, 5)} {
+		pinv := linalg.Pinv(m, 0)
+		assertAllClose64(t, m.MatMul(pinv).MatMul(m), m)
+	}
+	assertEqualSlices(t, linalg.Pinv(rng.RandomFloat64(2, 3, 5), 0).Shape(), types.Shape{2, 5, 3})
+
+	assertEqualSlices(t, linalg.MatrixRank(outer, 0).Data(), []int{1})
+	assertEqualSlices(t, linalg.MatrixRank(outer, 0).Shape(), types.Shape{})
+	assertEqualSlices(t, linalg.MatrixRank(rng.RandomFloat64(3, 4, 6), 0).Data(), []int{4, 4, 4})
+
+	// tolerances
+	ill := tensor.CreateTensor([]float64{1, 0, 0, 1e-10}, types.Shape{2, 2})
+	assertEqualSlices(t, linalg.MatrixRank(ill, 0).Data(), []int{2})
+	assertEqualSlices(t, linalg.MatrixRank(ill, 1e-5).Data(), []int{1})
+	assertAllClose64(t, linalg.Pinv(ill, 1e-5), tensor.CreateTensor([]float64{1, 0, 0, 0}, types.Shape{2, 2}))
+	// float32 default tolerance is larger
+	ill32 := tensor.CreateTensor([]float32{1, 0, 0, 1e-10}, types.Shape{2, 2})
+	assertEqualSlices(t, linalg.MatrixRank(ill32, 0).Data(), []int{1})
+
+	assert(t, linalg.MatrixRank(ill, -1).Err != nil)
+	assert(t, linalg.Pinv(tensor.Ones[float64](3), 0).Err != nil)
+}
+
+func TestNorms(t *testing.T) {
+	a := tensor.CreateTensor([]float64{3, -4, 0, 1}, types.Shape{2, 2})
+	assertAllClose64(t, linalg.VectorNorm(a, 2, false), tensor.Scalar(math.Sqrt(26)))
+	assertAllClose64(t, linalg.VectorNorm(a, 1, false, -1), tensor.CreateTensor([]float64{7, 1}, types.Shape{2}))
+	assertAllClose64(t, linalg.VectorNorm(a, math.Inf(1), true, 0), tensor.CreateTensor([]float64{3, 4}, types.Shape{1, 2}))
+	assertAllClose64(t, linalg.VectorNorm(a, math.Inf(-1), false), tensor.Scalar[float64](0))
+	assertAllClose64(t, linalg.VectorNorm(a, 0, false), tensor.Scalar[float64](3))
+	assertAllClose64(t, linalg.VectorNorm(a, 3, false, 1), tensor.CreateTensor([]float64{math.Cbrt(91), 1}, types.Shape{2}))
+	assert(t, linalg.VectorNorm(a, 2, false, 2).Err != nil)
+
+	m := tensor.CreateTensor([]float64{1, -2, 3, 4}, types.Shape{2, 2})
+	assertAllClose64(t, linalg.MatrixNorm(m, linalg.Frobenius, false), tensor.Scalar(math.Sqrt(30)))
+	assertAllClose64(t, linalg.MatrixNorm(m, linalg.MatrixL1, false), tensor.Scalar[float64](6))
+	assertAllClose64(t, linalg.MatrixNorm(m, linalg.MatrixInf, false), tensor.Scalar[float64](7))
+	assertAllClose64(t, linalg.MatrixNorm(m, linalg.MatrixL2, false), tensor.Scalar(math.Sqrt(15+math.Sqrt(125))))
+	// nuclear^2 = s1^2 + s2^2 + 2*|det|
+	assertAllClose64(t, linalg.MatrixNorm(m, linalg.Nuclear, false), tensor.Scalar(math.Sqrt(50)))
+	for _, ord := range []linalg.MatrixOrd{linalg.Frobenius, linalg.MatrixL2, linalg.Nuclear} {
+		assertEqualSlices(t, linalg.MatrixNorm(m, ord, false).Shape(), types.Shape{})
+	}
+	assertEqualSlices(t, linalg.MatrixNorm(m, linalg.MatrixL1, true).Shape(), types.Shape{1, 1})
+
+	batch := tensor.Range[float64](12).Reshape(3, 2, 2)
+	norms := linalg.MatrixNorm(batch, linalg.MatrixInf, true)
+	assertEqualSlices(t, norms.Shape(), types.Shape{3, 1, 1})
+	assertEqualSlices(t, norms.Data(), []float64{5, 13, 21})
+	assertEqualSlices(t, linalg.MatrixNorm(batch, linalg.Frobenius, false).Shape(), types.Shape{3})
+
+	norm32 := linalg.MatrixNorm(tensor.CreateTensor([]float32{1, -2, 3, 4}, types.Shape{2, 2}), linalg.Frobenius, false)
+	assertAllClose(t, norm32, tensor.Scalar(float32(math.Sqrt(30))))
+
+	cond := linalg.Cond(tensor.CreateTensor([]float64{2, 0, 0, 0.5}, types.Shape{2, 2}))
+	assertAllClose64(t, cond, tensor.Scalar[float64](4))
+	assertEqualSlices(t, cond.Shape(), types.Shape{})
+	assert(t, math.IsInf(linalg.Cond(tensor.CreateTensor([]float64{1, 2, 2, 4}, types.Shape{2, 2})).Item(), 1))
+	assert(t, linalg.MatrixNorm(tensor.Ones[float64](3), linalg.Frobenius, false).Err != nil)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"testing"
+)
+
+func TestCompareOps(t *testing.T) {
+	a := tensor.Range[float32](6).Reshape(2, 3)
+	b := tensor.CreateTensor([]float32{0, 2, 1}, types.Shape{3})
+	assertEqualMasks(t, a.Eq(b), []bool{true, false, false, false, false, false})
+	assertEqualMasks(t, a.Ne(b), []bool{false, true, true, true, true, true})
+	assertEqualMasks(t, a.Lt(b), []bool{false, true, false, false, false, false})
+	assertEqualMasks(t, a.Le(b), []bool{true, true, false, false, false, false})
+	assertEqualMasks(t, a.Gt(b), []bool{false, false, true, true, true, true})
+	assertEqualMasks(t, a.Ge(b), []bool{true, false, true, true, true, true})
+	assertEqualSlices(t, a.Ge(b).Shape(), types.Shape{2, 3})
+
+	// scalars on both sides keep the order of operands
+	two := tensor.Scalar[int32](2)
+	c := tensor.Range[int32](4)
+	assertEqualMasks(t, c.Lt(two), []bool{true, true, false, false})
+	assertEqualMasks(t, two.Lt(c), []bool{false, false, false, true})
+
+	// (3,1) & (1,3) => (3,3)
+	col := tensor.Range[int32](3).Reshape(3, 1)
+	row := tensor.Range[int32](3).Reshape(1, 3)
+	mask := col.Ge(row)
+	assertEqualSlices(t, mask.Shape(), types.Shape{3, 3})
+	assertEqualMasks(t, mask, []bool{true, false, false, true, true, false, true, true, true})
+
+	// views are compared in the logical order
+	assertEqualMasks(t, a.T().Gt(tensor.Scalar[float32](2)), []bool{false, true, false, true, false, true})
+
+	// 16-bit floats are compared by value
+	h := tensor.AsType[float32, types.Float16](tensor.CreateTensor([]float32{-1, 0, 2}, types.Shape{3}))
+	assertEqualMasks(t, h.Lt(tensor.Zeros[types.Float16](3)), []bool{true, false, false})
+
+	assert(t, a.Eq(tensor.Range[float32](4)).Err != nil)
+}
+
+func TestLogical(t *testing.T) {
+	x := tensor.Range[float32](6)
+	in_range := x.Ge(tensor.Scalar[float32](1)).And(x.Lt(tensor.Scalar[float32](4)))
+	assertEqualMasks(t, in_range, []bool{false, true, true, true, false, false})
+	outside := in_range.Not().Or(x.Eq(tensor.Scalar[float32](2)))
+	assertEqualMasks(t, outside, []bool{true, false, true, false, true, true})
+	assertEqualMasks(t, in_range.Xor(outside), []bool{true, true, false, true, true, true})
+
+	// masks stored as numbers are converted explicitly
+	a := tensor.CreateTensor([]int32{0, 1, 0, 5}, types.Shape{4})
+	assertEqualMasks(t, tensor.AsBool(a).Not(), []bool{true, false, true, false})
+}
+
+func TestWhere(t *testing.T) {
+	cond := tensor.CreateBoolTensor([]bool{true, false, true}, types.Shape{3})
+	a := tensor.Range[float32](6).Reshape(2, 3)
+	out, err := tensor.Where(cond, a, tensor.Scalar[float32](-1))
+	assert(t, err == nil)
+	assertEqualSlices(t, out.Shape(), types.Shape{2, 3})
+	assertEqualSlices(t, out.Data(), []float32{0, -1, 2, 3, -1, 5})
+
+	// relu without Go loops
+	x := tensor.CreateTensor([]float32{-1, 2, -3}, types.Shape{3})
+	zero := tensor.Scalar[float32](0)
+	relu, err := tensor.Where(x.Gt(zero), x, zero)
+	assert(t, err == nil)
+	assertEqualSlices(t, relu.Data(), []float32{0, 2, 0})
+
+	// all three operands are broadcasted
+	col := tensor.CreateBoolTensor([]bool{true, false}, types.Shape{2, 1})
+	out_int, err := tensor.Where(col, tensor.Range[int32](3), tensor.Scalar[int32](9))
+	assert(t, err == nil)
+	assertEqualSlices(t, out_int.Data(), []int32{0, 1, 2, 9, 9, 9})
+
+	_, err = tensor.Where(cond, a, tensor.Range[float32](2))
+	assert(t, err != nil)
+	_, err = tensor.Where(tensor.CreateBoolTensor([]bool{true, false}, types.Shape{2}), x, zero)
+	assert(t, err != nil)
+}
+
+func TestMaskedFillSelect(t *testing.T) {
+	a := tensor.Range[float32](1, 5).Reshape(2, 2)
+	filled := a.MaskedFill(a.Gt(tensor.Scalar[float32](2)), 0).MustAssert()
+	assertEqualSlices(t, filled.Data(), []float32{1, 2, 0, 0})
+	// the original tensor is not changed
+	assertEqualSlices(t, a.Data(), []float32{1, 2, 3, 4})
+
+	// broadcasted mask and in-place fill
+	row_mask := tensor.CreateBoolTensor([]bool{false, true}, types.Shape{2})
+	a.MaskedFill(row_mask, -1, a)
+	assertEqualSlices(t, a.Data(), []float32{1, -1, 3, -1})
+	// writes into a transposed view
+	at := a.T()
+	a.T().MaskedFill(row_mask, -2, at)
+	assertEqualSlices(t, a.Data(), []float32{1, -1, -2, -2})
+
+	// the mask can't change the shape of the tensor
+	square := tensor.CreateBoolTensor([]bool{true, true, true, true}, types.Shape{2, 2})
+	assert(t, tensor.Range[float32](2).MaskedFill(square, 0).Err != nil)
+
+	b := tensor.Range[int32](6).Reshape(2, 3)
+	selected := b.MaskedSelect(b.Ge(tensor.Scalar[int32](2))).MustAssert()
+	assertEqualSlices(t, selected.Shape(), types.Shape{4})
+	assertEqualSlices(t, selected.Data(), []int32{2, 3, 4, 5})
+	// views are selected in the logical order
+	selected = b.T().MaskedSelect(tensor.CreateBoolTensor([]bool{true, false}, types.Shape{2})).MustAssert()
+	assertEqualSlices(t, selected.Data(), []int32{0, 1, 2})
+
+	assertEqualSlices(t, b.MaskedSelect(b.Lt(tensor.Scalar[int32](0))).Shape(), types.Shape{0})
+	assert(t, b.MaskedSelect(tensor.CreateBoolTensor([]bool{true, false}, types.Shape{2})).Err != nil)
+
+	// masked ops work with 16-bit floats
+	h := tensor.AsType[float32, types.Float16](tensor.CreateTensor([]float32{-1, 2}, types.Shape{2}))
+	h = h.MaskedFill(h.Lt(tensor.Zeros[types.Float16](1)), 0)
+	assertEqualSlices(t, tensor.AsType[types.Float16, float32](h).Data(), []float32{0, 2})
+}
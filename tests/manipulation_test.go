@@ -0,0 +1,183 @@
+package main
+
+import (
+	"gograd/grad"
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"testing"
+)
+
+func TestFlipSwapMoveAxis(t *testing.T) {
+	a := tensor.Range[int32](6).Reshape(2, 3)
+	assertEqualSlices(t, a.Flip(1).Data(), []int32{2, 1, 0, 5, 4, 3})
+	assertEqualSlices(t, a.Flip(0).Data(), []int32{3, 4, 5, 0, 1, 2})
+	assertEqualSlices(t, a.Flip().Data(), []int32{5, 4, 3, 2, 1, 0})
+	// flipped tensor is a view
+	a.Flip(1).SetIndexAdv("0,0", tensor.Scalar[int32](10))
+	assertEqualSlices(t, a.Data(), []int32{0, 1, 10, 3, 4, 5})
+	// non-contiguous input
+	assertEqualSlices(t, a.T().Flip(0).Data(), []int32{10, 5, 1, 4, 0, 3})
+	assert(t, a.Flip(0, 0).Err != nil)
+
+	b := tensor.Range[int](24).Reshape(2, 3, 4)
+	assertEqualSlices(t, b.SwapAxes(0, -1).Shape(), types.Shape{4, 3, 2})
+	assertEqualSlices(t, b.SwapAxes(0, 2).Data(), b.T(2, 1, 0).Data())
+	assertEqualSlices(t, b.MoveAxis(0, -1).Shape(), types.Shape{3, 4, 2})
+	assertEqualSlices(t, b.MoveAxis(0, -1).Data(), b.T(1, 2, 0).Data())
+	assertEqualSlices(t, b.MoveAxis(2, 0).Shape(), types.Shape{4, 2, 3})
+	assertEqualSlices(t, b.MoveAxis(2, 0).Data(), b.T(2, 0, 1).Data())
+	assert(t, b.MoveAxis(3, 0).Err != nil)
+}
+
+func TestRot90(t *testing.T) {
+	a := tensor.CreateTensor([]int{1, 2, 3, 4, 5, 6}, types.Shape{2, 3})
+	assertEqualSlices(t, a.Rot90(1).Shape(), types.Shape{3, 2})
+	assertEqualSlices(t, a.Rot90(1).Data(), []int{3, 6, 2, 5, 1, 4})
+	assertEqualSlices(t, a.Rot90(2).Data(), []int{6, 5, 4, 3, 2, 1})
+	assertEqualSlices(t, a.Rot90(3).Data(), []int{4, 1, 5, 2, 6, 3})
+	assertEqualSlices(t, a.Rot90(-1).Data(), a.Rot90(3).Data())
+	assertEqualSlices(t, a.Rot90(4).Data(), a.Data())
+	assertEqualSlices(t, a.Rot90(1, 1, 0).Data(), a.Rot90(-1).Data())
+
+	b := tensor.Range[int](8).Reshape(2, 2, 2)
+	// rotation of every matrix of the batch
+	assertEqualSlices(t, b.Rot90(1, 1, 2).Data(), []int{1, 3, 0, 2, 5, 7, 4, 6})
+	assert(t, b.Rot90(1, 1).Err != nil)
+	assert(t, b.Rot90(1, 1, -2).Err != nil)
+}
+
+func TestRoll(t *testing.T) {
+	a := tensor.Range[float32](5)
+	assertEqualSlices(t, a.Roll(2, 0).Data(), []float32{3, 4, 0, 1, 2})
+	assertEqualSlices(t, a.Roll(-1, 0).Data(), []float32{1, 2, 3, 4, 0})
+	assertEqualSlices(t, a.Roll(7, 0).Data(), a.Roll(2, 0).Data())
+	assertEqualSlices(t, a.Roll(5, 0).Data(), a.Data())
+
+	b := tensor.Range[float32](6).Reshape(2, 3)
+	assertEqualSlices(t, b.Roll(1, 1).Data(), []float32{2, 0, 1, 5, 3, 4})
+	assertEqualSlices(t, b.Roll(1, 0).Data(), []float32{3, 4, 5, 0, 1, 2})
+	assertEqualSlices(t, b.T().Roll(1, 0).Data(), []float32{2, 5, 0, 3, 1, 4})
+	assert(t, b.Roll(1, 2).Err != nil)
+}
+
+func TestTileRepeatInterleave(t *testing.T) {
+	a := tensor.CreateTensor([]int32{1, 2}, types.Shape{1, 2})
+	assertEqualSlices(t, a.Tile(2, 2).Shape(), types.Shape{2, 4})
+	assertEqualSlices(t, a.Tile(2, 2).Data(), []int32{1, 2, 1, 2, 1, 2, 1, 2})
+	assertEqualSlices(t, a.Tile(2).Data(), []int32{1, 2, 1, 2})
+	assertEqualSlices(t, a.Tile(2, 1, 1).Shape(), types.Shape{2, 1, 2})
+	// the result doesn't share the memory
+	tiled := a.Tile(1, 1)
+	tiled.Fill(0)
+	assertEqualSlices(t, a.Data(), []int32{1, 2})
+
+	b := tensor.Range[int32](4).Reshape(2, 2).T()
+	assertEqualSlices(t, b.Tile(1, 2).Data(), []int32{0, 2, 0, 2, 1, 3, 1, 3})
+	assert(t, b.Tile(0).Err != nil)
+
+	c := tensor.CreateTensor([]int{1, 2, 3, 4}, types.Shape{2, 2})
+	assertEqualSlices(t, c.RepeatInterleave([]int{2}, 1).Data(), []int{1, 1, 2, 2, 3, 3, 4, 4})
+	assertEqualSlices(t, c.RepeatInterleave([]int{1, 2}, 0).Data(), []int{1, 2, 3, 4, 3, 4})
+	assertEqualSlices(t, c.T().RepeatInterleave([]int{2}, -1).Data(), []int{1, 1, 3, 3, 2, 2, 4, 4})
+	assert(t, c.RepeatInterleave([]int{1, 2, 3}, 0).Err != nil)
+	assert(t, c.RepeatInterleave([]int{0}, 0).Err != nil)
+}
+
+func TestTrilTriu(t *testing.T) {
+	a := tensor.Range[float32](1, 7).Reshape(2, 3)
+	assertEqualSlices(t, a.Tril(0).Data(), []float32{1, 0, 0, 4, 5, 0})
+	assertEqualSlices(t, a.Tril(1).Data(), []float32{1, 2, 0, 4, 5, 6})
+	assertEqualSlices(t, a.Tril(-1).Data(), []float32{0, 0, 0, 4, 0, 0})
+	assertEqualSlices(t, a.Triu(0).Data(), []float32{1, 2, 3, 0, 5, 6})
+	assertEqualSlices(t, a.Triu(1).Data(), []float32{0, 2, 3, 0, 0, 6})
+	// input is not changed
+	assertEqualSlices(t, a.Data(), []float32{1, 2, 3, 4, 5, 6})
+
+	// batch of transposed matrices
+	b := tensor.Range[int](8).Reshape(2, 2, 2).T(0, 2, 1)
+	assertEqualSlices(t, b.Tril(0).Data(), []int{0, 0, 1, 3, 4, 0, 5, 7})
+	assert(t, tensor.Range[int](3).Tril(0).Err != nil)
+}
+
+func TestPad(t *testing.T) {
+	a := tensor.CreateTensor([]int{1, 2, 3}, types.Shape{3})
+	w := [][2]int{{2, 1}}
+	assertEqualSlices(t, a.Pad(w, tensor.PadConstant).Data(), []int{0, 0, 1, 2, 3, 0})
+	assertEqualSlices(t, a.Pad(w, tensor.PadConstant, 9).Data(), []int{9, 9, 1, 2, 3, 9})
+	assertEqualSlices(t, a.Pad(w, tensor.PadReflect).Data(), []int{3, 2, 1, 2, 3, 2})
+	assertEqualSlices(t, a.Pad(w, tensor.PadReplicate).Data(), []int{1, 1, 1, 2, 3, 3})
+	assertEqualSlices(t, a.Pad(w, tensor.PadCircular).Data(), []int{2, 3, 1, 2, 3, 1})
+	// widths longer than the dim
+	assertEqualSlices(t, a.Pad([][2]int{{4, 0}}, tensor.PadReflect).Data(), []int{1, 2, 3, 2, 1, 2, 3})
+	assertEqualSlices(t, a.Pad([][2]int{{0, 4}}, tensor.PadCircular).Data(), []int{1, 2, 3, 1, 2, 3, 1})
+
+	b := tensor.CreateTensor([]float32{1, 2, 3, 4}, types.Shape{2, 2})
+	p := b.Pad([][2]int{{1, 0}, {0, 1}}, tensor.PadConstant, -1)
+	assertEqualSlices(t, p.Shape(), types.Shape{3, 3})
+	assertEqualSlices(t, p.Data(), []float32{-1, -1, -1, 1, 2, -1, 3, 4, -1})
+	r := b.Pad([][2]int{{1, 1}, {1, 1}}, tensor.PadReplicate)
+	assertEqualSlices(t, r.Data(), []float32{1, 1, 2, 2, 1, 1, 2, 2, 3, 3, 4, 4, 3, 3, 4, 4})
+	// non-contiguous input
+	c := b.T().Pad([][2]int{{0, 0}, {1, 0}}, tensor.PadCircular)
+	assertEqualSlices(t, c.Data(), []float32{3, 1, 3, 4, 2, 4})
+
+	assert(t, b.Pad([][2]int{{1, 1}}, tensor.PadConstant).Err != nil)
+	assert(t, b.Pad([][2]int{{-1, 0}, {0, 0}}, tensor.PadConstant).Err != nil)
+	assert(t, b.Pad([][2]int{{0, 0}, {0, 0}}, tensor.PadMode(7)).Err != nil)
+}
+
+func TestGradManipulation(t *testing.T) {
+	g := tensor.Range[float32](1, 7).Reshape(2, 3)
+
+	x := grad.Variable(tensor.Range[float32](6).Reshape(2, 3))
+	x.Flip(1).Backward(g)
+	assertEqualSlices(t, x.Grad.Data(), []float32{3, 2, 1, 6, 5, 4})
+
+	x = grad.Variable(tensor.Range[float32](6).Reshape(2, 3))
+	x.Roll(1, 1).Backward(g)
+	assertEqualSlices(t, x.Grad.Data(), []float32{2, 3, 1, 5, 6, 4})
+
+	x = grad.Variable(tensor.Range[float32](6).Reshape(3, 2))
+	x.Rot90(1).Backward(g.Clone().Reshape(2, 3))
+	// out[i][j] = x[j][1-i]
+	assertEqualSlices(t, x.Grad.Data(), []float32{4, 1, 5, 2, 6, 3})
+
+	x = grad.Variable(tensor.Range[float32](6).Reshape(3, 2))
+	x.SwapAxes(0, 1).Backward(g)
+	assertEqualSlices(t, x.Grad.Data(), []float32{1, 4, 2, 5, 3, 6})
+	x = grad.Variable(tensor.Range[float32](6).Reshape(3, 2))
+	x.MoveAxis(1, 0).Backward(g)
+	assertEqualSlices(t, x.Grad.Data(), []float32{1, 4, 2, 5, 3, 6})
+
+	x = grad.Variable(tensor.Range[float32](2).Reshape(1, 2))
+	x.Tile(2, 2).Backward(tensor.Range[float32](8).Reshape(2, 4))
+	// every element is repeated 4 times: 0+2+4+6, 1+3+5+7
+	assertEqualSlices(t, x.Grad.Data(), []float32{12, 16})
+
+	x = grad.Variable(tensor.Range[float32](3))
+	x.Tile(2, 1).Backward(tensor.Ones[float32](2, 3))
+	assertEqualSlices(t, x.Grad.Data(), []float32{2, 2, 2})
+
+	x = grad.Variable(tensor.Range[float32](2))
+	x.RepeatInterleave([]int{1, 2}, 0).Backward(tensor.CreateTensor([]float32{1, 2, 3}, types.Shape{3}))
+	assertEqualSlices(t, x.Grad.Data(), []float32{1, 5})
+
+	x = grad.Variable(tensor.Range[float32](6).Reshape(2, 3))
+	x.Triu(1).Backward(g)
+	assertEqualSlices(t, x.Grad.Data(), []float32{0, 2, 3, 0, 0, 6})
+	x = grad.Variable(tensor.Range[float32](6).Reshape(2, 3))
+	x.Tril(0).Backward(g)
+	assertEqualSlices(t, x.Grad.Data(), []float32{1, 0, 0, 4, 5, 0})
+
+	p := grad.Variable(tensor.CreateTensor([]float32{1, 2, 3}, types.Shape{3}))
+	p.Pad([][2]int{{2, 1}}, tensor.PadConstant).Backward(tensor.Range[float32](6))
+	assertEqualSlices(t, p.Grad.Data(), []float32{2, 3, 4})
+	p = grad.Variable(tensor.CreateTensor([]float32{1, 2, 3}, types.Shape{3}))
+	// [3,2,1,2,3,2] takes x[2],x[1],x[0],x[1],x[2],x[1]
+	p.Pad([][2]int{{2, 1}}, tensor.PadReflect).Backward(tensor.Range[float32](6))
+	assertEqualSlices(t, p.Grad.Data(), []float32{2, 1 + 3 + 5, 0 + 4})
+
+	q := grad.Variable(tensor.Ones[float32](2, 2))
+	q.Pad([][2]int{{1, 1}, {1, 1}}, tensor.PadReplicate).Backward(tensor.Ones[float32](4, 4))
+	assertEqualSlices(t, q.Grad.Data(), []float32{4, 4, 4, 4})
+}
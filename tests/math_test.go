@@ -0,0 +1,175 @@
+package main
+
+import (
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"math"
+	"testing"
+)
+
+// float32 values with 19 elements, so both AVX kernels and the tail are used
+func mathTestValues() *tensor.Tensor[float32] {
+	return tensor.CreateTensor([]float32{
+		-3.5, -2.5, -1.7, -1.5, -0.5, -0.2, 0, 0.2, 0.5, 1.5,
+		1.7, 2.5, 3.5, 4, 9, 16, 100, -100, 0.25,
+	}, types.Shape{19})
+}
+
+func TestMathUnarySimd(t *testing.T) {
+	a := mathTestValues()
+	cases := []struct {
+		name string
+		fn   func(*tensor.Tensor[float32]) *tensor.Tensor[float32]
+		ref  func(float64) float64
+	}{
+		{"Abs", func(a *tensor.Tensor[float32]) *tensor.Tensor[float32] { return a.Abs() }, math.Abs},
+		{"Floor", func(a *tensor.Tensor[float32]) *tensor.Tensor[float32] { return a.Floor() }, math.Floor},
+		{"Ceil", func(a *tensor.Tensor[float32]) *tensor.Tensor[float32] { return a.Ceil() }, math.Ceil},
+		{"Round", func(a *tensor.Tensor[float32]) *tensor.Tensor[float32] { return a.Round() }, math.RoundToEven},
+		{"Trunc", func(a *tensor.Tensor[float32]) *tensor.Tensor[float32] { return a.Trunc() }, math.Trunc},
+		{"Square", func(a *tensor.Tensor[float32]) *tensor.Tensor[float32] { return a.Square() },
+			func(x float64) float64 { return x * x }},
+		{"Sign", func(a *tensor.Tensor[float32]) *tensor.Tensor[float32] { return a.Sign() },
+			func(x float64) float64 {
+				if x == 0 {
+					return 0
+				}
+				return math.Copysign(1, x)
+			}},
+		{"Sin", func(a *tensor.Tensor[float32]) *tensor.Tensor[float32] { return a.Sin() }, math.Sin},
+		{"Cos", func(a *tensor.Tensor[float32]) *tensor.Tensor[float32] { return a.Cos() }, math.Cos},
+		{"Tanh", func(a *tensor.Tensor[float32]) *tensor.Tensor[float32] { return a.Tanh() }, math.Tanh},
+		{"Expm1", func(a *tensor.Tensor[float32]) *tensor.Tensor[float32] { return a.Expm1() }, math.Expm1},
+		{"Erf", func(a *tensor.Tensor[float32]) *tensor.Tensor[float32] { return a.Erf() }, math.Erf},
+	}
+	for _, c := range cases {
+		got := c.fn(a).MustAssert()
+		expected := a.ApplyFunc(func(v float32) float32 { return float32(c.ref(float64(v))) })
+		assertEqualSlices(t, got.Shape(), a.Shape())
+		if ok, _ := got.IsAllClose(expected, 1e-5); !ok {
+			t.Errorf("%v: got %v, expected %v", c.name, got.Data(), expected.Data())
+		}
+	}
+}
+
+func TestMathPositiveDomain(t *testing.T) {
+	a := tensor.Range[float32](1, 21)
+	cases := []struct {
+		name string
+		got  *tensor.Tensor[float32]
+		ref  func(float64) float64
+	}{
+		{"Sqrt", a.Sqrt(), math.Sqrt},
+		{"Rsqrt", a.Rsqrt(), func(x float64) float64 { return 1 / math.Sqrt(x) }},
+		{"Reciprocal", a.Reciprocal(), func(x float64) float64 { return 1 / x }},
+		{"Log2", a.Log2(), math.Log2},
+		{"Log10", a.Log10(), math.Log10},
+		{"Log1p", a.Log1p(), math.Log1p},
+		{"Tan", a.Tan(), math.Tan},
+	}
+	for _, c := range cases {
+		expected := a.ApplyFunc(func(v float32) float32 { return float32(c.ref(float64(v))) })
+		if ok, _ := c.got.MustAssert().IsAllClose(expected, 1e-4); !ok {
+			t.Errorf("%v: got %v, expected %v", c.name, c.got.Data(), expected.Data())
+		}
+	}
+	assertEqualSlices(t, a.Sqrt().Data()[:4], []float32{1, 1.4142135, 1.7320508, 2})
+}
+
+func TestMathOtherTypes(t *testing.T) {
+	f := tensor.CreateTensor([]float64{-2.5, -0.5, 0.5, 1.5, 2.5}, types.Shape{5})
+	assertEqualSlices(t, f.Round().Data(), []float64{-2, 0, 0, 2, 2})
+	assertEqualSlices(t, f.Floor().Data(), []float64{-3, -1, 0, 1, 2})
+	assertEqualSlices(t, f.Abs().Data(), []float64{2.5, 0.5, 0.5, 1.5, 2.5})
+
+	i := tensor.CreateTensor([]int32{-9, -4, 0, 4, 9}, types.Shape{5})
+	assertEqualSlices(t, i.Abs().Data(), []int32{9, 4, 0, 4, 9})
+	assertEqualSlices(t, i.Sign().Data(), []int32{-1, -1, 0, 1, 1})
+	assertEqualSlices(t, i.Square().Data(), []int32{81, 16, 0, 16, 81})
+	assertEqualSlices(t, i.Floor().Data(), i.Data())
+	assertEqualSlices(t, i.Abs().Sqrt().Data(), []int32{3, 2, 0, 2, 3})
+	// integer reciprocal truncates, 1/0 gives 0
+	assertEqualSlices(t, tensor.Range[int32](-1, 3).Reciprocal().Data(), []int32{-1, 0, 1, 0})
+
+	u := tensor.CreateTensor([]uint8{0, 3, 200}, types.Shape{3})
+	assertEqualSlices(t, u.Sign().Data(), []uint8{0, 1, 1})
+	assertEqualSlices(t, u.Abs().Data(), u.Data())
+	assertEqualSlices(t, u.Reciprocal().Data(), []uint8{0, 0, 0})
+}
+
+func TestMathNaN(t *testing.T) {
+	nan := float32(math.NaN())
+	a := tensor.CreateTensor([]float32{nan, 1, 2, 3, 4, 5, 6, 7, 8, nan}, types.Shape{10})
+	assert(t, math.IsNaN(float64(a.Sign().Data()[0])))
+	assert(t, math.IsNaN(float64(a.Abs().Data()[9])))
+	assert(t, math.IsNaN(float64(a.Round().Data()[0])))
+
+	b := tensor.Ones[float32](10).Mul(tensor.Scalar[float32](3))
+	mx := a.Maximum(b).MustAssert().Data()
+	mn := b.Minimum(a).MustAssert().Data()
+	assert(t, math.IsNaN(float64(mx[0])) && math.IsNaN(float64(mx[9])))
+	assert(t, math.IsNaN(float64(mn[0])) && math.IsNaN(float64(mn[9])))
+	assertEqualSlices(t, mx[1:9], []float32{3, 3, 3, 4, 5, 6, 7, 8})
+	assertEqualSlices(t, mn[1:9], []float32{1, 2, 3, 3, 3, 3, 3, 3})
+}
+
+func TestMaximumMinimum(t *testing.T) {
+	a := tensor.Range[float32](12).Reshape(3, 4)
+	b := tensor.CreateTensor([]float32{5, 1, 9, 2}, types.Shape{4})
+	assertEqualSlices(t, a.Maximum(b).Data(), []float32{5, 1, 9, 3, 5, 5, 9, 7, 8, 9, 10, 11})
+	assertEqualSlices(t, a.Minimum(b).Data(), []float32{0, 1, 2, 2, 4, 1, 6, 2, 5, 1, 9, 2})
+	// scalar on both sides
+	assertEqualSlices(t, a.Maximum(tensor.Scalar[float32](6)).Data(), []float32{6, 6, 6, 6, 6, 6, 6, 7, 8, 9, 10, 11})
+	assertEqualSlices(t, tensor.Scalar[float32](6).Minimum(a).Data(), []float32{0, 1, 2, 3, 4, 5, 6, 6, 6, 6, 6, 6})
+
+	i := tensor.CreateTensor([]int{-1, 5, 3}, types.Shape{3})
+	assertEqualSlices(t, i.Maximum(tensor.Scalar(2)).Data(), []int{2, 5, 3})
+	assertEqualSlices(t, i.Minimum(tensor.Scalar(2)).Data(), []int{-1, 2, 2})
+
+	a.Maximum(tensor.Scalar[float32](10), a)
+	assertEqualSlices(t, a.Data(), []float32{10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 11})
+}
+
+func TestModFloorDiv(t *testing.T) {
+	a := tensor.CreateTensor([]int{-7, -3, 3, 7, 0}, types.Shape{5})
+	b := tensor.Scalar(3)
+	assertEqualSlices(t, a.Mod(b).Data(), []int{2, 0, 0, 1, 0})
+	assertEqualSlices(t, a.FloorDiv(b).Data(), []int{-3, -1, 1, 2, 0})
+	nb := tensor.Scalar(-3)
+	assertEqualSlices(t, a.Mod(nb).Data(), []int{-1, 0, 0, -2, 0})
+	assertEqualSlices(t, a.FloorDiv(nb).Data(), []int{2, 1, -1, -3, 0})
+	// division by zero does not panic
+	assertEqualSlices(t, a.Mod(tensor.Scalar(0)).Data(), []int{0, 0, 0, 0, 0})
+	assertEqualSlices(t, a.FloorDiv(tensor.Scalar(0)).Data(), []int{0, 0, 0, 0, 0})
+
+	f := tensor.CreateTensor([]float32{-7.5, -3, 3, 7.5}, types.Shape{4})
+	assertEqualSlices(t, f.Mod(tensor.Scalar[float32](2)).Data(), []float32{0.5, 1, 1, 1.5})
+	assertEqualSlices(t, f.FloorDiv(tensor.Scalar[float32](2)).Data(), []float32{-4, -2, 1, 3})
+	assertEqualSlices(t, f.Mod(tensor.Scalar[float32](-2)).Data(), []float32{-1.5, -1, -1, -0.5})
+	// a == floordiv(a, b) * b + mod(a, b)
+	d := tensor.CreateTensor([]float32{2, -2, 3, -4}, types.Shape{4})
+	assertAllClose(t, f.FloorDiv(d).Mul(d).Add(f.Mod(d)), f)
+
+	// scalar on the left and broadcasting
+	m := tensor.Scalar(10).Mod(tensor.CreateTensor([]int{3, 4, -3}, types.Shape{3}))
+	assertEqualSlices(t, m.Data(), []int{1, 2, -2})
+	g := tensor.Range[int](6).Reshape(2, 3).FloorDiv(tensor.CreateTensor([]int{1, 2, 4}, types.Shape{3}))
+	assertEqualSlices(t, g.Data(), []int{0, 0, 0, 3, 2, 1})
+}
+
+func TestMathOut(t *testing.T) {
+	a := tensor.Range[float32](-8, 8)
+	out := tensor.CreateEmptyTensor[float32](16)
+	a.Abs(out)
+	assertEqualSlices(t, out.Data(), []float32{8, 7, 6, 5, 4, 3, 2, 1, 0, 1, 2, 3, 4, 5, 6, 7})
+	// in-place
+	a.Square(a)
+	assertEqualSlices(t, a.Data()[:3], []float32{64, 49, 36})
+	// non-contiguous input and output view
+	m := tensor.Range[float32](-4, 5).Reshape(3, 3)
+	view := m.View().T()
+	res := view.Abs()
+	assertEqualSlices(t, res.Data(), []float32{4, 1, 2, 3, 0, 3, 2, 1, 4})
+	m.Sign(m.View().T())
+	assertEqualSlices(t, m.Data(), []float32{-1, -1, 1, -1, 0, 1, -1, 1, 1})
+}
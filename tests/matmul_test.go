@@ -0,0 +1,161 @@
+package main
+
+import (
+	"gograd/tensor"
+	"gograd/tensor/types"
+	"math"
+	"testing"
+
+	"github.com/klauspost/cpuid/v2"
+)
+
+// O(N^3) reference implementation
+func naiveMatMul(a, b []float64, m, k, n int) []float64 {
+	out := make([]float64, m*n)
+	for i := 0; i < m; i++ {
+		for j := 0; j < n; j++ {
+			for p := 0; p < k; p++ {
+				out[i*n+j] += a[i*k+p] * b[p*n+j]
+			}
+		}
+	}
+	return out
+}
+
+func toFloat64(data []float32) []float64 {
+	out := make([]float64, len(data))
+	for i, v := range data {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+func assertMatMulClose(t *testing.T, got []float32, expected []float64) {
+	for i, v := range expected {
+		if math.Abs(float64(got[i])-v) > 1e-3*math.Max(1, math.Abs(v)) {
+			t.Errorf("element %v: got %v, expected %v", i, got[i], v)
+			return
+		}
+	}
+}
+
+// available implementations: Default, AVX, AVX512
+func availableImpls() []int {
+	impls := []int{0}
+	if cpuid.CPU.Supports(cpuid.AVX, cpuid.AVX2, cpuid.FMA3) {
+		impls = append(impls, 1)
+	}
+	if cpuid.CPU.Supports(cpuid.AVX512F, cpuid.AVX512DQ) {
+		impls = append(impls, 2)
+	}
+	return impls
+}
+
+func TestMatMulBlocked(t *testing.T) {
+	// sizes are not multiples of the micro tiles and K spans several blocks
+	rng := tensor.NewRNG(42)
+	m, k, n := 131, 517, 270
+	a := rng.RandomFloat32(types.Dim(m), types.Dim(k))
+	b := rng.RandomFloat32(types.Dim(k), types.Dim(n))
+	expected := naiveMatMul(toFloat64(a.Data()), toFloat64(b.Data()), m, k, n)
+
+	saved := tensor.AUTO_IMPL
+	defer func() { tensor.AUTO_IMPL = saved }()
+	for _, impl := range availableImpls() {
+		tensor.AUTO_IMPL.SetImpl(impl)
+		c := a.MatMul(b).MustAssert()
+		assertEqualSlices(t, c.Shape(), types.Shape{types.Dim(m), types.Dim(n)})
+		assertMatMulClose(t, c.Data(), expected)
+
+		// matrix-vector
+		v := b.IndexAdv(":, 7:8").Clone()
+		mv := a.MatMul(v).MustAssert()
+		expected_mv := naiveMatMul(toFloat64(a.Data()), toFloat64(v.Data()), m, k, 1)
+		assertMatMulClose(t, mv.Data(), expected_mv)
+	}
+}
+
+func TestMatMulBlockedBatched(t *testing.T) {
+	rng := tensor.NewRNG(7)
+	a := rng.RandomFloat32(3, 70, 40)
+	b := rng.RandomFloat32(40, 300)
+	c := a.MatMul(b).MustAssert()
+	for i := 0; i < 3; i++ {
+		expected := naiveMatMul(toFloat64(a.Index(i).Data()), toFloat64(b.Data()), 70, 40, 300)
+		assertMatMulClose(t, c.Index(i).Data(), expected)
+	}
+}
+
+func TestMatMulBlockedInt(t *testing.T) {
+	m, k, n := 100, 300, 260
+	a := tensor.Range[int32](m*k).Reshape(types.Dim(m), types.Dim(k)).ApplyFunc(func(v int32) int32 { return v%7 - 3 })
+	b := tensor.Range[int32](k*n).Reshape(types.Dim(k), types.Dim(n)).ApplyFunc(func(v int32) int32 { return v%5 - 2 })
+	c := a.MatMul(b).MustAssert()
+	a_data, b_data := a.Data(), b.Data()
+	for _, ij := range [][2]int{{0, 0}, {99, 259}, {50, 17}, {3, 258}} {
+		var expected int32
+		for p := 0; p < k; p++ {
+			expected += a_data[ij[0]*k+p] * b_data[p*n+ij[1]]
+		}
+		v, _ := c.Get(ij[0], ij[1])
+		assert(t, v == expected)
+	}
+}
+
+func TestMatMulStrassen(t *testing.T) {
+	saved := tensor.STRASSEN_CUTOFF
+	defer func() { tensor.STRASSEN_CUTOFF = saved }()
+	// small cutoff to force several recursion levels with odd blocks
+	tensor.STRASSEN_CUTOFF = 16
+
+	rng := tensor.NewRNG(3)
+	for _, mkn := range [][3]int{{64, 64, 64}, {67, 67, 67}, {45, 90, 33}, {100, 17, 81}, {17, 3, 1}} {
+		m, k, n := mkn[0], mkn[1], mkn[2]
+		a := rng.RandomFloat32(types.Dim(m), types.Dim(k))
+		b := rng.RandomFloat32(types.Dim(k), types.Dim(n))
+		c := a.MatMulStrassen(b).MustAssert()
+		assertEqualSlices(t, c.Shape(), types.Shape{types.Dim(m), types.Dim(n)})
+		assertMatMulClose(t, c.Data(), naiveMatMul(toFloat64(a.Data()), toFloat64(b.Data()), m, k, n))
+	}
+
+	// non-contiguous operands
+	a := rng.RandomFloat32(40, 70)
+	b := rng.RandomFloat32(50, 70).T()
+	c := a.MatMulStrassen(b).MustAssert()
+	assertMatMulClose(t, c.Data(), naiveMatMul(toFloat64(a.Data()), toFloat64(b.Data()), 40, 70, 50))
+}
+
+func TestMatMulStrassenFloat64(t *testing.T) {
+	saved := tensor.STRASSEN_CUTOFF
+	defer func() { tensor.STRASSEN_CUTOFF = saved }()
+	tensor.STRASSEN_CUTOFF = 8
+
+	rng := tensor.NewRNG(5)
+	m, k, n := 75, 50, 61
+	a := rng.RandomFloat64(types.Dim(m), types.Dim(k))
+	b := rng.RandomFloat64(types.Dim(k), types.Dim(n))
+	c := a.MatMulStrassen(b).MustAssert()
+	expected := naiveMatMul(a.Data(), b.Data(), m, k, n)
+	for i, v := range c.Data() {
+		if math.Abs(v-expected[i]) > 1e-10 {
+			t.Fatalf("element %v: %v != %v", i, v, expected[i])
+		}
+	}
+}
+
+func TestMatMulStrassenInt(t *testing.T) {
+	saved := tensor.STRASSEN_CUTOFF
+	defer func() { tensor.STRASSEN_CUTOFF = saved }()
+	tensor.STRASSEN_CUTOFF = 8
+
+	// integer results must be exact
+	a := tensor.Range[int32](33*20).Reshape(33, 20).ApplyFunc(func(v int32) int32 { return v%7 - 3 })
+	b := tensor.Range[int32](20*41).Reshape(20, 41).ApplyFunc(func(v int32) int32 { return v%5 - 2 })
+	assertEqualSlices(t, a.MatMulStrassen(b).MustAssert().Data(), a.MatMul(b).Data())
+}
+
+func TestMatMulStrassenErrors(t *testing.T) {
+	a := tensor.Ones[float32](2, 3)
+	assert(t, a.MatMulStrassen(tensor.Ones[float32](2, 3)).Err != nil)
+	assert(t, tensor.Ones[float32](2, 2, 2).MatMulStrassen(tensor.Ones[float32](2, 2)).Err != nil)
+}
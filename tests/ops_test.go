@@ -83,6 +83,25 @@ func TestAddInplace(t *testing.T) {
 	assertEqualSlices(t, b2.Data(), []float32{0, 2, 4, 6})
 }
 
+func TestAddSubAllImpls(t *testing.T) {
+	// SIMD kernels exist for float32 and int32 only, other types must fall back to go loops
+	saved := tensor.AUTO_IMPL
+	defer func() { tensor.AUTO_IMPL = saved }()
+	for _, impl := range availableImpls() {
+		tensor.AUTO_IMPL.SetImpl(impl)
+		a := tensor.Range[float64](20)
+		b := tensor.Range[float64](20)
+		assertEqualSlices(t, a.Add(b).MustAssert().Data()[17:], []float64{34, 36, 38})
+		assertEqualSlices(t, a.Sub(tensor.Scalar[float64](1)).MustAssert().Data()[:3], []float64{-1, 0, 1})
+		c := tensor.Range[int64](20)
+		assertEqualSlices(t, c.Add(c).MustAssert().Data()[17:], []int64{34, 36, 38})
+		d := tensor.Range[uint8](20)
+		assertEqualSlices(t, d.Sub(d).MustAssert().Data(), make([]uint8, 20))
+		e := tensor.Range[int](20)
+		assertEqualSlices(t, e.Sub(tensor.Scalar(1)).MustAssert().Data()[:3], []int{-1, 0, 1})
+	}
+}
+
 func TestMul(t *testing.T) {
 	a := tensor.CreateEmptyTensor[float32](3, 2).Fill(2)
 	b := tensor.CreateEmptyTensor[float32](3, 1).Fill(3)
@@ -99,6 +118,33 @@ func TestMul2(t *testing.T) {
 	assertEqualSlices(t, ab.Shape(), types.Shape{3, 2})
 }
 
+func TestMulAllImpls(t *testing.T) {
+	// the SIMD Mul kernels exist for float32 and int32 only
+	saved := tensor.AUTO_IMPL
+	defer func() { tensor.AUTO_IMPL = saved }()
+	for _, impl := range availableImpls() {
+		tensor.AUTO_IMPL.SetImpl(impl)
+		a := tensor.Range[float64](20)
+		assertEqualSlices(t, a.Mul(a).MustAssert().Data()[17:], []float64{289, 324, 361})
+		b := tensor.Range[int](20)
+		assertEqualSlices(t, b.Mul(tensor.Scalar(-2)).MustAssert().Data()[17:], []int{-34, -36, -38})
+		c := tensor.Range[uint16](20)
+		assertEqualSlices(t, c.Mul(c).MustAssert().Data()[17:], []uint16{289, 324, 361})
+	}
+}
+
+func TestMulInt(t *testing.T) {
+	// more than one SIMD register of int32
+	a := tensor.Range[int32](20)
+	b := tensor.Range[int32](20)
+	c := a.Mul(b)
+	for i, v := range c.Data() {
+		assert(t, v == int32(i*i))
+	}
+	assertEqualSlices(t, a.Mul(tensor.Scalar[int32](-3)).Data()[8:11], []int32{-24, -27, -30})
+	assertEqualSlices(t, tensor.Range[float64](3).Mul(tensor.Range[float64](3)).Data(), []float64{0, 1, 4})
+}
+
 func TestMulToConst(t *testing.T) {
 	a1 := tensor.CreateTensor([]float32{1, 2, 3, 4, 5, 6}, types.Shape{2, 3})
 	a2 := tensor.Scalar[float32](2)
@@ -243,3 +289,79 @@ func TestSubScint(t *testing.T) {
 	})
 	tensor.MustAssertAll(g1, g2, g3)
 }
+
+func TestScSubDiv(t *testing.T) {
+	// scalar is the left operand
+	a := tensor.Range[float32](1, 4)
+	assertEqualSlices(t, tensor.Scalar[float32](1).Sub(a).Data(), []float32{0, -1, -2})
+	assertEqualSlices(t, tensor.Scalar[float32](6).Div(a).Data(), []float32{6, 3, 2})
+	b := tensor.Range[int32](1, 4)
+	assertEqualSlices(t, tensor.Scalar[int32](1).Sub(b).Data(), []int32{0, -1, -2})
+}
+
+func TestMatMulBatched(t *testing.T) {
+	// (2,2,3) @ (3,2) => (2,2,2)
+	a := tensor.Range[float32](12).Reshape(2, 2, 3)
+	b := tensor.Range[float32](6).Reshape(3, 2)
+	c := a.MatMul(b).MustAssert()
+	assertEqualSlices(t, c.Shape(), types.Shape{2, 2, 2})
+	assertEqualSlices(t, c.Data(), []float32{10, 13, 28, 40, 46, 67, 64, 94})
+
+	// batch dims are broadcasted: (2,1,1,3) @ (3,3,1) => (2,3,1,1)
+	x := tensor.Range[float32](6).Reshape(2, 1, 1, 3)
+	y := tensor.Range[float32](9).Reshape(3, 3, 1)
+	z := x.MatMul(y).MustAssert()
+	assertEqualSlices(t, z.Shape(), types.Shape{2, 3, 1, 1})
+	assertEqualSlices(t, z.Data(), []float32{5, 14, 23, 14, 50, 86})
+
+	// non-contiguous operands
+	at := tensor.Range[float32](12).Reshape(2, 3, 2).T(0, 2, 1)
+	c = at.MatMul(b).MustAssert()
+	assertEqualSlices(t, c.Data(), []float32{20, 26, 26, 35, 56, 80, 62, 89})
+
+	assert(t, a.MatMul(tensor.Range[float32](8).Reshape(2, 4)).Err != nil)
+	e := tensor.Range[float32](12).Reshape(2, 2, 3)
+	assert(t, e.MatMul(tensor.Range[float32](18).Reshape(3, 3, 2)).Err != nil)
+}
+
+func TestMatMul1D(t *testing.T) {
+	m := tensor.Range[float32](6).Reshape(2, 3)
+	v := tensor.Range[float32](3)
+	mv := m.MatMul(v).MustAssert()
+	assertEqualSlices(t, mv.Shape(), types.Shape{2})
+	assertEqualSlices(t, mv.Data(), []float32{5, 14})
+
+	w := tensor.Range[float32](2)
+	wm := w.MatMul(m).MustAssert()
+	assertEqualSlices(t, wm.Shape(), types.Shape{3})
+	assertEqualSlices(t, wm.Data(), []float32{3, 4, 5})
+
+	vv := v.MatMul(v).MustAssert()
+	assertEqualSlices(t, vv.Data(), []float32{5})
+	assertEqualSlices(t, vv.Shape(), types.Shape{})
+
+	batched := tensor.Range[float32](12).Reshape(2, 2, 3).MatMul(v).MustAssert()
+	assertEqualSlices(t, batched.Shape(), types.Shape{2, 2})
+	assertEqualSlices(t, batched.Data(), []float32{5, 14, 23, 32})
+}
+
+func TestMatMulTypes(t *testing.T) {
+	a64 := tensor.Range[float64](6).Reshape(2, 3)
+	b64 := tensor.Range[float64](6).Reshape(3, 2)
+	assertEqualSlices(t, a64.MatMul(b64).MustAssert().Data(), []float64{10, 13, 28, 40})
+
+	ai := tensor.Range[int32](6).Reshape(2, 3)
+	bi := tensor.Range[int32](6).Reshape(3, 2)
+	assertEqualSlices(t, ai.MatMul(bi).MustAssert().Data(), []int32{10, 13, 28, 40})
+
+	au := tensor.Range[uint8](8).Reshape(2, 2, 2)
+	assertEqualSlices(t, au.MatMul(au).MustAssert().Data(), []uint8{2, 3, 6, 11, 46, 55, 66, 79})
+}
+
+func TestDivOtherTypes(t *testing.T) {
+	// only float32 has the AVX kernel
+	a := tensor.Range[float64](2, 22, 2)
+	assertEqualSlices(t, a.Div(tensor.Scalar[float64](2)).Data(), tensor.Range[float64](1, 11).Data())
+	b := tensor.Range[int](10, 20)
+	assertEqualSlices(t, b.Div(tensor.Scalar(3)).Data(), []int{3, 3, 4, 4, 4, 5, 5, 5, 6, 6})
+}
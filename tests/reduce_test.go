@@ -60,3 +60,57 @@ func TestMin(t *testing.T) {
 	assertEqualSlices(t, b.Shape(), types.Shape{1, 1})
 	assertEqualSlices(t, b.Data(), []float32{0})
 }
+
+func TestReduceAxes(t *testing.T) {
+	a := tensor.Range[int32](24).Reshape(2, 3, 4)
+	b := a.Sum(false, 1).MustAssert()
+	assertEqualSlices(t, b.Shape(), types.Shape{2, 4})
+	assertEqualSlices(t, b.Data(), []int32{12, 15, 18, 21, 48, 51, 54, 57})
+
+	b = a.Sum(true, 0, -1).MustAssert()
+	assertEqualSlices(t, b.Shape(), types.Shape{1, 3, 1})
+	assertEqualSlices(t, b.Data(), []int32{60, 92, 124})
+
+	b = a.Sum(false, 0, 1, 2).MustAssert()
+	assertEqualSlices(t, b.Shape(), types.Shape{})
+	assertEqualSlices(t, b.Data(), []int32{276})
+
+	b = a.Max(false, -1).MustAssert()
+	assertEqualSlices(t, b.Data(), []int32{3, 7, 11, 15, 19, 23})
+	b = a.Min(false, 0, 1).MustAssert()
+	assertEqualSlices(t, b.Data(), []int32{0, 1, 2, 3})
+
+	f := tensor.Range[float32](6).Reshape(2, 3)
+	assertEqualSlices(t, f.Mean(false, 0).Data(), []float32{1.5, 2.5, 3.5})
+	assertEqualSlices(t, f.Mean(true, 1).Shape(), types.Shape{2, 1})
+	assertEqualSlices(t, f.Mean(true, 1).Data(), []float32{1, 4})
+	assertEqualSlices(t, f.Add(tensor.Scalar[float32](1)).Prod(false, 1).Data(), []float32{6, 120})
+	assertEqualSlices(t, f.Add(tensor.Scalar[float32](1)).Prod(false).Data(), []float32{720})
+
+	// non-contiguous input
+	assertEqualSlices(t, f.T().Sum(false, 0).Data(), []float32{3, 12})
+}
+
+func TestReduceAxesErrors(t *testing.T) {
+	a := tensor.Range[int32](6).Reshape(2, 3)
+	assert(t, a.Sum(false, 2).Err != nil)
+	b := tensor.Range[int32](6).Reshape(2, 3)
+	assert(t, b.Max(false, 0, -2).Err != nil)
+	c := tensor.Range[int32](6).Reshape(2, 3)
+	assert(t, c.ArgMax(-3, false).Err != nil)
+}
+
+func TestArgMaxArgMin(t *testing.T) {
+	a := tensor.CreateTensor([]float32{1, 5, 2, 7, 3, 7}, types.Shape{2, 3})
+	assertEqualSlices(t, a.ArgMax(-1, false).Data(), []int{1, 0})
+	b := a.ArgMax(0, true).MustAssert()
+	assertEqualSlices(t, b.Shape(), types.Shape{1, 3})
+	assertEqualSlices(t, b.Data(), []int{1, 0, 1})
+	assertEqualSlices(t, a.ArgMin(1, false).Data(), []int{0, 1})
+	assertEqualSlices(t, a.T().ArgMin(1, false).Data(), []int{0, 1, 0})
+
+	c := tensor.Range[int32](24).Reshape(2, 3, 4).Neg()
+	assertEqualSlices(t, c.ArgMax(1, false).Data(), []int{0, 0, 0, 0, 0, 0, 0, 0})
+	assertEqualSlices(t, c.ArgMin(2, false).Data(), []int{3, 3, 3, 3, 3, 3})
+	assert(t, tensor.Range[int32](5).ArgMax(0, false).Item() == 4)
+}
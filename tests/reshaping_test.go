@@ -56,3 +56,104 @@ func TestShapeStack(t *testing.T) {
 	assert(t, err == nil)
 	assertEqualSlices(t, stacked, types.Shape{6, 2, 3})
 }
+
+func TestShapeStackAxis(t *testing.T) {
+	stacked, err := types.StackShapes(1, types.Shape{2, 1, 3}, types.Shape{2, 4, 3})
+	assert(t, err == nil)
+	assertEqualSlices(t, stacked, types.Shape{2, 5, 3})
+	stacked, err = types.StackShapes(-1, types.Shape{2, 1}, types.Shape{2, 3})
+	assert(t, err == nil)
+	assertEqualSlices(t, stacked, types.Shape{2, 4})
+	_, err = types.StackShapes(0, types.Shape{2, 1}, types.Shape{2, 3})
+	assert(t, err != nil)
+	_, err = types.StackShapes(0, types.Shape{2, 3}, types.Shape{2, 3, 1})
+	assert(t, err != nil)
+	_, err = types.StackShapes(2, types.Shape{2, 3}, types.Shape{2, 3})
+	assert(t, err != nil)
+}
+
+func TestConcat(t *testing.T) {
+	a := tensor.Range[int32](6).Reshape(2, 3)
+	b := tensor.CreateTensor([]int32{10, 11}, types.Shape{2, 1})
+	c, err := tensor.Concat(-1, a, b)
+	assert(t, err == nil)
+	assertEqualSlices(t, c.Shape(), types.Shape{2, 4})
+	assertEqualSlices(t, c.Data(), []int32{0, 1, 2, 10, 3, 4, 5, 11})
+
+	// non-contiguous inputs
+	c, err = tensor.Concat(0, a.T(), a.IndexAdv(":, ::-1").T())
+	assert(t, err == nil)
+	assertEqualSlices(t, c.Shape(), types.Shape{6, 2})
+	assertEqualSlices(t, c.Data(), []int32{0, 3, 1, 4, 2, 5, 2, 5, 1, 4, 0, 3})
+
+	x := tensor.Range[int32](8).Reshape(2, 2, 2)
+	c, err = tensor.Concat(1, x, x)
+	assert(t, err == nil)
+	assertEqualSlices(t, c.Data(), []int32{0, 1, 2, 3, 0, 1, 2, 3, 4, 5, 6, 7, 4, 5, 6, 7})
+
+	_, err = tensor.Concat(0, a, b)
+	assert(t, err != nil)
+}
+
+func TestStackAxis(t *testing.T) {
+	a := tensor.Range[int32](6).Reshape(2, 3)
+	b := tensor.Range[int32](6).Reshape(2, 3).Neg()
+	s, err := tensor.Stack(0, a, b)
+	assert(t, err == nil)
+	assertEqualSlices(t, s.Shape(), types.Shape{2, 2, 3})
+	assertEqualSlices(t, s.Data(), []int32{0, 1, 2, 3, 4, 5, 0, -1, -2, -3, -4, -5})
+
+	s, err = tensor.Stack(-1, a, b)
+	assert(t, err == nil)
+	assertEqualSlices(t, s.Shape(), types.Shape{2, 3, 2})
+	assertEqualSlices(t, s.Data(), []int32{0, 0, 1, -1, 2, -2, 3, -3, 4, -4, 5, -5})
+	// inputs are not modified
+	assertEqualSlices(t, a.Shape(), types.Shape{2, 3})
+
+	_, err = tensor.Stack(0, a, a.T())
+	assert(t, err != nil)
+	_, err = tensor.Stack(3, a, b)
+	assert(t, err != nil)
+}
+
+func TestSplitChunk(t *testing.T) {
+	a := tensor.Range[int32](15).Reshape(5, 3)
+	parts, err := a.Split([]int{2, 3}, 0)
+	assert(t, err == nil)
+	assertEqualSlices(t, parts[0].Data(), []int32{0, 1, 2, 3, 4, 5})
+	assertEqualSlices(t, parts[1].Shape(), types.Shape{3, 3})
+
+	parts, err = a.Split([]int{1, 2}, -1)
+	assert(t, err == nil)
+	assertEqualSlices(t, parts[0].Data(), []int32{0, 3, 6, 9, 12})
+	assertEqualSlices(t, parts[1].Shape(), types.Shape{5, 2})
+	// parts are views
+	parts[0].Fill(-1)
+	v, _ := a.Get(4, 0)
+	assert(t, v == -1)
+
+	chunks, err := a.Chunk(3, 0)
+	assert(t, err == nil)
+	assert(t, len(chunks) == 3)
+	assertEqualSlices(t, chunks[2].Shape(), types.Shape{1, 3})
+	chunks, err = a.T().Chunk(2, 0)
+	assert(t, err == nil)
+	assertEqualSlices(t, chunks[1].Data(), []int32{2, 5, 8, 11, 14})
+
+	// zero sizes give empty views
+	parts, err = a.Split([]int{0, 5, 0}, 0)
+	assert(t, err == nil)
+	assertEqualSlices(t, parts[0].Shape(), types.Shape{0, 3})
+	assertEqualSlices(t, parts[1].Shape(), types.Shape{5, 3})
+	assertEqualSlices(t, parts[2].Data(), []int32{})
+	parts, err = tensor.CreateEmptyTensor[int32](0, 2).Split([]int{0}, 0)
+	assert(t, err == nil)
+	assertEqualSlices(t, parts[0].Shape(), types.Shape{0, 2})
+
+	_, err = a.Split([]int{2, 2}, 0)
+	assert(t, err != nil)
+	_, err = a.Split([]int{6, -1}, 0)
+	assert(t, err != nil)
+	_, err = a.Chunk(0, 0)
+	assert(t, err != nil)
+}
@@ -1,15 +1,130 @@
 package main
 
 import (
+	"gograd/grad"
 	"gograd/tensor"
 	types "gograd/tensor/types"
+	"math"
 	"testing"
 )
 
 func TestSort(t *testing.T) {
 	a := tensor.CreateTensor(
 		[]int32{3, 2, 5, 0, 6, 1, 0, 5}, types.Shape{2, 2, 2})
-	sort_a := a.Sort().MustAssert()
+	sort_a := a.Sort(-1, false).MustAssert()
+	assertEqualSlices(t, sort_a.Shape(), types.Shape{2, 2, 2})
 	assertEqualSlices(t,
-		sort_a.Data(), []int32{0, 0, 1, 2, 3, 5, 5, 6})
+		sort_a.Data(), []int32{2, 3, 0, 5, 1, 6, 0, 5})
+	assertEqualSlices(t,
+		a.Sort(0, false).Data(), []int32{3, 1, 0, 0, 6, 2, 5, 5})
+	assertEqualSlices(t,
+		a.Sort(1, true).Data(), []int32{5, 2, 3, 0, 6, 5, 0, 1})
+	// the flattened tensor is sorted as a whole
+	assertEqualSlices(t,
+		a.Clone().Reshape(8).Sort(0, false).Data(), []int32{0, 0, 1, 2, 3, 5, 5, 6})
+
+	// non-contiguous input
+	b := tensor.CreateTensor([]float32{3, 1, 2, 0, 5, 4}, types.Shape{2, 3}).T()
+	assertEqualSlices(t, b.Sort(1, false).Data(), []float32{0, 3, 1, 5, 2, 4})
+
+	nan := float32(math.NaN())
+	c := tensor.CreateTensor([]float32{2, nan, 1, 3}, types.Shape{4})
+	s := c.Sort(0, false).Data()
+	assertEqualSlices(t, s[:3], []float32{1, 2, 3})
+	assert(t, math.IsNaN(float64(s[3])))
+	s = c.Sort(0, true).Data()
+	assert(t, math.IsNaN(float64(s[0])))
+	assertEqualSlices(t, s[1:], []float32{3, 2, 1})
+
+	assert(t, a.Sort(3, false).Err != nil)
+}
+
+func TestArgSort(t *testing.T) {
+	a := tensor.CreateTensor([]float32{3, 1, 2, 1}, types.Shape{4})
+	// equal values keep their order
+	assertEqualSlices(t, a.ArgSort(0, false).Data(), []int{1, 3, 2, 0})
+	assertEqualSlices(t, a.ArgSort(0, true).Data(), []int{0, 2, 1, 3})
+
+	m := tensor.CreateTensor([]int{3, 0, 1, 2, 1, 0}, types.Shape{3, 2})
+	assertEqualSlices(t, m.ArgSort(0, false).Data(), []int{1, 0, 2, 2, 0, 1})
+	// ArgSort and TakeAlongAxis give the sorted tensor
+	idx := m.ArgSort(0, false)
+	assertEqualSlices(t, m.TakeAlongAxis(idx, 0).Data(), m.Sort(0, false).Data())
+	assert(t, m.ArgSort(-3, false).Err != nil)
+}
+
+func TestTopK(t *testing.T) {
+	a := tensor.CreateTensor([]float32{1, 5, 3, 5, 4, 0, 2, 6}, types.Shape{2, 4})
+	values, indices := a.TopK(2, 1)
+	assertEqualSlices(t, values.Shape(), types.Shape{2, 2})
+	assertEqualSlices(t, values.Data(), []float32{5, 5, 6, 4})
+	assertEqualSlices(t, indices.Data(), []int{1, 3, 3, 0})
+	values, indices = a.TopK(1, 0)
+	assertEqualSlices(t, values.Data(), []float32{4, 5, 3, 6})
+	assertEqualSlices(t, indices.Data(), []int{1, 0, 0, 1})
+
+	// long lanes select with a heap, the result must match the full sort
+	n := 1000
+	data := make([]int32, 2*n)
+	for i := range data {
+		data[i] = int32((i * 7919) % 113)
+	}
+	b := tensor.CreateTensor(data, types.Shape{2, types.Dim(n)})
+	top, top_indices := b.TopK(5, 1)
+	sorted := b.Sort(1, true).Data()
+	arg := b.ArgSort(1, true).Data()
+	assertEqualSlices(t, top.Data(), append(append([]int32{}, sorted[:5]...), sorted[n:n+5]...))
+	assertEqualSlices(t, top_indices.Data(), append(append([]int{}, arg[:5]...), arg[n:n+5]...))
+
+	values, indices = a.TopK(5, 1)
+	assert(t, values.Err != nil && indices.Err != nil)
+	values, _ = a.TopK(0, 1)
+	assert(t, values.Err != nil)
+}
+
+func TestUnique(t *testing.T) {
+	a := tensor.CreateTensor([]int{2, 1, 2, 3, 1, 2}, types.Shape{2, 3})
+	values, inverse, counts := a.Unique()
+	assertEqualSlices(t, values.Data(), []int{1, 2, 3})
+	assertEqualSlices(t, inverse.Shape(), types.Shape{2, 3})
+	assertEqualSlices(t, inverse.Data(), []int{1, 0, 1, 2, 0, 1})
+	assertEqualSlices(t, counts.Data(), []int{2, 3, 1})
+	// values can be restored with the inverse
+	assertEqualSlices(t, values.IndexSelect(0, inverse.Clone().Reshape(6)).Data(), a.Data())
+	assertEqualSlices(t, a.Shape(), types.Shape{2, 3})
+
+	nan := math.NaN()
+	b := tensor.CreateTensor([]float64{nan, 1, nan}, types.Shape{3})
+	unique, inverse, counts := b.Unique()
+	assert(t, len(unique.Data()) == 2 && unique.Data()[0] == 1 && math.IsNaN(unique.Data()[1]))
+	assertEqualSlices(t, inverse.Data(), []int{1, 0, 1})
+	assertEqualSlices(t, counts.Data(), []int{1, 2})
+}
+
+func TestSearchSorted(t *testing.T) {
+	a := tensor.CreateTensor([]float32{1, 3, 3, 5}, types.Shape{4})
+	v := tensor.CreateTensor([]float32{3, 6, 0, 4}, types.Shape{2, 2})
+	assertEqualSlices(t, a.SearchSorted(v, false).Data(), []int{1, 4, 0, 3})
+	assertEqualSlices(t, a.SearchSorted(v, true).Data(), []int{3, 4, 0, 3})
+	assertEqualSlices(t, a.SearchSorted(v, true).Shape(), types.Shape{2, 2})
+
+	// every row is searched separately
+	b := tensor.CreateTensor([]int{1, 2, 3, 10, 20, 30}, types.Shape{2, 3})
+	w := tensor.CreateTensor([]int{2, 25}, types.Shape{2, 1})
+	assertEqualSlices(t, b.SearchSorted(w, false).Data(), []int{1, 2})
+	assert(t, b.SearchSorted(tensor.Range[int](3), false).Err != nil)
+}
+
+func TestGradSortTopK(t *testing.T) {
+	x := grad.Variable(tensor.CreateTensor([]float32{3, 1, 2, 0, 5, 4}, types.Shape{2, 3}))
+	s := x.Sort(1, false)
+	assertEqualSlices(t, s.Value.Data(), []float32{1, 2, 3, 0, 4, 5})
+	s.Backward(tensor.CreateTensor([]float32{1, 2, 3, 4, 5, 6}, types.Shape{2, 3}))
+	assertEqualSlices(t, x.Grad.Data(), []float32{3, 1, 2, 4, 6, 5})
+
+	y := grad.Variable(tensor.CreateTensor([]float32{3, 1, 2, 0, 5, 4}, types.Shape{2, 3}))
+	top, indices := y.TopK(1, 1)
+	assertEqualSlices(t, indices.Data(), []int{0, 1})
+	top.Backward(tensor.Ones[float32](2, 1))
+	assertEqualSlices(t, y.Grad.Data(), []float32{1, 0, 0, 0, 1, 0})
 }
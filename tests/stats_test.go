@@ -0,0 +1,140 @@
+package main
+
+import (
+	"gograd/tensor"
+	types "gograd/tensor/types"
+	"math"
+	"testing"
+)
+
+func TestVarStd(t *testing.T) {
+	a := tensor.CreateTensor([]float32{1, 2, 3, 6}, types.Shape{2, 2})
+	assertEqualSlices(t, a.Var(0, false).Data(), []float32{3.5})
+	assertEqualSlices(t, a.Var(1, false).Data(), []float32{14. / 3})
+	assertEqualSlices(t, a.Var(0, false, 0).Data(), []float32{1, 4})
+	assertEqualSlices(t, a.Var(0, true, 1).Shape(), types.Shape{2, 1})
+	assertEqualSlices(t, a.Var(0, true, 1).Data(), []float32{0.25, 2.25})
+	assertEqualSlices(t, a.Std(0, false, -1).Data(), []float32{0.5, 1.5})
+	assertEqualSlices(t, a.Std(1, false, 0).Data(), []float32{float32(math.Sqrt(2)), float32(math.Sqrt(8))})
+	assert(t, a.Var(4, false).Err != nil)
+
+	// not adjacent axes
+	b := tensor.Range[float64](24).Reshape(2, 3, 4)
+	v := b.Var(0, false, 0, 2)
+	assertEqualSlices(t, v.Shape(), types.Shape{3})
+	// values of every lane are {0..3} + {0, 12} + 4*j
+	assertEqualSlices(t, v.Data(), []float64{37.25, 37.25, 37.25})
+	assertEqualSlices(t, b.Var(0, true, 0, 2).Shape(), types.Shape{1, 3, 1})
+}
+
+func TestVarStable(t *testing.T) {
+	// large mean with a small variance loses everything in the naive E[x^2] - E[x]^2
+	n := 100000
+	data := make([]float32, n)
+	for i := range data {
+		data[i] = 1e4 + float32(i%2)
+	}
+	a := tensor.CreateTensor(data, types.Shape{types.Dim(n)})
+	assertEqualSlices(t, a.Var(0, false).Data(), []float32{0.25})
+	assertEqualSlices(t, a.Mean(false).Data(), []float32{10000.5})
+	m := a.Reshape(10, types.Dim(n/10))
+	assertEqualSlices(t, m.Var(0, false, 1).Data()[:2], []float32{0.25, 0.25})
+	assertEqualSlices(t, m.Var(0, false, 0).Data()[:2], []float32{0, 0})
+}
+
+func TestMeanPrecision(t *testing.T) {
+	// float32 accumulator can't add 1 to 2^24
+	n := 1 << 25
+	a := tensor.Ones[float32](types.Dim(n))
+	assertEqualSlices(t, a.Mean(false).Data(), []float32{1})
+	b := a.Reshape(2, types.Dim(n/2))
+	assertEqualSlices(t, b.Mean(false, 1).Data(), []float32{1, 1})
+	assertEqualSlices(t, b.Mean(false, 0).Data()[:3], []float32{1, 1, 1})
+}
+
+func TestMedianQuantile(t *testing.T) {
+	a := tensor.CreateTensor([]float32{3, 1, 2, 4, 6, 5}, types.Shape{2, 3})
+	assertEqualSlices(t, a.Median(false, 1).Data(), []float32{2, 5})
+	assertEqualSlices(t, a.Median(false).Data(), []float32{3.5})
+	assertEqualSlices(t, a.Median(true, 0).Data(), []float32{3.5, 3.5, 3.5})
+	assertEqualSlices(t, a.Median(true, 0).Shape(), types.Shape{1, 3})
+
+	b := tensor.CreateTensor([]float64{1, 2, 3, 4}, types.Shape{4})
+	cases := []struct {
+		method   tensor.QuantileMethod
+		expected float64
+	}{
+		{tensor.QuantileLinear, 1.75},
+		{tensor.QuantileLower, 1},
+		{tensor.QuantileHigher, 2},
+		{tensor.QuantileNearest, 2},
+		{tensor.QuantileMidpoint, 1.5},
+	}
+	for _, c := range cases {
+		assertEqualSlices(t, b.Quantile(0.25, c.method, false).Data(), []float64{c.expected})
+	}
+	assertEqualSlices(t, b.Quantile(0, tensor.QuantileLinear, false).Data(), []float64{1})
+	assertEqualSlices(t, b.Quantile(1, tensor.QuantileLinear, false).Data(), []float64{4})
+	// nearest rounds to the even position on ties
+	c := tensor.CreateTensor([]float64{10, 20, 30}, types.Shape{3})
+	assertEqualSlices(t, c.Quantile(0.25, tensor.QuantileNearest, false).Data(), []float64{10})
+	assertEqualSlices(t, c.Quantile(0.75, tensor.QuantileNearest, false).Data(), []float64{30})
+
+	nan := tensor.CreateTensor([]float64{1, math.NaN(), 3, 4}, types.Shape{2, 2})
+	med := nan.Median(false, 1).Data()
+	assert(t, math.IsNaN(med[0]) && med[1] == 3.5)
+
+	assert(t, tensor.Range[float32](3).Quantile(1.5, tensor.QuantileLinear, false).Err != nil)
+	assert(t, tensor.Range[float32](3).Quantile(0.5, tensor.QuantileMethod(10), false).Err != nil)
+}
+
+func TestHistogramBincount(t *testing.T) {
+	a := tensor.CreateTensor([]float32{1, 2, 2, 3, 5}, types.Shape{5})
+	counts, edges := a.Histogram(2)
+	assertEqualSlices(t, counts.Data(), []int{3, 2})
+	assertEqualSlices(t, edges.Data(), []float64{1, 3, 5})
+	counts, edges = a.Histogram(2, 0, 4)
+	assertEqualSlices(t, counts.Data(), []int{1, 3})
+	assertEqualSlices(t, edges.Data(), []float64{0, 2, 4})
+	counts, _ = a.Histogram(4, 1, 5)
+	assertEqualSlices(t, counts.Data(), []int{1, 2, 1, 1})
+	// single value range is expanded
+	counts, edges = tensor.Ones[int](3).Histogram(1)
+	assertEqualSlices(t, counts.Data(), []int{3})
+	assertEqualSlices(t, edges.Data(), []float64{0.5, 1.5})
+
+	counts, _ = a.Histogram(0)
+	assert(t, counts.Err != nil)
+	counts, _ = a.Histogram(2, 3, 1)
+	assert(t, counts.Err != nil)
+
+	b := tensor.CreateTensor([]int{0, 1, 1, 3}, types.Shape{4})
+	assertEqualSlices(t, b.Bincount(0).Data(), []int{1, 2, 0, 1})
+	assertEqualSlices(t, b.Bincount(6).Data(), []int{1, 2, 0, 1, 0, 0})
+	assert(t, tensor.CreateTensor([]int{1, -1}, types.Shape{2}).Bincount(0).Err != nil)
+	assert(t, tensor.Range[float32](3).Bincount(0).Err != nil)
+	assert(t, tensor.Range[int](4).Reshape(2, 2).Bincount(0).Err != nil)
+}
+
+func TestCovCorrcoef(t *testing.T) {
+	a := tensor.CreateTensor([]float64{0, 1, 2, 2, 1, 0}, types.Shape{2, 3})
+	assertEqualSlices(t, a.Cov(1).Data(), []float64{1, -1, -1, 1})
+	assertEqualSlices(t, a.Cov(0).Shape(), types.Shape{2, 2})
+	assertAllClose64(t, a.Cov(0), tensor.CreateTensor([]float64{2. / 3, -2. / 3, -2. / 3, 2. / 3}, types.Shape{2, 2}))
+
+	b := tensor.CreateTensor([]float32{1, 2, 3, 4, 2, 4, 6, 8, 4, 3, 2, 1}, types.Shape{3, 4})
+	c := b.Corrcoef()
+	assertEqualSlices(t, c.Data(), []float32{1, 1, -1, 1, 1, -1, -1, -1, 1})
+
+	v := tensor.CreateTensor([]float32{1, 2, 3, 4}, types.Shape{4})
+	assertEqualSlices(t, v.Cov(1).Shape(), types.Shape{1, 1})
+	assertAllClose(t, v.Cov(1).Reshape(1), v.Var(1, false))
+
+	// zero variance gives NaN
+	z := tensor.CreateTensor([]float64{1, 1, 1, 1, 2, 3}, types.Shape{2, 3})
+	zc := z.Corrcoef().Data()
+	assert(t, math.IsNaN(zc[0]) && math.Abs(zc[3]-1) < 1e-12)
+
+	assert(t, tensor.Range[float32](8).Reshape(2, 2, 2).Cov(1).Err != nil)
+	assert(t, v.Cov(4).Err != nil)
+}
@@ -103,3 +103,15 @@ func TestSerializer(t *testing.T) {
 	}
 	assert(t, isequal)
 }
+
+func TestUnrolledLoopsLongInputs(t *testing.T) {
+	// every element of the inputs longer than the unrolled block must be processed
+	ones := tensor.Ones[int](100).Data()
+	assertEqualSlices(t, ones, tensor.Range[int](100).Mul(tensor.Scalar(0)).Add(tensor.Scalar(1)).Data())
+	converted := tensor.AsType[int, float32](tensor.Range[int](100))
+	assertEqualSlices(t, converted.Data(), tensor.Range[float32](100).Data())
+	a := tensor.Range[int](2*20).Reshape(2, 20).T().AsContiguous().Data()
+	for i := 0; i < 20; i++ {
+		assertEqualSlices(t, a[2*i:2*i+2], []int{i, 20 + i})
+	}
+}
@@ -23,6 +23,13 @@ func assertNotEqualSlices[DT types.TensorType](t *testing.T, slice1 []DT, slice2
 	}
 }
 
+func assertAllClose(t *testing.T, a, b *tensor.Tensor[float32]) {
+	is_close, err := a.IsAllClose(b, 0.00001)
+	if err != nil || !is_close {
+		t.Errorf("Tensors must be close. Got %v and %v", a.Data(), b.Data())
+	}
+}
+
 func assert(t *testing.T, stmt bool) {
 	if !stmt {
 		t.Errorf("Statement must be true.")